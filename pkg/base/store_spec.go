@@ -417,6 +417,13 @@ func PreventedStartupFile(dir string) string {
 	return filepath.Join(dir, "_CRITICAL_ALERT.txt")
 }
 
+// EmergencyBallastFile returns the path of the emergency ballast file of the
+// store in the given data directory. The path must be stable across releases,
+// otherwise the ballasts of existing stores would be duplicated.
+func EmergencyBallastFile(dataDir string) string {
+	return filepath.Join(dataDir, AuxiliaryDir, "EMERGENCY_BALLAST")
+}
+
 // PriorCriticalAlertError attempts to read the
 // PreventedStartupFile for each store directory and returns their
 // contents as a structured error.
@@ -212,6 +212,9 @@ var systemTableBackupConfiguration = map[string]systemBackupConfiguration{
 	systemschema.SqllivenessTable.Name: {
 		includeInClusterBackup: optOutOfClusterBackup,
 	},
+	systemschema.NotificationsTable.Name: {
+		includeInClusterBackup: optOutOfClusterBackup,
+	},
 	systemschema.StatementBundleChunksTable.Name: {
 		includeInClusterBackup: optOutOfClusterBackup,
 	},
@@ -74,7 +74,10 @@ const (
 	OptEnvelopeWrapped       EnvelopeType = `wrapped`
 
 	OptFormatJSON FormatType = `json`
-	OptFormatAvro FormatType = `experimental_avro`
+	OptFormatAvro FormatType = `avro`
+	// OptFormatExperimentalAvro is the name under which the avro format was
+	// originally introduced. It is still accepted as an alias for avro.
+	OptFormatExperimentalAvro FormatType = `experimental_avro`
 
 	SinkParamCACert           = `ca_cert`
 	SinkParamClientCert       = `client_cert`
@@ -87,6 +90,7 @@ const (
 	SinkSchemeBuffer          = ``
 	SinkSchemeExperimentalSQL = `experimental-sql`
 	SinkSchemeKafka           = `kafka`
+	SinkSchemeWebhookHTTPS    = `webhook-https`
 	SinkParamSASLEnabled      = `sasl_enabled`
 	SinkParamSASLHandshake    = `sasl_handshake`
 	SinkParamSASLUser         = `sasl_user`
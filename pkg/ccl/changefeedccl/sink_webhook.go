@@ -0,0 +1,196 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/httputil"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	// webhookSinkBatchSize is the number of rows sent in a single request to
+	// the webhook. Rows are buffered until this many have been emitted or the
+	// sink is flushed.
+	webhookSinkBatchSize = 64
+	// webhookSinkTimeout is the timeout of a single request to the webhook.
+	webhookSinkTimeout = 3 * time.Second
+)
+
+// webhookSinkRetryOptions are the options used to retry failed requests to the
+// webhook before the error is returned to the changefeed, which then retries
+// from its last checkpoint.
+var webhookSinkRetryOptions = retry.Options{
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+	MaxRetries:     5,
+	Multiplier:     2,
+}
+
+func isWebhookSink(u *url.URL) bool {
+	return u.Scheme == changefeedbase.SinkSchemeWebhookHTTPS
+}
+
+// webhookSink emits to an HTTPS endpoint. Rows are sent as batches, in the
+// body of POST requests of the form:
+//
+//   {"payload": [<row>, <row>, ...], "length": <number of rows>}
+//
+// where each row is a JSON envelope with its key in the value. Resolved
+// timestamps are sent as their own requests, whose body is the resolved
+// timestamp envelope. It is not concurrency-safe; all calls to Emit and Flush
+// should be from the same goroutine.
+type webhookSink struct {
+	url    string
+	client *httputil.Client
+	// rows are the encoded rows which have been emitted but not sent yet.
+	rows [][]byte
+}
+
+var _ Sink = (*webhookSink)(nil)
+
+type webhookSinkConfig struct {
+	tlsSkipVerify bool
+	caCert        []byte
+}
+
+func makeWebhookSink(u *url.URL, cfg webhookSinkConfig, opts map[string]string) (Sink, error) {
+	switch changefeedbase.FormatType(opts[changefeedbase.OptFormat]) {
+	case changefeedbase.OptFormatJSON:
+	default:
+		return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
+			changefeedbase.OptFormat, opts[changefeedbase.OptFormat])
+	}
+
+	switch changefeedbase.EnvelopeType(opts[changefeedbase.OptEnvelope]) {
+	case changefeedbase.OptEnvelopeWrapped:
+	default:
+		return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
+			changefeedbase.OptEnvelope, opts[changefeedbase.OptEnvelope])
+	}
+
+	if _, ok := opts[changefeedbase.OptKeyInValue]; !ok {
+		return nil, errors.Errorf(`this sink requires the WITH %s option`, changefeedbase.OptKeyInValue)
+	}
+
+	tlsConf := &tls.Config{InsecureSkipVerify: cfg.tlsSkipVerify}
+	if cfg.caCert != nil {
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			return nil, errors.Wrap(err, `could not load system root CA pool`)
+		}
+		if !roots.AppendCertsFromPEM(cfg.caCert) {
+			return nil, errors.Errorf(`failed to parse %s as a PEM certificate`,
+				changefeedbase.SinkParamCACert)
+		}
+		tlsConf.RootCAs = roots
+	}
+
+	sinkURL := *u
+	sinkURL.Scheme = strings.TrimPrefix(u.Scheme, `webhook-`)
+	return &webhookSink{
+		url: sinkURL.String(),
+		client: &httputil.Client{Client: &http.Client{
+			Timeout:   webhookSinkTimeout,
+			Transport: &http.Transport{TLSClientConfig: tlsConf},
+		}},
+	}, nil
+}
+
+// EmitRow implements the Sink interface.
+func (s *webhookSink) EmitRow(
+	ctx context.Context, _ catalog.TableDescriptor, _, value []byte, _ hlc.Timestamp,
+) error {
+	s.rows = append(s.rows, value)
+	if len(s.rows) >= webhookSinkBatchSize {
+		return s.Flush(ctx)
+	}
+	return nil
+}
+
+// EmitResolvedTimestamp implements the Sink interface.
+func (s *webhookSink) EmitResolvedTimestamp(
+	ctx context.Context, encoder Encoder, resolved hlc.Timestamp,
+) error {
+	// The resolved timestamp must not overtake the rows which precede it.
+	if err := s.Flush(ctx); err != nil {
+		return err
+	}
+	payload, err := encoder.EncodeResolvedTimestamp(ctx, `` /* topic */, resolved)
+	if err != nil {
+		return err
+	}
+	return s.send(ctx, payload)
+}
+
+// Flush implements the Sink interface.
+func (s *webhookSink) Flush(ctx context.Context) error {
+	if len(s.rows) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	buf.WriteString(`{"payload":[`)
+	for i, row := range s.rows {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(row)
+	}
+	fmt.Fprintf(&buf, `],"length":%d}`, len(s.rows))
+	if err := s.send(ctx, buf.Bytes()); err != nil {
+		return err
+	}
+	s.rows = s.rows[:0]
+	return nil
+}
+
+// send posts the given body to the webhook, retrying on failures.
+func (s *webhookSink) send(ctx context.Context, body []byte) error {
+	var err error
+	for r := retry.StartWithCtx(ctx, webhookSinkRetryOptions); r.Next(); {
+		if err = s.post(ctx, body); err == nil {
+			return nil
+		}
+	}
+	return errors.Wrap(err, `sending to webhook sink`)
+}
+
+func (s *webhookSink) post(ctx context.Context, body []byte) error {
+	resp, err := s.client.Post(ctx, s.url, `application/json`, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Errorf(`%s: %s`, resp.Status, msg)
+	}
+	return nil
+}
+
+// Close implements the Sink interface.
+func (s *webhookSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
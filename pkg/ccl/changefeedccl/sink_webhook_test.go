@@ -0,0 +1,117 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	var mu struct {
+		syncutil.Mutex
+		bodies []string
+		// failures is the number of requests to fail before succeeding.
+		failures int
+	}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, `application/json`, r.Header.Get(`Content-Type`))
+		mu.Lock()
+		defer mu.Unlock()
+		if mu.failures > 0 {
+			mu.failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		mu.bodies = append(mu.bodies, string(body))
+	}))
+	defer srv.Close()
+	popBodies := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		bodies := mu.bodies
+		mu.bodies = nil
+		return bodies
+	}
+
+	opts := map[string]string{
+		changefeedbase.OptFormat:     string(changefeedbase.OptFormatJSON),
+		changefeedbase.OptEnvelope:   string(changefeedbase.OptEnvelopeWrapped),
+		changefeedbase.OptKeyInValue: ``,
+	}
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	u.Scheme = changefeedbase.SinkSchemeWebhookHTTPS
+
+	// The test server uses a self-signed certificate.
+	s, err := makeWebhookSink(u, webhookSinkConfig{tlsSkipVerify: true}, opts)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, s.Close()) }()
+
+	// Empty.
+	require.NoError(t, s.Flush(ctx))
+	require.Empty(t, popBodies())
+
+	// Rows are only sent when the sink is flushed.
+	require.NoError(t, s.EmitRow(ctx, nil, nil, []byte(`{"after":1}`), zeroTS))
+	require.NoError(t, s.EmitRow(ctx, nil, nil, []byte(`{"after":2}`), zeroTS))
+	require.Empty(t, popBodies())
+	require.NoError(t, s.Flush(ctx))
+	require.Equal(t, []string{`{"payload":[{"after":1},{"after":2}],"length":2}`}, popBodies())
+
+	// Full batches are sent without flushing.
+	for i := 0; i < webhookSinkBatchSize; i++ {
+		require.NoError(t, s.EmitRow(ctx, nil, nil, []byte(`{}`), zeroTS))
+	}
+	require.Len(t, popBodies(), 1)
+
+	// Failed requests are retried.
+	mu.Lock()
+	mu.failures = 2
+	mu.Unlock()
+	require.NoError(t, s.EmitRow(ctx, nil, nil, []byte(`{"after":3}`), zeroTS))
+	require.NoError(t, s.Flush(ctx))
+	require.Equal(t, []string{`{"payload":[{"after":3}],"length":1}`}, popBodies())
+
+	// Resolved timestamps are sent after the rows emitted before them.
+	enc, err := makeJSONEncoder(opts)
+	require.NoError(t, err)
+	require.NoError(t, s.EmitRow(ctx, nil, nil, []byte(`{"after":4}`), zeroTS))
+	require.NoError(t, s.EmitResolvedTimestamp(ctx, enc, hlc.Timestamp{WallTime: 1}))
+	bodies := popBodies()
+	require.Len(t, bodies, 2)
+	require.Equal(t, `{"payload":[{"after":4}],"length":1}`, bodies[0])
+	require.True(t, strings.Contains(bodies[1], `"resolved"`), bodies[1])
+
+	// Other formats and envelopes are rejected.
+	_, err = makeWebhookSink(u, webhookSinkConfig{}, map[string]string{
+		changefeedbase.OptFormat:     string(changefeedbase.OptFormatAvro),
+		changefeedbase.OptEnvelope:   string(changefeedbase.OptEnvelopeWrapped),
+		changefeedbase.OptKeyInValue: ``,
+	})
+	require.EqualError(t, err, `this sink is incompatible with format=avro`)
+}
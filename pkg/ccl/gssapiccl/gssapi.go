@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/hba"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/identmap"
 	"github.com/cockroachdb/errors"
 )
 
@@ -49,6 +50,7 @@ func authGSS(
 	_ pgwire.PasswordValidUntilFn,
 	execCfg *sql.ExecutorConfig,
 	entry *hba.Entry,
+	_ *identmap.Conf,
 ) (security.UserAuthHook, error) {
 	return func(requestedUser security.SQLUsername, clientConnection bool) (func(), error) {
 		var (
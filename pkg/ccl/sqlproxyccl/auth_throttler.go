@@ -0,0 +1,98 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package sqlproxyccl
+
+import (
+	"net"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/cache"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// authThrottlerCacheSize is the maximum number of client addresses tracked by
+// an authThrottler. The least recently used addresses are forgotten first.
+const authThrottlerCacheSize = 1 << 16
+
+// authThrottler slows down brute-force attacks on passwords. After a client
+// fails to authenticate, the connections from its IP address are refused
+// until a delay has elapsed. The delay starts at baseDelay and doubles with
+// every consecutive failure, up to maxDelay. A successful authentication
+// resets it.
+type authThrottler struct {
+	baseDelay time.Duration
+	maxDelay  time.Duration
+
+	mu struct {
+		syncutil.Mutex
+		// clients maps the IP addresses of the clients whose last
+		// authentication failed to their *authThrottleState.
+		clients *cache.UnorderedCache
+	}
+}
+
+type authThrottleState struct {
+	// delay is the delay imposed by the last failure.
+	delay time.Duration
+	// nextAttempt is the time before which connections are refused.
+	nextAttempt time.Time
+}
+
+func newAuthThrottler(baseDelay, maxDelay time.Duration) *authThrottler {
+	t := &authThrottler{baseDelay: baseDelay, maxDelay: maxDelay}
+	t.mu.clients = cache.NewUnorderedCache(cache.Config{
+		Policy: cache.CacheLRU,
+		ShouldEvict: func(size int, _, _ interface{}) bool {
+			return size > authThrottlerCacheSize
+		},
+	})
+	return t
+}
+
+// throttleKey returns the key under which the attempts of the client at the
+// given address are tracked.
+func throttleKey(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+// allowed returns true if the client at the given address may attempt to
+// authenticate at the given time.
+func (t *authThrottler) allowed(addr net.Addr, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	v, ok := t.mu.clients.Get(throttleKey(addr))
+	if !ok {
+		return true
+	}
+	return !now.Before(v.(*authThrottleState).nextAttempt)
+}
+
+// reportAttempt records the outcome of an authentication attempt by the client
+// at the given address.
+func (t *authThrottler) reportAttempt(addr net.Addr, success bool, now time.Time) {
+	key := throttleKey(addr)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if success {
+		t.mu.clients.Del(key)
+		return
+	}
+	s := &authThrottleState{delay: t.baseDelay}
+	if v, ok := t.mu.clients.Get(key); ok {
+		if s.delay = 2 * v.(*authThrottleState).delay; s.delay > t.maxDelay {
+			s.delay = t.maxDelay
+		}
+	}
+	s.nextAttempt = now.Add(s.delay)
+	t.mu.clients.Add(key, s)
+}
@@ -0,0 +1,55 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package sqlproxyccl
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthThrottler(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	th := newAuthThrottler(time.Second, 3*time.Second)
+	now := time.Unix(0, 0)
+	client := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234}
+	// The same client, connecting from another port.
+	sameClient := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5678}
+	otherClient := &net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 1234}
+
+	require.True(t, th.allowed(client, now))
+
+	// The first failure delays the next attempt by the base delay.
+	th.reportAttempt(client, false /* success */, now)
+	require.False(t, th.allowed(client, now))
+	require.False(t, th.allowed(sameClient, now.Add(999*time.Millisecond)))
+	require.True(t, th.allowed(otherClient, now))
+	now = now.Add(time.Second)
+	require.True(t, th.allowed(client, now))
+
+	// The delay doubles with every consecutive failure, up to the maximum.
+	th.reportAttempt(client, false /* success */, now)
+	require.False(t, th.allowed(client, now.Add(1999*time.Millisecond)))
+	now = now.Add(2 * time.Second)
+	require.True(t, th.allowed(client, now))
+	th.reportAttempt(client, false /* success */, now)
+	require.False(t, th.allowed(client, now.Add(2999*time.Millisecond)))
+	now = now.Add(3 * time.Second)
+	require.True(t, th.allowed(client, now))
+
+	// A success resets the delay.
+	th.reportAttempt(client, true /* success */, now)
+	require.True(t, th.allowed(client, now))
+	th.reportAttempt(client, false /* success */, now)
+	require.True(t, th.allowed(client, now.Add(time.Second)))
+}
@@ -14,7 +14,9 @@ import (
 	"io"
 	"net"
 	"os"
+	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
 	"github.com/jackc/pgproto3/v2"
 )
@@ -82,6 +84,13 @@ type Options struct {
 	// The argument is the startup message received from the frontend. It
 	// contains the protocol version and params sent by the client.
 	BackendDialer func(msg *pgproto3.StartupMessage) (net.Conn, error)
+
+	// If set, the clients which fail to authenticate are throttled: the
+	// connections from their IP address are refused for AuthThrottleBaseDelay
+	// after the failure. The delay doubles with every consecutive failure, up to
+	// AuthThrottleMaxDelay.
+	AuthThrottleBaseDelay time.Duration
+	AuthThrottleMaxDelay  time.Duration
 }
 
 // Proxy takes an incoming client connection and relays it to a backend SQL
@@ -135,6 +144,16 @@ func (s *Server) Proxy(proxyConn *Conn) error {
 	}
 	defer func() { _ = conn.Close() }()
 
+	if s.authThrottler != nil && !s.authThrottler.allowed(proxyConn.RemoteAddr(), timeutil.Now()) {
+		s.metrics.RefusedConnCount.Inc(1)
+		codeErr := &CodeError{
+			code: CodeProxyRefusedConnection,
+			err:  errors.New("too many failed authentication attempts"),
+		}
+		sendErrToClient(conn, codeErr.code, codeErr.Error())
+		return codeErr
+	}
+
 	backendDialer := s.opts.BackendDialer
 	var backendConfig *BackendConfig
 	if s.opts.BackendConfigFromParams != nil {
@@ -194,12 +213,18 @@ func (s *Server) Proxy(proxyConn *Conn) error {
 	if err := authenticate(conn, crdbConn); err != nil {
 		s.metrics.AuthFailedCount.Inc(1)
 		if codeErr := (*CodeError)(nil); errors.As(err, &codeErr) {
+			if codeErr.code == CodeAuthFailed && s.authThrottler != nil {
+				s.authThrottler.reportAttempt(proxyConn.RemoteAddr(), false /* success */, timeutil.Now())
+			}
 			sendErrToClient(conn, codeErr.code, codeErr.Error())
 			return err
 		}
 		return errors.AssertionFailedf("unrecognized auth failure")
 	}
 
+	if s.authThrottler != nil {
+		s.authThrottler.reportAttempt(proxyConn.RemoteAddr(), true /* success */, timeutil.Now())
+	}
 	s.metrics.SuccessfulConnCount.Inc(1)
 
 	// These channels are buffered because we'll only consume one of them.
@@ -31,6 +31,8 @@ type Server struct {
 	mux             *http.ServeMux
 	metrics         *Metrics
 	metricsRegistry *metric.Registry
+	// authThrottler is set if the authentication attempts are throttled.
+	authThrottler *authThrottler
 
 	promMu             syncutil.Mutex
 	prometheusExporter metric.PrometheusExporter
@@ -55,6 +57,14 @@ func NewServer(opts Options) *Server {
 		prometheusExporter: metric.MakePrometheusExporter(),
 	}
 
+	if opts.AuthThrottleBaseDelay > 0 {
+		maxDelay := opts.AuthThrottleMaxDelay
+		if maxDelay < opts.AuthThrottleBaseDelay {
+			maxDelay = opts.AuthThrottleBaseDelay
+		}
+		s.authThrottler = newAuthThrottler(opts.AuthThrottleBaseDelay, maxDelay)
+	}
+
 	// /_status/{healthz,vars} matches CRDB's healthcheck and metrics
 	// endpoints.
 	mux.HandleFunc("/_status/vars/", s.handleVars)
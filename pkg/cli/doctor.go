@@ -231,7 +231,7 @@ FROM system.namespace`
 		if err := protoutil.Unmarshal(progressBytes, md.Progress); err != nil {
 			return err
 		}
-		if md.Status == jobs.StatusRunning {
+		if !md.Status.Terminal() {
 			jobsTable = append(jobsTable, md)
 		}
 		return nil
@@ -321,7 +321,7 @@ func runZipDirDoctor(cmd *cobra.Command, args []string) (retErr error) {
 		fields := strings.Fields(row)
 		md := jobs.JobMetadata{}
 		md.Status = jobs.Status(fields[1])
-		if md.Status != jobs.StatusRunning {
+		if md.Status.Terminal() {
 			return nil
 		}
 
@@ -242,6 +242,40 @@ func initTempStorageConfig(
 	return tempStorageConfig, nil
 }
 
+// checkDiskFullAndEstablishBallasts returns an error if the disk of one of the
+// on-disk stores is full. Otherwise, it creates the emergency ballasts of the
+// stores which do not have one yet.
+func checkDiskFullAndEstablishBallasts(ctx context.Context, specs []base.StoreSpec) error {
+	for _, spec := range specs {
+		if spec.InMemory {
+			continue
+		}
+		ballastPath := base.EmergencyBallastFile(spec.Path)
+		full, err := storage.IsDiskFull(spec)
+		if err != nil {
+			// Not being able to check the disk usage (e.g. on platforms where
+			// this is not supported) should not prevent the node from starting.
+			log.Ops.Warningf(ctx, "unable to check the disk usage of store %s: %v", spec.Path, err)
+			continue
+		}
+		if full {
+			return &cliError{
+				exitCode: exit.DiskFull(),
+				cause: errors.WithHintf(
+					errors.Newf("store %s: out of disk space", spec.Path),
+					"Free up disk space, for example by removing the emergency ballast file %s, "+
+						"and restart the node.", ballastPath),
+			}
+		}
+		if created, err := storage.MaybeEstablishBallast(spec.Path); err != nil {
+			log.Ops.Warningf(ctx, "unable to create the emergency ballast of store %s: %v", spec.Path, err)
+		} else if created {
+			log.Ops.Infof(ctx, "created the emergency ballast %s", ballastPath)
+		}
+	}
+	return nil
+}
+
 var errCannotUseJoin = errors.New("cannot use --join with 'cockroach start-single-node' -- use 'cockroach start' instead")
 
 func runStartSingleNode(cmd *cobra.Command, args []string) error {
@@ -376,6 +410,13 @@ func runStart(cmd *cobra.Command, args []string, startSingleNode bool) (returnEr
 	// Now perform additional configuration tweaks specific to the start
 	// command.
 
+	// Refuse to start if the disk of a store is full, since the node would not
+	// be able to make progress. Otherwise, reserve the emergency ballast that
+	// the operator can delete to recover if the disk fills up later on.
+	if err := checkDiskFullAndEstablishBallasts(ctx, serverCfg.Stores.Specs); err != nil {
+		return err
+	}
+
 	// Derive temporary/auxiliary directory specifications.
 	if serverCfg.Settings.ExternalIODir, err = initExternalIODir(ctx, serverCfg.Stores.Specs[0]); err != nil {
 		return err
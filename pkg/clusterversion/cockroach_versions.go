@@ -222,6 +222,12 @@ const (
 	// using the replicated legacy TruncatedState. It's also used in asserting
 	// that no replicated truncated state representation is found.
 	PostTruncatedAndRangeAppliedStateMigration
+	// SCRAMAuthentication is the version from which passwords may be stored as
+	// SCRAM-SHA-256 verifiers, which older nodes cannot authenticate.
+	SCRAMAuthentication
+	// NotificationsTable adds the system.notifications table, through which
+	// the notifications generated by NOTIFY are delivered to all the nodes.
+	NotificationsTable
 
 	// Step (1): Add new versions here.
 )
@@ -368,6 +374,14 @@ var versionsSingleton = keyedVersions([]keyedVersion{
 		Key:     PostTruncatedAndRangeAppliedStateMigration,
 		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 16},
 	},
+	{
+		Key:     SCRAMAuthentication,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 18},
+	},
+	{
+		Key:     NotificationsTable,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 20},
+	},
 
 	// Step (2): Add new versions here.
 })
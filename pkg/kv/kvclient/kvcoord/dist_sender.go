@@ -1783,7 +1783,14 @@ func (ds *DistSender) sendToReplicas(
 	desc := routing.Desc()
 	ba.RangeID = desc.RangeID
 	leaseholder := routing.Leaseholder()
-	replicas, err := NewReplicaSlice(ctx, ds.nodeDescs, desc, leaseholder)
+	canFollowerRead := (ds.clusterID != nil) && CanSendToFollower(ds.clusterID.Get(), ds.st, ba)
+	// Non-voting replicas can only serve follower reads, so they are only
+	// candidates for the requests which can be sent to followers.
+	filter := OnlyPotentialLeaseholders
+	if canFollowerRead {
+		filter = AllExtantReplicas
+	}
+	replicas, err := NewReplicaSlice(ctx, ds.nodeDescs, desc, leaseholder, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -1796,7 +1803,6 @@ func (ds *DistSender) sendToReplicas(
 
 	// Try the leaseholder first, if the request wants it.
 	{
-		canFollowerRead := (ds.clusterID != nil) && CanSendToFollower(ds.clusterID.Get(), ds.st, ba)
 		sendToLeaseholder := (leaseholder != nil) && !canFollowerRead && ba.RequiresLeaseHolder()
 		if sendToLeaseholder {
 			idx := replicas.Find(leaseholder.ReplicaID)
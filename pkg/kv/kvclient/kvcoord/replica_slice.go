@@ -39,11 +39,27 @@ func (i ReplicaInfo) addr() string {
 // A ReplicaSlice is a slice of ReplicaInfo.
 type ReplicaSlice []ReplicaInfo
 
+// ReplicaSliceFilter controls which kinds of replicas are to be included in
+// the slice for routing BatchRequests to.
+type ReplicaSliceFilter int
+
+const (
+	// OnlyPotentialLeaseholders prescribes that the ReplicaSlice should include
+	// only replicas that are allowed to be leaseholders (i.e. replicas of type
+	// VOTER_FULL).
+	OnlyPotentialLeaseholders ReplicaSliceFilter = iota
+	// AllExtantReplicas prescribes that the ReplicaSlice should also include the
+	// non-voting replicas, which can serve follower reads. Replicas of type
+	// LEARNER, VOTER_OUTGOING and VOTER_DEMOTING are still excluded.
+	AllExtantReplicas
+)
+
 // NewReplicaSlice creates a ReplicaSlice from the replicas listed in the range
 // descriptor and using gossip to lookup node descriptors. Replicas on nodes
 // that are not gossiped are omitted from the result.
 //
-// Generally, only voting replicas are returned. However, if a non-nil
+// Generally, only voting replicas are returned, or voting and non-voting
+// replicas if filter is AllExtantReplicas. However, if a non-nil
 // leaseholder is passed in, it will be included in the result even if the
 // descriptor has it as a learner (we assert that the leaseholder is part of the
 // descriptor). The idea is that the descriptor might be stale and list the
@@ -59,6 +75,7 @@ func NewReplicaSlice(
 	nodeDescs NodeDescStore,
 	desc *roachpb.RangeDescriptor,
 	leaseholder *roachpb.ReplicaDescriptor,
+	filter ReplicaSliceFilter,
 ) (ReplicaSlice, error) {
 	if leaseholder != nil {
 		if _, ok := desc.GetReplicaDescriptorByID(leaseholder.ReplicaID); !ok {
@@ -67,9 +84,24 @@ func NewReplicaSlice(
 	}
 
 	// Learner replicas won't serve reads/writes, so we'll send only to the
-	// `VoterDescriptors` replicas. This is just an optimization to save a network hop,
-	// everything would still work if we had `All` here.
-	voters := desc.Replicas().VoterDescriptors()
+	// `VoterDescriptors` replicas (and the non-voters, if follower reads are
+	// allowed). This is just an optimization to save a network hop, everything
+	// would still work if we had `All` here.
+	var voters []roachpb.ReplicaDescriptor
+	switch filter {
+	case OnlyPotentialLeaseholders:
+		voters = desc.Replicas().VoterDescriptors()
+	case AllExtantReplicas:
+		voters = desc.Replicas().FilterToDescriptors(func(rDesc roachpb.ReplicaDescriptor) bool {
+			switch rDesc.GetType() {
+			case roachpb.VOTER_FULL, roachpb.VOTER_INCOMING, roachpb.NON_VOTER:
+				return true
+			}
+			return false
+		})
+	default:
+		log.Fatalf(ctx, "unknown ReplicaSliceFilter %v", filter)
+	}
 	// If we know a leaseholder, though, let's make sure we include it.
 	if leaseholder != nil && len(voters) < len(desc.Replicas().Descriptors()) {
 		found := false
@@ -68,23 +68,40 @@ func TestNewReplicaSlice(t *testing.T) {
 			},
 		},
 	}
-	rs, err := NewReplicaSlice(ctx, ns, rd, nil /* leaseholder */)
+	rs, err := NewReplicaSlice(ctx, ns, rd, nil /* leaseholder */, OnlyPotentialLeaseholders)
 	require.NoError(t, err)
 	require.Equal(t, 3, rs.Len())
 
 	// Check that learners are not included.
 	typLearner := roachpb.LEARNER
 	rd.InternalReplicas[2].Type = &typLearner
-	rs, err = NewReplicaSlice(ctx, ns, rd, nil /* leaseholder */)
+	rs, err = NewReplicaSlice(ctx, ns, rd, nil /* leaseholder */, OnlyPotentialLeaseholders)
 	require.NoError(t, err)
 	require.Equal(t, 2, rs.Len())
 
 	// Check that, if the leasehoder points to a learner, that learner is
 	// included.
 	leaseholder := &roachpb.ReplicaDescriptor{NodeID: 3, StoreID: 3}
-	rs, err = NewReplicaSlice(ctx, ns, rd, leaseholder)
+	rs, err = NewReplicaSlice(ctx, ns, rd, leaseholder, OnlyPotentialLeaseholders)
 	require.NoError(t, err)
 	require.Equal(t, 3, rs.Len())
+
+	// Check that non-voters are only included when all the extant replicas are
+	// requested.
+	typNonVoter := roachpb.NON_VOTER
+	rd.InternalReplicas[2].Type = &typNonVoter
+	rs, err = NewReplicaSlice(ctx, ns, rd, nil /* leaseholder */, OnlyPotentialLeaseholders)
+	require.NoError(t, err)
+	require.Equal(t, 2, rs.Len())
+	rs, err = NewReplicaSlice(ctx, ns, rd, nil /* leaseholder */, AllExtantReplicas)
+	require.NoError(t, err)
+	require.Equal(t, 3, rs.Len())
+
+	// Learners are never included.
+	rd.InternalReplicas[1].Type = &typLearner
+	rs, err = NewReplicaSlice(ctx, ns, rd, nil /* leaseholder */, AllExtantReplicas)
+	require.NoError(t, err)
+	require.Equal(t, 2, rs.Len())
 }
 
 func getStores(rs ReplicaSlice) (r []roachpb.StoreID) {
@@ -19,6 +19,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
@@ -282,8 +283,9 @@ func (tc *TxnCoordSender) initCommonInterceptors(
 		riGen.ds = ds
 	}
 	tc.interceptorAlloc.txnPipeliner = txnPipeliner{
-		st:    tcf.st,
-		riGen: riGen,
+		st:              tcf.st,
+		riGen:           riGen,
+		pipelinedWrites: tc.metrics.PipelinedWrites,
 	}
 	tc.interceptorAlloc.txnSpanRefresher = txnSpanRefresher{
 		st:    tcf.st,
@@ -781,8 +783,14 @@ func (tc *TxnCoordSender) updateStateLocked(
 	// Most errors cause the transaction to not accept further requests (except a
 	// rollback), but some errors are safe to allow continuing (in particular
 	// ConditionFailedError). In particular, SQL can recover by rolling back to a
-	// savepoint.
-	if roachpb.ErrPriority(pErr.GoError()) != roachpb.ErrorScoreUnambiguousError {
+	// savepoint. A WriteIntentError returned to a read-only batch with the
+	// Error wait policy is also safe, and SQL retries around the locked rows
+	// when scanning with SKIP LOCKED. Such a batch may have been partially
+	// evaluated when it spans multiple ranges, but it performed no writes, and
+	// the txnPipeliner tracks all the lock spans of failed batches so that the
+	// unreplicated locks acquired by its evaluated parts are released.
+	if roachpb.ErrPriority(pErr.GoError()) != roachpb.ErrorScoreUnambiguousError &&
+		!isWaitPolicyLockConflict(ba, pErr) {
 		tc.mu.txnState = txnError
 		tc.mu.storedErr = roachpb.NewError(&roachpb.TxnAlreadyEncounteredErrorError{
 			PrevError: pErr.String(),
@@ -846,6 +854,16 @@ func (tc *TxnCoordSender) AnchorOnSystemConfigRange() error {
 	return tc.setTxnAnchorKeyLocked(keys.SystemConfigSpan.Key)
 }
 
+// isWaitPolicyLockConflict returns true if the error is a WriteIntentError
+// returned to a read-only batch with the Error wait policy.
+func isWaitPolicyLockConflict(ba roachpb.BatchRequest, pErr *roachpb.Error) bool {
+	if ba.WaitPolicy != lock.WaitPolicy_Error || !ba.IsReadOnly() {
+		return false
+	}
+	_, ok := pErr.GetDetail().(*roachpb.WriteIntentError)
+	return ok
+}
+
 // TxnStatus is part of the client.TxnSender interface.
 func (tc *TxnCoordSender) TxnStatus() roachpb.TransactionStatus {
 	tc.mu.Lock()
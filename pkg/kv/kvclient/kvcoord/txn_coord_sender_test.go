@@ -23,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage"
@@ -998,6 +999,68 @@ func TestTxnCoordSenderNoDuplicateLockSpans(t *testing.T) {
 	}
 }
 
+// TestTxnCoordSenderWaitPolicyLockConflict verifies that a WriteIntentError
+// returned to a read-only batch with the Error wait policy does not prevent the
+// transaction from issuing further requests, while the same error returned to a
+// batch with writes does.
+func TestTxnCoordSenderWaitPolicyLockConflict(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	manual := hlc.NewManualClock(123)
+	clock := hlc.NewClock(manual.UnixNano, time.Nanosecond)
+
+	var senderFn kv.SenderFunc = func(_ context.Context, ba roachpb.BatchRequest) (
+		*roachpb.BatchResponse, *roachpb.Error) {
+		if ba.WaitPolicy == lock.WaitPolicy_Error {
+			return nil, roachpb.NewErrorWithTxn(&roachpb.WriteIntentError{
+				Intents: []roachpb.Intent{roachpb.MakeIntent(&enginepb.TxnMeta{}, roachpb.Key("b"))},
+			}, ba.Txn)
+		}
+		br := ba.CreateReply()
+		br.Txn = ba.Txn.Clone()
+		return br, nil
+	}
+	ambient := log.AmbientContext{Tracer: tracing.NewTracer()}
+	factory := NewTxnCoordSenderFactory(
+		TxnCoordSenderFactoryConfig{
+			AmbientCtx: ambient,
+			Clock:      clock,
+			Stopper:    stopper,
+			Settings:   cluster.MakeTestingClusterSettings(),
+		},
+		senderFn,
+	)
+	db := kv.NewDB(ambient, factory, clock, stopper)
+
+	for _, withWrite := range []bool{false, true} {
+		t.Run(fmt.Sprintf("write=%t", withWrite), func(t *testing.T) {
+			txn := kv.NewTxn(ctx, db, 0 /* gatewayNodeID */)
+			var ba roachpb.BatchRequest
+			ba.WaitPolicy = lock.WaitPolicy_Error
+			ba.Add(&roachpb.ScanRequest{
+				RequestHeader: roachpb.RequestHeader{Key: roachpb.Key("a"), EndKey: roachpb.Key("c")},
+				KeyLocking:    lock.Exclusive,
+			})
+			if withWrite {
+				ba.Add(roachpb.NewPut(roachpb.Key("d"), roachpb.MakeValueFromString("value")))
+			}
+			_, pErr := txn.Send(ctx, ba)
+			require.IsType(t, &roachpb.WriteIntentError{}, pErr.GetDetail())
+
+			_, err := txn.Get(ctx, roachpb.Key("a"))
+			if withWrite {
+				require.True(t, errors.HasType(err, (*roachpb.TxnAlreadyEncounteredErrorError)(nil)), "%v", err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.NoError(t, txn.Rollback(ctx))
+		})
+	}
+}
+
 // checkTxnMetrics verifies that the provided Sender's transaction metrics match the expected
 // values. This is done through a series of retries with increasing backoffs, to work around
 // the TxnCoordSender's asynchronous updating of metrics after a transaction ends.
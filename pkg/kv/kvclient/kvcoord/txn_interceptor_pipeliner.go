@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
 	"github.com/google/btree"
 )
 
@@ -182,6 +183,9 @@ type txnPipeliner struct {
 	wrapped  lockedSender
 	disabled bool
 
+	// pipelinedWrites counts the writes performed with async consensus.
+	pipelinedWrites *metric.Counter
+
 	// In-flight writes are intent point writes that have not yet been proved
 	// to have succeeded. They will need to be proven before the transaction
 	// can commit.
@@ -495,7 +499,11 @@ func (tp *txnPipeliner) updateLockTracking(
 	// concurrent requests for extended periods of time. See #3346.
 	if br == nil {
 		// The transaction cannot continue in this epoch whether this is
-		// a retryable error or not.
+		// a retryable error or not, unless the batch was a read-only batch
+		// with the Error wait policy which ran into a conflicting lock (see
+		// TxnCoordSender.updateStateLocked). Such a batch may have been
+		// partially evaluated across ranges, so its locking reads may have
+		// acquired some of their locks and need to be tracked all the same.
 		ba.LockSpanIterate(nil, tp.trackLocks)
 		return
 	}
@@ -546,6 +554,9 @@ func (tp *txnPipeliner) updateLockTracking(
 				// need to prove that these succeeded sometime before we commit.
 				header := req.Header()
 				tp.ifWrites.insert(header.Key, header.Sequence)
+				if tp.pipelinedWrites != nil {
+					tp.pipelinedWrites.Inc(1)
+				}
 			} else {
 				// If the lock acquisitions weren't performed asynchronously
 				// then add them directly to our lock footprint. Locking read
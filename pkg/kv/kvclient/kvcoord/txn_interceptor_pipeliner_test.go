@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
 	"github.com/stretchr/testify/require"
 )
 
@@ -79,8 +80,9 @@ func (m *mockLockedSender) ChainMockSend(
 func makeMockTxnPipeliner() (txnPipeliner, *mockLockedSender) {
 	mockSender := &mockLockedSender{}
 	return txnPipeliner{
-		st:      cluster.MakeTestingClusterSettings(),
-		wrapped: mockSender,
+		st:              cluster.MakeTestingClusterSettings(),
+		wrapped:         mockSender,
+		pipelinedWrites: metric.NewCounter(metric.Metadata{}),
 	}, mockSender
 }
 
@@ -184,6 +186,7 @@ func TestTxnPipelinerTrackInFlightWrites(t *testing.T) {
 	require.Nil(t, pErr)
 	require.NotNil(t, br)
 	require.Equal(t, 1, tp.ifWrites.len())
+	require.Equal(t, int64(1), tp.pipelinedWrites.Count())
 
 	w := tp.ifWrites.t.Min().(*inFlightWrite)
 	require.Equal(t, putArgs.Key, w.Key)
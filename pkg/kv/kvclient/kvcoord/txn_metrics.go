@@ -23,6 +23,7 @@ type TxnMetrics struct {
 	Commits         *metric.Counter
 	Commits1PC      *metric.Counter // Commits which finished in a single phase
 	ParallelCommits *metric.Counter // Commits which entered the STAGING state
+	PipelinedWrites *metric.Counter // Writes performed with async consensus
 
 	RefreshSuccess                *metric.Counter
 	RefreshFail                   *metric.Counter
@@ -71,6 +72,12 @@ var (
 		Measurement: "KV Transactions",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaPipelinedWritesRates = metric.Metadata{
+		Name:        "txn.pipelined_writes",
+		Help:        "Number of writes performed asynchronously through transactional write pipelining",
+		Measurement: "KV Writes",
+		Unit:        metric.Unit_COUNT,
+	}
 	metaRefreshSuccess = metric.Metadata{
 		Name: "txn.refresh.success",
 		Help: "Number of successful transaction refreshes. A refresh may be " +
@@ -207,6 +214,7 @@ func MakeTxnMetrics(histogramWindow time.Duration) TxnMetrics {
 		Commits:                       metric.NewCounter(metaCommitsRates),
 		Commits1PC:                    metric.NewCounter(metaCommits1PCRates),
 		ParallelCommits:               metric.NewCounter(metaParallelCommitsRates),
+		PipelinedWrites:               metric.NewCounter(metaPipelinedWritesRates),
 		RefreshSuccess:                metric.NewCounter(metaRefreshSuccess),
 		RefreshFail:                   metric.NewCounter(metaRefreshFail),
 		RefreshFailWithCondensedSpans: metric.NewCounter(metaRefreshFailWithCondensedSpans),
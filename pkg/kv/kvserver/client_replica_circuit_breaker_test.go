@@ -0,0 +1,88 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReplicaCircuitBreaker verifies that the writes to a range which is unable
+// to replicate commands fail fast once the circuit breaker of the leaseholder
+// is tripped, and that the breaker is reset once the range makes progress.
+func TestReplicaCircuitBreaker(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	tc := testcluster.StartTestCluster(t, 3, base.TestClusterArgs{
+		ReplicationMode: base.ReplicationManual,
+	})
+	defer tc.Stopper().Stop(ctx)
+
+	key := tc.ScratchRange(t)
+	desc := tc.AddVotersOrFatal(t, key, tc.Target(1), tc.Target(2))
+	require.NoError(t, tc.WaitForVoters(key, tc.Target(1), tc.Target(2)))
+	store, repl := getFirstStoreReplica(t, tc.Server(0), key)
+	kvserver.SetReplicaCircuitBreakerSlowReplicationThreshold(
+		&tc.Server(0).ClusterSettings().SV, time.Second)
+
+	// Drop all the messages of the range addressed to the leaseholder, so that
+	// it does not hear back from the followers and cannot commit commands.
+	var partitioned int32 = 1
+	drop := func() bool { return atomic.LoadInt32(&partitioned) == 1 }
+	tc.Servers[0].RaftTransport().Listen(store.StoreID(), &unreliableRaftHandler{
+		rangeID:            desc.RangeID,
+		RaftMessageHandler: store,
+		unreliableRaftHandlerFuncs: unreliableRaftHandlerFuncs{
+			dropReq:  func(*kvserver.RaftMessageRequest) bool { return drop() },
+			dropHB:   func(*kvserver.RaftHeartbeat) bool { return drop() },
+			dropResp: func(*kvserver.RaftMessageResponse) bool { return drop() },
+		},
+	})
+
+	// The first write trips the breaker once it has not been applied after
+	// the slow replication threshold. It may still be applied later.
+	err := store.DB().Put(ctx, key, "a")
+	require.True(t, errors.HasType(err, (*roachpb.AmbiguousResultError)(nil)), "%+v", err)
+	require.True(t, repl.CircuitBreakerTripped())
+
+	// Subsequent writes fail fast.
+	err = store.DB().Put(ctx, key, "b")
+	require.True(t, kvserver.IsReplicaUnavailableError(err), "%+v", err)
+
+	// Reads which do not conflict with the pending write are still served.
+	_, err = store.DB().Get(ctx, key.Next())
+	require.NoError(t, err)
+
+	// Once the partition heals, the pending write is applied, which resets the
+	// breaker.
+	atomic.StoreInt32(&partitioned, 0)
+	testutils.SucceedsSoon(t, func() error {
+		if repl.CircuitBreakerTripped() {
+			return errors.New("circuit breaker still tripped")
+		}
+		return nil
+	})
+	require.NoError(t, store.DB().Put(ctx, key, "c"))
+}
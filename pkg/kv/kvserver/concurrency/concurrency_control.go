@@ -15,6 +15,7 @@ package concurrency
 
 import (
 	"context"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
@@ -331,6 +332,11 @@ type Request struct {
 	// transactions.
 	WaitPolicy lock.WaitPolicy
 
+	// The maximum amount of time that the request will wait on each
+	// conflicting lock before giving up and returning a WriteIntentError. If
+	// zero, the request waits indefinitely.
+	LockTimeout time.Duration
+
 	// The individual requests in the batch.
 	Requests []roachpb.RequestUnion
 
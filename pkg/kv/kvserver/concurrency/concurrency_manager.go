@@ -276,6 +276,39 @@ func (m *managerImpl) HandleWriterIntentError(
 	// Guard. This is analogous to iterating through the loop in SequenceReq.
 	m.lm.Release(g.moveLatchGuard())
 
+	// A request with an Error wait policy does not wait for the intents. The
+	// holders of all of them are pushed at once, and the intents of the
+	// holders which are still active are returned in a single error. This
+	// allows the client to handle all the conflicts of the request at once,
+	// instead of discovering them one at a time by re-sequencing the request
+	// against the lockTable.
+	if g.Req.WaitPolicy == lock.WaitPolicy_Error {
+		var conflicts []roachpb.Intent
+		active := make(map[uuid.UUID]bool)
+		for i := range t.Intents {
+			intent := &t.Intents[i]
+			isActive, pushed := active[intent.Txn.ID]
+			if !pushed {
+				if err := m.ltw.WaitOnLock(ctx, g.Req, intent); err != nil {
+					if _, ok := err.GetDetail().(*roachpb.WriteIntentError); !ok {
+						m.FinishReq(g)
+						return nil, err
+					}
+					isActive = true
+				}
+				active[intent.Txn.ID] = isActive
+			}
+			if isActive {
+				conflicts = append(conflicts, *intent)
+			}
+		}
+		if len(conflicts) > 0 {
+			m.FinishReq(g)
+			return nil, roachpb.NewError(&roachpb.WriteIntentError{Intents: conflicts})
+		}
+		return g, nil
+	}
+
 	// If the lockTable was disabled then we need to immediately wait on the
 	// intents to ensure that they are resolved and moved out of the request's
 	// way.
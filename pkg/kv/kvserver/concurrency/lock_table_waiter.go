@@ -129,6 +129,20 @@ type IntentResolver interface {
 func (w *lockTableWaiterImpl) WaitOn(
 	ctx context.Context, req Request, guard lockTableGuard,
 ) (err *Error) {
+	// If the request has a lock timeout, it bounds the time spent waiting in
+	// lock wait-queues and pushing the transactions that hold conflicting
+	// locks. Once it elapses, the request returns a WriteIntentError for the
+	// conflicting lock or reservation that it was last waiting on.
+	var lastState waitingState
+	parentCtx := ctx
+	ctx, cancel := withLockTimeout(ctx, req)
+	defer cancel()
+	defer func() {
+		if err != nil && lockTimeoutExceeded(parentCtx, ctx) && lastState.txn != nil {
+			err = newWriteIntentErr(lastState)
+		}
+	}()
+
 	newStateC := guard.NewStateChan()
 	ctxDoneC := ctx.Done()
 	shouldQuiesceC := w.stopper.ShouldQuiesce()
@@ -156,6 +170,7 @@ func (w *lockTableWaiterImpl) WaitOn(
 			h.emitAndInit(state)
 			switch state.kind {
 			case waitFor, waitForDistinguished:
+				lastState = state
 				if req.WaitPolicy == lock.WaitPolicy_Error {
 					// If the waiter has an Error wait policy, resolve the conflict
 					// immediately without waiting. If the conflict is a lock then
@@ -255,6 +270,7 @@ func (w *lockTableWaiterImpl) WaitOn(
 				timerWaitingState = state
 
 			case waitElsewhere:
+				lastState = state
 				// The lockTable has hit a memory limit and is no longer maintaining
 				// proper lock wait-queues.
 				if !state.held {
@@ -367,13 +383,20 @@ func (w *lockTableWaiterImpl) WaitOnLock(
 	if err != nil {
 		return roachpb.NewError(err)
 	}
-	return w.pushLockTxn(ctx, req, waitingState{
+	ws := waitingState{
 		kind:        waitFor,
 		txn:         &intent.Txn,
 		key:         intent.Key,
 		held:        true,
 		guardAccess: sa,
-	})
+	}
+	pushCtx, cancel := withLockTimeout(ctx, req)
+	defer cancel()
+	pErr := w.pushLockTxn(pushCtx, req, ws)
+	if pErr != nil && lockTimeoutExceeded(ctx, pushCtx) {
+		pErr = newWriteIntentErr(ws)
+	}
+	return pErr
 }
 
 // pushLockTxn pushes the holder of the provided lock.
@@ -741,6 +764,22 @@ func (h *contentionEventHelper) emitAndInit(s waitingState) {
 	}
 }
 
+// withLockTimeout returns a context that expires once the request's lock
+// timeout elapses. If the request has no lock timeout, ctx is returned as is.
+func withLockTimeout(ctx context.Context, req Request) (context.Context, context.CancelFunc) {
+	if req.LockTimeout == 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, req.LockTimeout)
+}
+
+// lockTimeoutExceeded returns whether the context returned by withLockTimeout
+// for parentCtx expired because the lock timeout elapsed, as opposed to
+// parentCtx itself being canceled.
+func lockTimeoutExceeded(parentCtx, ctx context.Context) bool {
+	return parentCtx.Err() == nil && errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
+
 func newWriteIntentErr(ws waitingState) *Error {
 	return roachpb.NewError(&roachpb.WriteIntentError{
 		Intents: []roachpb.Intent{roachpb.MakeIntent(ws.txn, ws.key)},
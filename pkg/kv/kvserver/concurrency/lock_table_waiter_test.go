@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/intentresolver"
@@ -485,6 +486,98 @@ func testErrorWaitPush(t *testing.T, k waitKind, makeReq func() Request, expPush
 	})
 }
 
+// TestLockTableWaiterWithLockTimeout tests that a request with a lock timeout
+// stops waiting on a conflicting lock or reservation once the timeout elapses
+// and returns a WriteIntentError for the conflict.
+func TestLockTableWaiterWithLockTimeout(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	keyA := roachpb.Key("keyA")
+
+	makeReq := func(lockTimeout time.Duration) Request {
+		txn := makeTxnProto("request")
+		req := Request{
+			Txn:         &txn,
+			Timestamp:   txn.ReadTimestamp,
+			LockTimeout: lockTimeout,
+			LockSpans:   &spanset.SpanSet{},
+		}
+		req.LockSpans.AddNonMVCC(spanset.SpanReadWrite, roachpb.Span{Key: keyA})
+		return req
+	}
+	// The pushee is an active transaction, so pushes of it block until they
+	// are canceled.
+	blockingPush := func(
+		ctx context.Context, _ *enginepb.TxnMeta, _ roachpb.Header, _ roachpb.PushTxnType,
+	) (*roachpb.Transaction, *Error) {
+		<-ctx.Done()
+		return nil, roachpb.NewError(ctx.Err())
+	}
+	requireWriteIntentErr := func(t *testing.T, err *Error, pusheeTxn roachpb.Transaction) {
+		require.NotNil(t, err)
+		wiErr, ok := err.GetDetail().(*roachpb.WriteIntentError)
+		require.True(t, ok, "unexpected error: %v", err)
+		require.Len(t, wiErr.Intents, 1)
+		require.Equal(t, keyA, wiErr.Intents[0].Key)
+		require.Equal(t, pusheeTxn.ID, wiErr.Intents[0].Txn.ID)
+	}
+
+	testutils.RunTrueAndFalse(t, "lockHeld", func(t *testing.T, lockHeld bool) {
+		w, ir, g := setupLockTableWaiterTest()
+		defer w.stopper.Stop(ctx)
+		pusheeTxn := makeTxnProto("pushee")
+		ir.pushTxn = blockingPush
+
+		g.state = waitingState{
+			kind:        waitForDistinguished,
+			txn:         &pusheeTxn.TxnMeta,
+			key:         keyA,
+			held:        lockHeld,
+			guardAccess: spanset.SpanReadWrite,
+		}
+		g.notify()
+
+		err := w.WaitOn(ctx, makeReq(time.Millisecond), g)
+		requireWriteIntentErr(t, err, pusheeTxn)
+	})
+
+	t.Run("WaitOnLock", func(t *testing.T) {
+		w, ir, _ := setupLockTableWaiterTest()
+		defer w.stopper.Stop(ctx)
+		pusheeTxn := makeTxnProto("pushee")
+		ir.pushTxn = blockingPush
+
+		intent := roachpb.MakeIntent(&pusheeTxn.TxnMeta, keyA)
+		err := w.WaitOnLock(ctx, makeReq(time.Millisecond), &intent)
+		requireWriteIntentErr(t, err, pusheeTxn)
+	})
+
+	// If the request's context is canceled before its lock timeout elapses,
+	// the context cancellation is returned instead.
+	t.Run("canceled", func(t *testing.T) {
+		w, ir, g := setupLockTableWaiterTest()
+		defer w.stopper.Stop(ctx)
+		pusheeTxn := makeTxnProto("pushee")
+		ir.pushTxn = blockingPush
+
+		g.state = waitingState{
+			kind:        waitForDistinguished,
+			txn:         &pusheeTxn.TxnMeta,
+			key:         keyA,
+			held:        true,
+			guardAccess: spanset.SpanReadWrite,
+		}
+		g.notify()
+
+		cancelCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		err := w.WaitOn(cancelCtx, makeReq(time.Hour), g)
+		require.NotNil(t, err)
+		require.Regexp(t, "context canceled", err)
+	})
+}
+
 // TestLockTableWaiterIntentResolverError tests that the lockTableWaiter
 // propagates errors from its intent resolver when it pushes transactions
 // or resolves their intents.
@@ -24,7 +24,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/gc"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverbase"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -35,6 +37,9 @@ import (
 const (
 	// gcQueueTimerDuration is the duration between GCs of queued replicas.
 	gcQueueTimerDuration = 1 * time.Second
+	// gcQueueOverloadedTimerDuration is the duration between GCs of queued
+	// replicas while the store's LSM is overloaded.
+	gcQueueOverloadedTimerDuration = 10 * time.Second
 	// intentAgeNormalization is the average age of outstanding intents
 	// which amount to a score of "1" added to total replica priority.
 	intentAgeNormalization = 24 * time.Hour // 1 day
@@ -510,9 +515,24 @@ func updateStoreMetricsWithGCInfo(metrics *StoreMetrics, info gc.Info) {
 	metrics.GCResolveTotal.Inc(int64(info.ResolveTotal))
 }
 
-// timer returns a constant duration to space out GC processing
-// for successive queued replicas.
-func (*gcQueue) timer(_ time.Duration) time.Duration {
+// timer returns a duration to space out GC processing for successive queued
+// replicas. GC is paced more slowly while the store has too many L0
+// sub-levels, since the writes performed by GC add to the compaction debt
+// that foreground traffic is already suffering from.
+func (gcq *gcQueue) timer(_ time.Duration) time.Duration {
+	m, err := gcq.store.Engine().GetMetrics()
+	if err != nil {
+		return gcQueueTimerDuration
+	}
+	return gcQueueTimer(&gcq.store.ClusterSettings().SV, m.L0SublevelCount)
+}
+
+// gcQueueTimer returns the duration between GCs of queued replicas of a store
+// with the given number of L0 sub-levels.
+func gcQueueTimer(sv *settings.Values, l0SubLevels int64) time.Duration {
+	if l0SubLevels > admission.L0SubLevelCountOverloadThreshold.Get(sv) {
+		return gcQueueOverloadedTimerDuration
+	}
 	return gcQueueTimerDuration
 }
 
@@ -33,6 +33,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/rditer"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/rpc"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util"
@@ -366,6 +367,18 @@ func (r *Replica) ShouldBackpressureWrites() bool {
 	return r.shouldBackpressureWrites()
 }
 
+// CircuitBreakerTripped returns whether the circuit breaker of the replica is
+// tripped.
+func (r *Replica) CircuitBreakerTripped() bool {
+	return r.breaker.isTripped()
+}
+
+// SetReplicaCircuitBreakerSlowReplicationThreshold overrides the
+// kv.replica_circuit_breaker.slow_replication_threshold setting.
+func SetReplicaCircuitBreakerSlowReplicationThreshold(sv *settings.Values, d time.Duration) {
+	replicaCircuitBreakerSlowReplicationThreshold.Override(sv, d)
+}
+
 // GetRaftLogSize returns the approximate raft log size and whether it is
 // trustworthy.. See r.mu.raftLogSize for details.
 func (r *Replica) GetRaftLogSize() (int64, bool) {
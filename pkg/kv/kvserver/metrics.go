@@ -92,6 +92,12 @@ var (
 		Measurement: "Ranges",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaReplicaCircuitBreakerCurTripped = metric.Metadata{
+		Name:        "kv.replica_circuit_breaker.num_tripped_replicas",
+		Help:        "Number of replicas for which the per-replica circuit breaker is currently tripped",
+		Measurement: "Replicas",
+		Unit:        metric.Unit_COUNT,
+	}
 
 	// Lease request metrics.
 	metaLeaseRequestSuccessCount = metric.Metadata{
@@ -1058,6 +1064,9 @@ type StoreMetrics struct {
 	UnderReplicatedRangeCount *metric.Gauge
 	OverReplicatedRangeCount  *metric.Gauge
 
+	// Replica circuit breaker metrics.
+	ReplicaCircuitBreakerCurTripped *metric.Gauge
+
 	// Lease request metrics for successful and failed lease requests. These
 	// count proposals (i.e. it does not matter how many replicas apply the
 	// lease).
@@ -1430,6 +1439,9 @@ func newStoreMetrics(histogramWindow time.Duration) *StoreMetrics {
 		UnderReplicatedRangeCount: metric.NewGauge(metaUnderReplicatedRangeCount),
 		OverReplicatedRangeCount:  metric.NewGauge(metaOverReplicatedRangeCount),
 
+		// Replica circuit breaker metrics.
+		ReplicaCircuitBreakerCurTripped: metric.NewGauge(metaReplicaCircuitBreakerCurTripped),
+
 		// Lease request metrics.
 		LeaseRequestSuccessCount:  metric.NewCounter(metaLeaseRequestSuccessCount),
 		LeaseRequestErrorCount:    metric.NewCounter(metaLeaseRequestErrorCount),
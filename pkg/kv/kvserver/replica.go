@@ -206,6 +206,10 @@ type Replica struct {
 	// in order to aid in replica rebalancing decisions.
 	writeStats *replicaStats
 
+	// breaker fails writes fast while the replica is unable to replicate
+	// commands.
+	breaker replicaCircuitBreaker
+
 	// creatingReplica is set when a replica is created as uninitialized
 	// via a raft message.
 	creatingReplica *roachpb.ReplicaDescriptor
@@ -188,7 +188,8 @@ func (c *replicatedCmd) finishTracingSpan() {
 // decode decodes the entry e into the decodedRaftEntry.
 func (d *decodedRaftEntry) decode(ctx context.Context, e *raftpb.Entry) error {
 	*d = decodedRaftEntry{}
-	// etcd raft sometimes inserts nil commands, ours are never nil.
+	// etcd raft sometimes inserts nil commands, ours are never nil (except
+	// for the ones proposed by the probe of a tripped circuit breaker).
 	// This case is handled upstream of this call.
 	if len(e.Data) == 0 {
 		return nil
@@ -0,0 +1,192 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+	"go.etcd.io/etcd/raft/v3"
+)
+
+// replicaCircuitBreakerSlowReplicationThreshold is the duration after which a
+// proposal which has not been applied trips the circuit breaker of its
+// replica.
+var replicaCircuitBreakerSlowReplicationThreshold = settings.RegisterDurationSetting(
+	"kv.replica_circuit_breaker.slow_replication_threshold",
+	"duration after which slow proposals trip the per-replica circuit breaker, "+
+		"causing writes to the range to fail fast until the range makes progress "+
+		"again; zero disables the breaker",
+	0,
+	settings.NonNegativeDuration,
+).WithPublic()
+
+// errReplicaUnavailable marks the errors returned to the requests rejected by
+// a tripped circuit breaker.
+var errReplicaUnavailable = errors.New("replica unavailable")
+
+// IsReplicaUnavailableError returns true if the error was returned because the
+// circuit breaker of the replica was tripped.
+func IsReplicaUnavailableError(err error) bool {
+	return errors.Is(err, errReplicaUnavailable)
+}
+
+// replicaCircuitBreaker fails the writes to a replica fast while the replica
+// is unable to replicate commands, for example because its range has lost
+// quorum, instead of letting them hang indefinitely.
+//
+// The breaker is tripped by a proposal which has not been applied after
+// kv.replica_circuit_breaker.slow_replication_threshold, and reset as soon as
+// the replica applies committed entries again. While it is tripped, a probe
+// periodically proposes an empty command to the range (see
+// launchCircuitBreakerProbe), so that the breaker is reset once the range is
+// available again even if no other command is proposed. Reads are not
+// rejected, since they can be served without replication as long as the
+// replica holds a valid lease, and neither are lease and admin requests, which
+// may be needed to restore the availability of the range.
+type replicaCircuitBreaker struct {
+	// tripped is 1 while the breaker is tripped. It allows checking the breaker
+	// without acquiring the mutex.
+	tripped int32
+
+	mu struct {
+		syncutil.Mutex
+		// err is returned to the requests rejected while the breaker is tripped.
+		err error
+	}
+}
+
+// isTripped returns true if the breaker is tripped.
+func (b *replicaCircuitBreaker) isTripped() bool {
+	return atomic.LoadInt32(&b.tripped) == 1
+}
+
+// err returns the error which tripped the breaker, or nil if the breaker is not
+// tripped.
+func (b *replicaCircuitBreaker) err() error {
+	if !b.isTripped() {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.mu.err
+}
+
+// trip trips the breaker with the given error, unless it is already tripped.
+// It returns the error the breaker is tripped with, and whether the breaker
+// was tripped by this call.
+func (b *replicaCircuitBreaker) trip(err error) (_ error, tripped bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.mu.err != nil {
+		return b.mu.err, false
+	}
+	b.mu.err = err
+	atomic.StoreInt32(&b.tripped, 1)
+	return err, true
+}
+
+// reset resets the breaker. It returns true if the breaker was tripped.
+func (b *replicaCircuitBreaker) reset() bool {
+	if !b.isTripped() {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	wasTripped := b.mu.err != nil
+	b.mu.err = nil
+	atomic.StoreInt32(&b.tripped, 0)
+	return wasTripped
+}
+
+// checkCircuitBreaker returns an error if the batch must be rejected because
+// the circuit breaker of the replica is tripped.
+func (r *Replica) checkCircuitBreaker(ba *roachpb.BatchRequest) error {
+	if ba.IsReadOnly() || ba.IsAdmin() || ba.IsSingleSkipLeaseCheckRequest() {
+		return nil
+	}
+	return r.breaker.err()
+}
+
+// tripCircuitBreaker trips the circuit breaker of the replica because the
+// given batch has not been applied after the given duration. It returns the
+// error the breaker is tripped with.
+func (r *Replica) tripCircuitBreaker(
+	ctx context.Context, ba *roachpb.BatchRequest, dur time.Duration,
+) error {
+	desc := r.Desc()
+	err, tripped := r.breaker.trip(errors.Wrapf(errReplicaUnavailable,
+		"r%d: command %s has not been applied after %.2fs; descriptor: %s",
+		desc.RangeID, ba, dur.Seconds(), desc))
+	if tripped {
+		log.Errorf(ctx, "tripped circuit breaker: %v", err)
+		r.launchCircuitBreakerProbe()
+	}
+	return err
+}
+
+// circuitBreakerProbeInterval is the interval at which the probe of a tripped
+// circuit breaker proposes an empty command.
+const circuitBreakerProbeInterval = time.Second
+
+// launchCircuitBreakerProbe starts a task which proposes an empty command to
+// the range every circuitBreakerProbeInterval until the circuit breaker of the
+// replica is reset. The breaker is reset when any committed entry is applied,
+// including the empty commands. Without the probe, the breaker would stay
+// tripped if the command which tripped it was dropped (for example because
+// the lease changed hands), since no other write is proposed while the breaker
+// is tripped.
+//
+// Like the empty entries appended by a new leader, the applied empty commands
+// cause the pending proposals of the replica to be reproposed.
+func (r *Replica) launchCircuitBreakerProbe() {
+	ctx := r.AnnotateCtx(context.Background())
+	stopper := r.store.Stopper()
+	_ = stopper.RunAsyncTask(ctx, "replica-circuit-breaker-probe", func(ctx context.Context) {
+		ticker := time.NewTicker(circuitBreakerProbeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-stopper.ShouldQuiesce():
+				return
+			}
+			if !r.breaker.isTripped() {
+				return
+			}
+			if err := r.withRaftGroup(true, func(raftGroup *raft.RawNode) (bool, error) {
+				// The proposal is dropped if the replica does not know the
+				// leader; it is retried at the next tick.
+				if err := raftGroup.Propose(nil); err != nil && !errors.Is(err, raft.ErrProposalDropped) {
+					return true, err
+				}
+				return true, nil
+			}); err != nil {
+				log.VEventf(ctx, 1, "stopping circuit breaker probe: %v", err)
+				return
+			}
+		}
+	})
+}
+
+// maybeResetCircuitBreaker resets the circuit breaker of the replica, which
+// has just applied committed entries.
+func (r *Replica) maybeResetCircuitBreaker(ctx context.Context) {
+	if r.breaker.reset() {
+		log.Infof(ctx, "reset circuit breaker")
+	}
+}
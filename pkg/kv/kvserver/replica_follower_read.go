@@ -52,16 +52,18 @@ func (r *Replica) canServeFollowerRead(
 		return pErr
 	}
 
-	// There's no known reason that a non-VOTER_FULL replica couldn't serve follower
-	// reads (or RangeFeed), but as of the time of writing, these are expected
-	// to be short-lived, so it's not worth working out the edge-cases. Revisit if
-	// we add long-lived learners or feel that incoming/outgoing voters also need
-	// to be able to serve follower reads.
+	// Non-voting replicas are long-lived and apply the same log as the voters,
+	// so they can serve follower reads; this is what they are meant for. There's
+	// no known reason that the other non-VOTER_FULL replicas couldn't serve
+	// follower reads (or RangeFeed), but as of the time of writing, these are
+	// expected to be short-lived, so it's not worth working out the edge-cases.
+	// Revisit if we feel that learners or incoming/outgoing voters also need to
+	// be able to serve follower reads.
 	repDesc, err := r.GetReplicaDescriptor()
 	if err != nil {
 		return roachpb.NewError(err)
 	}
-	if typ := repDesc.GetType(); typ != roachpb.VOTER_FULL {
+	if typ := repDesc.GetType(); typ != roachpb.VOTER_FULL && typ != roachpb.NON_VOTER {
 		log.Eventf(ctx, "%s replicas cannot serve follower reads", typ)
 		return pErr
 	}
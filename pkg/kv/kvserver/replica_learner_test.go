@@ -854,6 +854,47 @@ func TestLearnerAndJointConfigFollowerRead(t *testing.T) {
 	check()
 }
 
+// TestNonVoterFollowerRead verifies that non-voting replicas can serve
+// follower reads.
+func TestNonVoterFollowerRead(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	// Limiting how long transactions can run does not work well with race
+	// unless we're extremely lenient, which drives up the test duration.
+	skip.UnderRace(t)
+
+	ctx := context.Background()
+	tc := testcluster.StartTestCluster(t, 2, base.TestClusterArgs{
+		ReplicationMode: base.ReplicationManual,
+	})
+	defer tc.Stopper().Stop(ctx)
+	db := sqlutils.MakeSQLRunner(tc.ServerConn(0))
+	db.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.target_duration = $1`, testingTargetDuration)
+	db.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.close_fraction = $1`, testingCloseFraction)
+	db.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.follower_reads_enabled = true`)
+
+	scratchStartKey := tc.ScratchRange(t)
+	scratchDesc := tc.AddNonVotersOrFatal(t, scratchStartKey, tc.Target(1))
+	require.Len(t, scratchDesc.Replicas().NonVoterDescriptors(), 1)
+
+	req := roachpb.BatchRequest{Header: roachpb.Header{
+		RangeID:   scratchDesc.RangeID,
+		Timestamp: tc.Server(0).Clock().Now(),
+	}}
+	req.Add(&roachpb.ScanRequest{RequestHeader: roachpb.RequestHeader{
+		Key: scratchDesc.StartKey.AsRawKey(), EndKey: scratchDesc.EndKey.AsRawKey(),
+	}})
+
+	_, repl := getFirstStoreReplica(t, tc.Server(1), scratchStartKey)
+	testutils.SucceedsSoon(t, func() error {
+		// The read succeeds once the closed timestamp of the non-voter has passed
+		// the timestamp of the request.
+		_, pErr := repl.Send(ctx, req)
+		return pErr.GoError()
+	})
+}
+
 func TestLearnerOrJointConfigAdminRelocateRange(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -46,6 +46,9 @@ type ReplicaMetrics struct {
 	LatchInfoLocal  kvserverpb.LatchManagerInfo
 	LatchInfoGlobal kvserverpb.LatchManagerInfo
 	RaftLogTooLarge bool
+	// CircuitBreakerTripped is true if the circuit breaker of the replica is
+	// tripped.
+	CircuitBreakerTripped bool
 }
 
 // Metrics returns the current metrics for the replica.
@@ -68,7 +71,7 @@ func (r *Replica) Metrics(
 
 	latchInfoGlobal, latchInfoLocal := r.concMgr.LatchMetrics()
 
-	return calcReplicaMetrics(
+	m := calcReplicaMetrics(
 		ctx,
 		now,
 		&r.store.cfg.RaftConfig,
@@ -86,6 +89,8 @@ func (r *Replica) Metrics(
 		raftLogSize,
 		raftLogSizeTrusted,
 	)
+	m.CircuitBreakerTripped = r.breaker.isTripped()
+	return m
 }
 
 func calcReplicaMetrics(
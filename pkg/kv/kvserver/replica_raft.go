@@ -67,12 +67,22 @@ func makeIDKey() kvserverbase.CmdIDKey {
 // - the MaxLeaseIndex of the resulting proposal, if any.
 // - any error obtained during the creation or proposal of the command, in
 //   which case the other returned values are zero.
+//
+// evalDone, if not nil, is called once the request is evaluated, before the
+// command is proposed.
 func (r *Replica) evalAndPropose(
-	ctx context.Context, ba *roachpb.BatchRequest, g *concurrency.Guard, lease *roachpb.Lease,
+	ctx context.Context,
+	ba *roachpb.BatchRequest,
+	g *concurrency.Guard,
+	lease *roachpb.Lease,
+	evalDone func(),
 ) (chan proposalResult, func(), int64, *roachpb.Error) {
 	idKey := makeIDKey()
 	proposal, pErr := r.requestToProposal(ctx, idKey, ba, g.LatchSpans())
 	log.Event(proposal.ctx, "evaluated request")
+	if evalDone != nil {
+		evalDone()
+	}
 
 	// If the request hit a server-side concurrency retry error, immediately
 	// proagate the error. Don't assume ownership of the concurrency guard.
@@ -780,9 +790,12 @@ func (r *Replica) handleRaftReadyRaftMuLocked(
 		} else if err != nil {
 			return stats, getNonDeterministicFailureExplanation(err), err
 		}
+		// The range is able to commit entries, so it is not unavailable.
+		r.maybeResetCircuitBreaker(ctx)
 
 		// etcd raft occasionally adds a nil entry (our own commands are never
-		// empty). This happens in two situations: When a new leader is elected, and
+		// empty, except for the ones proposed by the probe of a tripped circuit
+		// breaker). This happens in two situations: When a new leader is elected, and
 		// when a config change is dropped due to the "one at a time" rule. In both
 		// cases we may need to resubmit our pending proposals (In the former case
 		// we resubmit everything because we proposed them to a former leader that
@@ -15,6 +15,7 @@ import (
 	"reflect"
 
 	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/batcheval"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
@@ -22,7 +23,10 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/spanset"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/txnwait"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/errors"
 )
@@ -72,6 +76,12 @@ func (r *Replica) sendWithRangeID(
 	if err := r.maybeRateLimitBatch(ctx, ba); err != nil {
 		return nil, roachpb.NewError(err)
 	}
+	if err := r.checkCircuitBreaker(ba); err != nil {
+		return nil, roachpb.NewError(err)
+	}
+	if err := r.checkDiskFull(ba); err != nil {
+		return nil, roachpb.NewError(err)
+	}
 
 	// NB: must be performed before collecting request spans.
 	ba, err := maybeStripInFlightWrites(ba)
@@ -246,8 +256,13 @@ func returnRangeInfoIfClientStale(
 // concurrency retry error" (see isConcurrencyRetryError for more details). If
 // the function returns one of these errors, it must also pass ownership of the
 // concurrency guard back to the caller.
+//
+// The function is also provided with a callback that releases the admission
+// slot of the batch. It calls it once the batch is evaluated, before waiting
+// for the batch to be replicated, so that slots are not held during
+// replication. The callback may be called more than once.
 type batchExecutionFn func(
-	*Replica, context.Context, *roachpb.BatchRequest, kvserverpb.LeaseStatus, *concurrency.Guard,
+	*Replica, context.Context, *roachpb.BatchRequest, kvserverpb.LeaseStatus, *concurrency.Guard, func(),
 ) (*roachpb.BatchResponse, *concurrency.Guard, *roachpb.Error)
 
 var _ batchExecutionFn = (*Replica).executeWriteBatch
@@ -279,6 +294,11 @@ func (r *Replica) executeBatchWithConcurrencyRetries(
 			r.concMgr.FinishReq(g)
 		}
 	}()
+	releaseAdmission := func() {}
+	defer func() {
+		// NB: wrapped to release the slot of the last admission.
+		releaseAdmission()
+	}()
 	for {
 		// Exit loop if context has been canceled or timed out.
 		if err := ctx.Err(); err != nil {
@@ -320,6 +340,16 @@ func (r *Replica) executeBatchWithConcurrencyRetries(
 			r.recordBatchForLoadBasedSplitting(ctx, ba, latchSpans)
 		}
 
+		// Admission control queues the batch before it acquires latches, so
+		// that batches waiting for admission don't hold latches needed by
+		// batches that were admitted. The admission slot is released once the
+		// batch is evaluated, before it is proposed to Raft, so that batches
+		// don't hold slots while they are replicated.
+		var err error
+		if releaseAdmission, err = r.admitBatch(ctx, ba); err != nil {
+			return nil, roachpb.NewError(errors.Wrap(err, "aborted during admission"))
+		}
+
 		// Acquire latches to prevent overlapping requests from executing until
 		// this request completes. After latching, wait on any conflicting locks
 		// to ensure that the request has full isolation during evaluation. This
@@ -331,6 +361,7 @@ func (r *Replica) executeBatchWithConcurrencyRetries(
 			Priority:        ba.UserPriority,
 			ReadConsistency: ba.ReadConsistency,
 			WaitPolicy:      ba.WaitPolicy,
+			LockTimeout:     ba.LockTimeout,
 			Requests:        ba.Requests,
 			LatchSpans:      latchSpans,
 			LockSpans:       lockSpans,
@@ -349,7 +380,10 @@ func (r *Replica) executeBatchWithConcurrencyRetries(
 			}
 		}
 
-		br, g, pErr = fn(r, ctx, ba, status, g)
+		br, g, pErr = fn(r, ctx, ba, status, g, releaseAdmission)
+		// Release the slot before handling retry errors, which may wait on
+		// other transactions.
+		releaseAdmission()
 		if pErr == nil {
 			// Success.
 			return br, nil
@@ -399,6 +433,90 @@ func (r *Replica) executeBatchWithConcurrencyRetries(
 	}
 }
 
+// admitBatch blocks until the batch is admitted by the store's admission
+// queue. It returns a function which releases the admission slot of the batch,
+// which must be called once the batch is evaluated. Calls to the function after
+// the first one are no-ops.
+func (r *Replica) admitBatch(ctx context.Context, ba *roachpb.BatchRequest) (func(), error) {
+	q := r.store.cfg.KVAdmissionQ
+	if q == nil || bypassAdmission(ba) {
+		return func() {}, nil
+	}
+	tenantID, ok := roachpb.TenantFromContext(ctx)
+	if !ok {
+		tenantID = roachpb.SystemTenantID
+	}
+	admitted, err := q.Admit(ctx, makeAdmissionWorkInfo(tenantID, ba))
+	if err != nil || !admitted {
+		return func() {}, err
+	}
+	released := false
+	return func() {
+		if !released {
+			released = true
+			q.AdmittedWorkDone(tenantID.ToUint64())
+		}
+	}, nil
+}
+
+// bypassAdmission returns true if the batch must not be queued by admission
+// control. Requests to the system keyspace and to system tables, of the system
+// tenant or of secondary tenants (such as node liveness heartbeats, meta range
+// lookups and SQL liveness heartbeats), are never queued, since delaying them
+// could cause nodes or SQL pods to be considered unavailable.
+func bypassAdmission(ba *roachpb.BatchRequest) bool {
+	if len(ba.Requests) == 0 {
+		return true
+	}
+	for _, ru := range ba.Requests {
+		if isSystemKey(ru.GetInner().Header().Key) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSystemKey returns true if the key is below the user table keyspace of its
+// tenant.
+func isSystemKey(key roachpb.Key) bool {
+	rem, _, err := keys.DecodeTenantPrefix(key)
+	if err != nil {
+		return false
+	}
+	return roachpb.Key(rem).Compare(keys.UserTableDataMin) < 0
+}
+
+// makeAdmissionWorkInfo returns the admission control information of a batch.
+// Bulk requests (such as the AddSSTable requests issued by index backfills and
+// imports) and requests of transactions with LOW priority are admitted after
+// other requests, while requests of transactions with HIGH priority are
+// admitted first. Among requests of the same priority, the requests of the
+// tenants that use fewer slots are admitted first.
+func makeAdmissionWorkInfo(tenantID roachpb.TenantID, ba *roachpb.BatchRequest) admission.WorkInfo {
+	info := admission.WorkInfo{
+		TenantID: tenantID.ToUint64(),
+		Priority: admission.NormalPri,
+	}
+	if ba.Txn != nil {
+		info.CreateTime = ba.Txn.MinTimestamp.WallTime
+		switch ba.Txn.Priority {
+		case enginepb.MinTxnPriority:
+			info.Priority = admission.LowPri
+		case enginepb.MaxTxnPriority:
+			info.Priority = admission.HighPri
+		}
+	} else {
+		info.CreateTime = timeutil.Now().UnixNano()
+	}
+	for _, ru := range ba.Requests {
+		switch ru.GetInner().(type) {
+		case *roachpb.AddSSTableRequest, *roachpb.ExportRequest, *roachpb.RevertRangeRequest:
+			info.Priority = admission.LowPri
+		}
+	}
+	return info
+}
+
 // isConcurrencyRetryError returns whether or not the provided error is a
 // "server-side concurrency retry error" that will be captured and retried by
 // executeBatchWithConcurrencyRetries. Server-side concurrency retry errors are
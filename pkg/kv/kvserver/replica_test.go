@@ -54,6 +54,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -614,7 +615,7 @@ func sendLeaseRequest(r *Replica, l *roachpb.Lease) error {
 	ba.Timestamp = r.store.Clock().Now()
 	ba.Add(&roachpb.RequestLeaseRequest{Lease: *l})
 	exLease, _ := r.GetLease()
-	ch, _, _, pErr := r.evalAndPropose(context.Background(), &ba, allSpansGuard(), &exLease)
+	ch, _, _, pErr := r.evalAndPropose(context.Background(), &ba, allSpansGuard(), &exLease, nil /* evalDone */)
 	if pErr == nil {
 		// Next if the command was committed, wait for the range to apply it.
 		// TODO(bdarnell): refactor this to a more conventional error-handling pattern.
@@ -1410,7 +1411,7 @@ func TestReplicaLeaseRejectUnknownRaftNodeID(t *testing.T) {
 	ba := roachpb.BatchRequest{}
 	ba.Timestamp = tc.repl.store.Clock().Now()
 	ba.Add(&roachpb.RequestLeaseRequest{Lease: *lease})
-	ch, _, _, pErr := tc.repl.evalAndPropose(context.Background(), &ba, allSpansGuard(), &exLease)
+	ch, _, _, pErr := tc.repl.evalAndPropose(context.Background(), &ba, allSpansGuard(), &exLease, nil /* evalDone */)
 	if pErr == nil {
 		// Next if the command was committed, wait for the range to apply it.
 		// TODO(bdarnell): refactor to a more conventional error-handling pattern.
@@ -7847,7 +7848,7 @@ func TestReplicaCancelRaftCommandProgress(t *testing.T) {
 				Key: roachpb.Key(fmt.Sprintf("k%d", i)),
 			},
 		})
-		ch, _, idx, err := repl.evalAndPropose(ctx, &ba, allSpansGuard(), &lease)
+		ch, _, idx, err := repl.evalAndPropose(ctx, &ba, allSpansGuard(), &lease, nil /* evalDone */)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -7917,7 +7918,7 @@ func TestReplicaBurstPendingCommandsAndRepropose(t *testing.T) {
 				Key: roachpb.Key(fmt.Sprintf("k%d", i)),
 			},
 		})
-		ch, _, idx, err := tc.repl.evalAndPropose(ctx, &ba, allSpansGuard(), &lease)
+		ch, _, idx, err := tc.repl.evalAndPropose(ctx, &ba, allSpansGuard(), &lease, nil /* evalDone */)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -9246,7 +9247,7 @@ func TestErrorInRaftApplicationClearsIntents(t *testing.T) {
 	}
 
 	exLease, _ := repl.GetLease()
-	ch, _, _, pErr := repl.evalAndPropose(context.Background(), &ba, allSpansGuard(), &exLease)
+	ch, _, _, pErr := repl.evalAndPropose(context.Background(), &ba, allSpansGuard(), &exLease, nil /* evalDone */)
 	if pErr != nil {
 		t.Fatal(pErr)
 	}
@@ -9292,7 +9293,7 @@ func TestProposeWithAsyncConsensus(t *testing.T) {
 
 	atomic.StoreInt32(&filterActive, 1)
 	exLease, _ := repl.GetLease()
-	ch, _, _, pErr := repl.evalAndPropose(context.Background(), &ba, allSpansGuard(), &exLease)
+	ch, _, _, pErr := repl.evalAndPropose(context.Background(), &ba, allSpansGuard(), &exLease, nil /* evalDone */)
 	if pErr != nil {
 		t.Fatal(pErr)
 	}
@@ -9356,7 +9357,7 @@ func TestApplyPaginatedCommittedEntries(t *testing.T) {
 
 	atomic.StoreInt32(&filterActive, 1)
 	exLease, _ := repl.GetLease()
-	_, _, _, pErr := repl.evalAndPropose(ctx, &ba, allSpansGuard(), &exLease)
+	_, _, _, pErr := repl.evalAndPropose(ctx, &ba, allSpansGuard(), &exLease, nil /* evalDone */)
 	if pErr != nil {
 		t.Fatal(pErr)
 	}
@@ -9374,7 +9375,7 @@ func TestApplyPaginatedCommittedEntries(t *testing.T) {
 		ba2.Timestamp = tc.Clock().Now()
 
 		var pErr *roachpb.Error
-		ch, _, _, pErr = repl.evalAndPropose(ctx, &ba2, allSpansGuard(), &exLease)
+		ch, _, _, pErr = repl.evalAndPropose(ctx, &ba2, allSpansGuard(), &exLease, nil /* evalDone */)
 		if pErr != nil {
 			t.Fatal(pErr)
 		}
@@ -12489,7 +12490,7 @@ func TestProposalNotAcknowledgedOrReproposedAfterApplication(t *testing.T) {
 	tc.repl.RaftLock()
 	sp := cfg.AmbientCtx.Tracer.StartSpan("replica send", tracing.WithForceRealSpan())
 	tracedCtx := tracing.ContextWithSpan(ctx, sp)
-	ch, _, _, pErr := tc.repl.evalAndPropose(tracedCtx, &ba, allSpansGuard(), &lease)
+	ch, _, _, pErr := tc.repl.evalAndPropose(tracedCtx, &ba, allSpansGuard(), &lease, nil /* evalDone */)
 	if pErr != nil {
 		t.Fatal(pErr)
 	}
@@ -12581,7 +12582,7 @@ func TestLaterReproposalsDoNotReuseContext(t *testing.T) {
 	// Go out of our way to enable recording so that expensive logging is enabled
 	// for this context.
 	sp.SetVerbose(true)
-	ch, _, _, pErr := tc.repl.evalAndPropose(tracedCtx, &ba, allSpansGuard(), &lease)
+	ch, _, _, pErr := tc.repl.evalAndPropose(tracedCtx, &ba, allSpansGuard(), &lease, nil /* evalDone */)
 	if pErr != nil {
 		t.Fatal(pErr)
 	}
@@ -13071,3 +13072,173 @@ func TestRangeInfoReturned(t *testing.T) {
 		})
 	}
 }
+
+func TestBypassAdmission(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	tenantCodec := keys.MakeSQLCodec(roachpb.MakeTenantID(10))
+	get := func(key roachpb.Key) roachpb.Request {
+		return &roachpb.GetRequest{RequestHeader: roachpb.RequestHeader{Key: key}}
+	}
+	for _, tc := range []struct {
+		name   string
+		keys   []roachpb.Key
+		bypass bool
+	}{
+		{name: "liveness", keys: []roachpb.Key{keys.NodeLivenessKey(1)}, bypass: true},
+		{name: "meta", keys: []roachpb.Key{keys.RangeMetaKey(roachpb.RKey("a")).AsRawKey()}, bypass: true},
+		{name: "system table", keys: []roachpb.Key{keys.SystemSQLCodec.TablePrefix(keys.JobsTableID)}, bypass: true},
+		{name: "user table", keys: []roachpb.Key{keys.SystemSQLCodec.TablePrefix(keys.MinUserDescID)}, bypass: false},
+		{name: "tenant system table", keys: []roachpb.Key{tenantCodec.TablePrefix(keys.SqllivenessID)}, bypass: true},
+		{name: "tenant user table", keys: []roachpb.Key{tenantCodec.TablePrefix(keys.MinUserDescID)}, bypass: false},
+		{
+			name: "system key after user key",
+			keys: []roachpb.Key{
+				tenantCodec.TablePrefix(keys.MinUserDescID), tenantCodec.TablePrefix(keys.SqllivenessID),
+			},
+			bypass: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var ba roachpb.BatchRequest
+			for _, key := range tc.keys {
+				ba.Add(get(key))
+			}
+			require.Equal(t, tc.bypass, bypassAdmission(&ba))
+		})
+	}
+}
+
+// TestAdmissionReleasedBeforeProposal verifies that a write batch gives back
+// its admission slot once it is evaluated, before it waits for replication.
+func TestAdmissionReleasedBeforeProposal(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+
+	key := keys.SystemSQLCodec.TablePrefix(keys.MinUserDescID)
+	var tc testContext
+	cfg := TestStoreConfig(nil)
+	admission.KVAdmissionControlEnabled.Override(&cfg.Settings.SV, true)
+	q := admission.NewWorkQueue(cfg.Settings, 1 /* totalSlots */)
+	cfg.KVAdmissionQ = q
+	var proposed int32
+	cfg.TestingKnobs.TestingProposalFilter = func(args kvserverbase.ProposalFilterArgs) *roachpb.Error {
+		if args.Req.IsSingleRequest() && args.Req.Requests[0].GetPut() != nil &&
+			args.Req.Requests[0].GetPut().Key.Equal(key) {
+			// The queue only has one slot, so this only succeeds if the proposing
+			// batch has already released it.
+			admitCtx, cancel := context.WithTimeout(args.Ctx, 10*time.Second)
+			defer cancel()
+			if _, err := q.Admit(admitCtx, admission.WorkInfo{TenantID: roachpb.SystemTenantID.ToUint64()}); err != nil {
+				return roachpb.NewErrorf("admission slot held during proposal: %v", err)
+			}
+			q.AdmittedWorkDone(roachpb.SystemTenantID.ToUint64())
+			atomic.StoreInt32(&proposed, 1)
+		}
+		return nil
+	}
+	tc.StartWithStoreConfig(t, stopper, cfg)
+
+	pArgs := putArgs(key, []byte("value"))
+	_, pErr := kv.SendWrapped(ctx, tc.Sender(), &pArgs)
+	require.Nil(t, pErr)
+	require.Equal(t, int32(1), atomic.LoadInt32(&proposed))
+}
+
+func TestCheckCircuitBreaker(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	var r Replica
+	key := roachpb.Key("a")
+	put := &roachpb.PutRequest{RequestHeader: roachpb.RequestHeader{Key: key}}
+	get := &roachpb.GetRequest{RequestHeader: roachpb.RequestHeader{Key: key}}
+	requestLease := &roachpb.RequestLeaseRequest{RequestHeader: roachpb.RequestHeader{Key: key}}
+	transferLease := &roachpb.TransferLeaseRequest{RequestHeader: roachpb.RequestHeader{Key: key}}
+	split := &roachpb.AdminSplitRequest{RequestHeader: roachpb.RequestHeader{Key: key}}
+	makeBatch := func(req roachpb.Request) *roachpb.BatchRequest {
+		var ba roachpb.BatchRequest
+		ba.Add(req)
+		return &ba
+	}
+
+	require.NoError(t, r.checkCircuitBreaker(makeBatch(put)))
+	_, tripped := r.breaker.trip(errors.Wrap(errReplicaUnavailable, "boom"))
+	require.True(t, tripped)
+
+	// Writes are rejected, while reads, lease requests and admin requests are
+	// not.
+	require.True(t, IsReplicaUnavailableError(r.checkCircuitBreaker(makeBatch(put))))
+	for _, req := range []roachpb.Request{get, requestLease, transferLease, split} {
+		require.NoError(t, r.checkCircuitBreaker(makeBatch(req)), "%s", req.Method())
+	}
+
+	require.True(t, r.breaker.reset())
+	require.NoError(t, r.checkCircuitBreaker(makeBatch(put)))
+}
+
+func TestCheckDiskFull(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	cleanup, eng := newOnDiskEngine(t)
+	defer cleanup()
+	defer eng.Close()
+	s := &Store{engine: eng, Ident: &roachpb.StoreIdent{StoreID: 1}}
+	var r Replica
+	r.store = s
+	r.mu.state.Desc = &roachpb.RangeDescriptor{
+		RangeID:  1,
+		StartKey: roachpb.RKey("a"),
+		EndKey:   roachpb.RKey("b"),
+	}
+	var liveness Replica
+	liveness.store = s
+	liveness.mu.state.Desc = &roachpb.RangeDescriptor{
+		RangeID:  2,
+		StartKey: roachpb.RKey(keys.NodeLivenessPrefix),
+		EndKey:   roachpb.RKey(keys.NodeLivenessKeyMax),
+	}
+
+	key := roachpb.Key("a")
+	put := &roachpb.PutRequest{RequestHeader: roachpb.RequestHeader{Key: key}}
+	get := &roachpb.GetRequest{RequestHeader: roachpb.RequestHeader{Key: key}}
+	requestLease := &roachpb.RequestLeaseRequest{RequestHeader: roachpb.RequestHeader{Key: key}}
+	split := &roachpb.AdminSplitRequest{RequestHeader: roachpb.RequestHeader{Key: key}}
+	makeBatch := func(req roachpb.Request) *roachpb.BatchRequest {
+		var ba roachpb.BatchRequest
+		ba.Add(req)
+		return &ba
+	}
+
+	// The ballast of a 1000 MiB store is 10 MiB, so it is full with less than
+	// 5 MiB available.
+	s.updateDiskFull(ctx, roachpb.StoreCapacity{Capacity: 1000 << 20, Available: 100 << 20})
+	require.NoError(t, r.checkDiskFull(makeBatch(put)))
+
+	// Writes are rejected, while reads, lease requests, admin requests and
+	// writes to node liveness are not.
+	s.updateDiskFull(ctx, roachpb.StoreCapacity{Capacity: 1000 << 20, Available: 1 << 20})
+	require.True(t, IsStoreDiskFullError(r.checkDiskFull(makeBatch(put))))
+	for _, req := range []roachpb.Request{get, requestLease, split} {
+		require.NoError(t, r.checkDiskFull(makeBatch(req)), "%s", req.Method())
+	}
+	require.NoError(t, liveness.checkDiskFull(makeBatch(put)))
+
+	// The store accepts writes again once space is freed up.
+	s.updateDiskFull(ctx, roachpb.StoreCapacity{Capacity: 1000 << 20, Available: 20 << 20})
+	require.NoError(t, r.checkDiskFull(makeBatch(put)))
+
+	// In-memory stores are never full.
+	inMem := storage.NewDefaultInMemForTesting()
+	defer inMem.Close()
+	r.store = &Store{engine: inMem, Ident: &roachpb.StoreIdent{StoreID: 2}}
+	r.store.updateDiskFull(ctx, roachpb.StoreCapacity{Capacity: 1000 << 20, Available: 1 << 20})
+	require.NoError(t, r.checkDiskFull(makeBatch(put)))
+}
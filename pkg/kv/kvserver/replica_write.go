@@ -64,7 +64,11 @@ import (
 // as this method makes the assumption that it operates on a shallow copy (see
 // call to applyTimestampCache).
 func (r *Replica) executeWriteBatch(
-	ctx context.Context, ba *roachpb.BatchRequest, st kvserverpb.LeaseStatus, g *concurrency.Guard,
+	ctx context.Context,
+	ba *roachpb.BatchRequest,
+	st kvserverpb.LeaseStatus,
+	g *concurrency.Guard,
+	evalDone func(),
 ) (br *roachpb.BatchResponse, _ *concurrency.Guard, pErr *roachpb.Error) {
 	startTime := timeutil.Now()
 
@@ -133,7 +137,7 @@ func (r *Replica) executeWriteBatch(
 	// If the command is proposed to Raft, ownership of and responsibility for
 	// the concurrency guard will be assumed by Raft, so provide the guard to
 	// evalAndPropose.
-	ch, abandon, maxLeaseIndex, pErr := r.evalAndPropose(ctx, ba, g, &st.Lease)
+	ch, abandon, maxLeaseIndex, pErr := r.evalAndPropose(ctx, ba, g, &st.Lease, evalDone)
 	if pErr != nil {
 		if maxLeaseIndex != 0 {
 			log.Fatalf(
@@ -173,6 +177,15 @@ func (r *Replica) executeWriteBatch(
 	slowTimer := timeutil.NewTimer()
 	defer slowTimer.Stop()
 	slowTimer.Reset(base.SlowRequestThreshold)
+	// If the command is not applied before the slow replication threshold,
+	// the replica's circuit breaker is tripped.
+	var breakerC <-chan time.Time
+	if threshold := replicaCircuitBreakerSlowReplicationThreshold.Get(&r.ClusterSettings().SV); threshold > 0 {
+		breakerTimer := timeutil.NewTimer()
+		defer breakerTimer.Stop()
+		breakerTimer.Reset(threshold)
+		breakerC = breakerTimer.C
+	}
 	// NOTE: this defer was moved from a case in the select statement to here
 	// because escape analysis does a better job avoiding allocations to the
 	// heap when defers are unconditional. When this was in the slowTimer select
@@ -278,6 +291,12 @@ func (r *Replica) executeWriteBatch(
 			rangeUnavailableMessage(&s, r.Desc(), r.store.cfg.NodeLiveness.GetIsLiveMap(),
 				r.RaftStatus(), ba, timeutil.Since(startPropTime))
 			log.Errorf(ctx, "range unavailable: %v", s)
+		case <-breakerC:
+			// Give up on the command, which may still be applied, and fail the
+			// subsequent writes fast until the range makes progress again.
+			abandon()
+			err := r.tripCircuitBreaker(ctx, ba, timeutil.Since(startPropTime))
+			return nil, nil, roachpb.NewError(roachpb.NewAmbiguousResultError(err.Error()))
 		case <-ctxDone:
 			// If our context was canceled, return an AmbiguousResultError,
 			// which indicates to the caller that the command may have executed.
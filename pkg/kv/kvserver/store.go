@@ -53,6 +53,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
 	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
@@ -470,6 +471,11 @@ type Store struct {
 	nodeDesc     *roachpb.NodeDescriptor
 	initComplete sync.WaitGroup // Signaled by async init tasks
 
+	// 1 if the disk of the store is full, in which case the store stops
+	// accepting writes, 0 otherwise. Updated atomically every time the
+	// capacity of the store is computed.
+	diskFull int32
+
 	// Semaphore to limit concurrent non-empty snapshot application.
 	snapshotApplySem chan struct{}
 
@@ -743,6 +749,10 @@ type StoreConfig struct {
 	// subsystem. It is queried during the GC process and in the handling of
 	// AdminVerifyProtectedTimestampRequest.
 	ProtectedTimestampCache protectedts.Cache
+
+	// KVAdmissionQ, if set, queues the evaluation of batches when the node is
+	// overloaded.
+	KVAdmissionQ *admission.WorkQueue
 }
 
 // ConsistencyTestingKnobs is a BatchEvalTestingKnobs struct used to control the
@@ -2534,6 +2544,8 @@ func (s *Store) updateReplicationGauges(ctx context.Context) error {
 		underreplicatedRangeCount int64
 		overreplicatedRangeCount  int64
 		behindCount               int64
+
+		circuitBreakerTrippedCount int64
 	)
 
 	timestamp := s.cfg.Clock.Now()
@@ -2578,6 +2590,9 @@ func (s *Store) updateReplicationGauges(ctx context.Context) error {
 			}
 		}
 		behindCount += metrics.BehindCount
+		if rep.breaker.isTripped() {
+			circuitBreakerTrippedCount++
+		}
 		if qps, dur := rep.leaseholderStats.avgQPS(); dur >= MinStatsDuration {
 			averageQueriesPerSecond += qps
 		}
@@ -2606,6 +2621,7 @@ func (s *Store) updateReplicationGauges(ctx context.Context) error {
 	s.metrics.UnderReplicatedRangeCount.Update(underreplicatedRangeCount)
 	s.metrics.OverReplicatedRangeCount.Update(overreplicatedRangeCount)
 	s.metrics.RaftLogFollowerBehindCount.Update(behindCount)
+	s.metrics.ReplicaCircuitBreakerCurTripped.Update(circuitBreakerTrippedCount)
 
 	if !minMaxClosedTS.IsEmpty() {
 		nanos := timeutil.Since(minMaxClosedTS.GoTime()).Nanoseconds()
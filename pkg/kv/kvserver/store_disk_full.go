@@ -0,0 +1,104 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// errStoreDiskFull marks the errors returned to the writes rejected because the
+// disk of the store is full.
+var errStoreDiskFull = errors.New("store disk full")
+
+// IsStoreDiskFullError returns true if the error was returned because the disk
+// of the store is full.
+func IsStoreDiskFullError(err error) bool {
+	return errors.Is(err, errStoreDiskFull)
+}
+
+// isDiskFull returns true if the disk of the store is full, in which case the
+// store rejects the writes that it would evaluate as the leaseholder of a
+// range, to slow down the growth of the disk usage instead of eventually
+// crashing the node with unrecoverable storage errors.
+//
+// This is enforced when requests are evaluated, not when Raft entries are
+// applied: the store keeps applying the entries proposed by the leaseholders
+// of the ranges it is a follower of, as well as snapshots, so its disk usage
+// can still grow. The operator is expected to free up space or move replicas
+// away from the store.
+func (s *Store) isDiskFull() bool {
+	return atomic.LoadInt32(&s.diskFull) == 1
+}
+
+// updateDiskFull updates whether the disk of the store is full given its
+// capacity, as defined by storage.IsCapacityFull, and alerts the operator
+// when the store starts or stops rejecting writes. In-memory stores are never
+// full, as for storage.IsDiskFull.
+func (s *Store) updateDiskFull(ctx context.Context, capacity roachpb.StoreCapacity) {
+	full := !s.engine.InMem() && storage.IsCapacityFull(capacity.Capacity, capacity.Available)
+	var val int32
+	if full {
+		val = 1
+	}
+	if atomic.SwapInt32(&s.diskFull, val) == val {
+		return
+	}
+	if full {
+		log.Ops.Errorf(ctx, "disk full: only %s of %s available; the writes evaluated by "+
+			"the leaseholders on this store are rejected until space is freed up, for "+
+			"example by removing the emergency ballast %s, but Raft entries proposed by "+
+			"other stores are still applied",
+			humanizeutil.IBytes(capacity.Available), humanizeutil.IBytes(capacity.Capacity),
+			s.emergencyBallastFile())
+	} else {
+		log.Ops.Infof(ctx, "disk no longer full: %s of %s available; the store accepts writes again",
+			humanizeutil.IBytes(capacity.Available), humanizeutil.IBytes(capacity.Capacity))
+	}
+}
+
+// emergencyBallastFile returns the path of the emergency ballast of the store.
+func (s *Store) emergencyBallastFile() string {
+	return base.EmergencyBallastFile(filepath.Dir(s.engine.GetAuxiliaryDir()))
+}
+
+// checkDiskFull returns an error if the batch must be rejected because the disk
+// of the store is full. It is called before the leaseholder evaluates the
+// batch. Reads are not rejected, and neither are lease and admin
+// requests, which may be needed to move the ranges away from the store. Writes
+// to the ranges below the timeseries data, which include the meta ranges and
+// node liveness, are allowed too, so that the node remains live and able to
+// serve reads.
+func (r *Replica) checkDiskFull(ba *roachpb.BatchRequest) error {
+	if !r.store.isDiskFull() {
+		return nil
+	}
+	if ba.IsReadOnly() || ba.IsAdmin() || ba.IsSingleSkipLeaseCheckRequest() {
+		return nil
+	}
+	desc := r.Desc()
+	if desc.StartKey.Less(roachpb.RKey(keys.TimeseriesPrefix)) {
+		return nil
+	}
+	return errors.WithHintf(
+		errors.Wrapf(errStoreDiskFull, "r%d: cannot write to s%d", desc.RangeID, r.store.StoreID()),
+		"free up disk space on the store, for example by removing the emergency ballast %s",
+		r.store.emergencyBallastFile())
+}
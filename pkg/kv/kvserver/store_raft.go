@@ -780,6 +780,7 @@ func (s *Store) updateCapacityGauges(ctx context.Context) error {
 	s.metrics.Capacity.Update(desc.Capacity.Capacity)
 	s.metrics.Available.Update(desc.Capacity.Available)
 	s.metrics.Used.Update(desc.Capacity.Used)
+	s.updateDiskFull(ctx, desc.Capacity)
 
 	return nil
 }
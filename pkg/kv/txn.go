@@ -71,6 +71,10 @@ type Txn struct {
 		// The txn has to be committed by this deadline. A nil value indicates no
 		// deadline.
 		deadline *hlc.Timestamp
+
+		// lockTimeout, if non-zero, is attached to all requests sent through
+		// this txn that don't specify their own lock timeout.
+		lockTimeout time.Duration
 	}
 }
 
@@ -912,6 +916,9 @@ func (txn *Txn) Send(
 	txn.mu.Lock()
 	requestTxnID := txn.mu.ID
 	sender := txn.mu.sender
+	if ba.Header.LockTimeout == 0 {
+		ba.Header.LockTimeout = txn.mu.lockTimeout
+	}
 	txn.mu.Unlock()
 	br, pErr := txn.db.sendUsingSender(ctx, ba, sender)
 	if pErr == nil {
@@ -1252,6 +1259,18 @@ func (txn *Txn) ConfigureStepping(ctx context.Context, mode SteppingMode) (prevM
 	return txn.mu.sender.ConfigureStepping(ctx, mode)
 }
 
+// SetLockTimeout configures the maximum amount of time that the requests sent
+// through the transaction wait on each conflicting lock before failing with a
+// WriteIntentError. A zero timeout lets them wait indefinitely. The previous
+// lock timeout is returned.
+func (txn *Txn) SetLockTimeout(timeout time.Duration) (prevTimeout time.Duration) {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	prevTimeout = txn.mu.lockTimeout
+	txn.mu.lockTimeout = timeout
+	return prevTimeout
+}
+
 // CreateSavepoint establishes a savepoint.
 // This method is only valid when called on RootTxns.
 func (txn *Txn) CreateSavepoint(ctx context.Context) (SavepointToken, error) {
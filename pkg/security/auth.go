@@ -95,10 +95,18 @@ func Contains(sl []string, s string) bool {
 	return false
 }
 
+// CertUserMapper maps a principal found in a client certificate to the
+// SQL users it is allowed to authenticate as.
+type CertUserMapper func(certUser string) ([]SQLUsername, error)
+
 // UserAuthCertHook builds an authentication hook based on the security
-// mode and client certificate.
-func UserAuthCertHook(insecureMode bool, tlsState *tls.ConnectionState) (UserAuthHook, error) {
-	var certUsers []string
+// mode and client certificate. If certUserMapper is non-nil, it is used
+// to map the principals in the certificate to SQL users; otherwise, the
+// principals must match the requested user exactly.
+func UserAuthCertHook(
+	insecureMode bool, tlsState *tls.ConnectionState, certUserMapper CertUserMapper,
+) (UserAuthHook, error) {
+	var certUsers, allowedUsers []string
 
 	if !insecureMode {
 		var err error
@@ -106,6 +114,24 @@ func UserAuthCertHook(insecureMode bool, tlsState *tls.ConnectionState) (UserAut
 		if err != nil {
 			return nil, err
 		}
+		allowedUsers = certUsers
+		if certUserMapper != nil {
+			allowedUsers = nil
+			for _, certUser := range certUsers {
+				if certUser == NodeUser {
+					// The node user is never mapped.
+					allowedUsers = append(allowedUsers, certUser)
+					continue
+				}
+				mapped, err := certUserMapper(certUser)
+				if err != nil {
+					return nil, err
+				}
+				for _, u := range mapped {
+					allowedUsers = append(allowedUsers, u.Normalized())
+				}
+			}
+		}
 	}
 
 	return func(requestedUser SQLUsername, clientConnection bool) (func(), error) {
@@ -135,7 +161,7 @@ func UserAuthCertHook(insecureMode bool, tlsState *tls.ConnectionState) (UserAut
 		// The client certificate user must match the requested user,
 		// except if the certificate user is NodeUser, which is allowed to
 		// act on behalf of all other users.
-		if !Contains(certUsers, requestedUser.Normalized()) && !Contains(certUsers, NodeUser) {
+		if !Contains(allowedUsers, requestedUser.Normalized()) && !Contains(allowedUsers, NodeUser) {
 			return nil, errors.Errorf("requested user is %s, but certificate is for %s", requestedUser, certUsers)
 		}
 
@@ -14,6 +14,7 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"os"
+	"unicode"
 
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/errors"
@@ -57,6 +58,9 @@ func appendEmptySha256(password string) []byte {
 // hash of the supplied password. If they are not equivalent, returns an
 // error.
 func CompareHashAndPassword(hashedPassword []byte, password string) error {
+	if IsScramHash(hashedPassword) {
+		return compareScramAndPassword(hashedPassword, password)
+	}
 	return bcrypt.CompareHashAndPassword(hashedPassword, appendEmptySha256(password))
 }
 
@@ -65,6 +69,19 @@ func HashPassword(password string) ([]byte, error) {
 	return bcrypt.GenerateFromPassword(appendEmptySha256(password), BcryptCost)
 }
 
+// HashPasswordWithMethod takes a raw password and returns it hashed using
+// the given method.
+func HashPasswordWithMethod(method PasswordHashMethod, password string) ([]byte, error) {
+	switch method {
+	case HashBCrypt:
+		return HashPassword(password)
+	case HashSCRAMSHA256:
+		return HashPasswordScram(password)
+	default:
+		return nil, errors.AssertionFailedf("unknown password hash method %d", method)
+	}
+}
+
 // PromptForPassword prompts for a password.
 // This is meant to be used when using a password.
 func PromptForPassword() (string, error) {
@@ -88,3 +105,66 @@ var MinPasswordLength = settings.RegisterIntSetting(
 	1,
 	settings.NonNegativeInt,
 )
+
+// ErrPasswordTooSimple indicates that a client provided a password that does
+// not contain enough characters of each class according to policy.
+var ErrPasswordTooSimple = errors.New("password too simple")
+
+// The cluster settings that configure the minimum number of characters of
+// each class in SQL passwords.
+var (
+	minPasswordUppercase = settings.RegisterIntSetting(
+		"server.user_login.password_complexity.min_uppercase",
+		"the minimum number of uppercase letters in passwords set in cleartext via SQL",
+		0,
+		settings.NonNegativeInt,
+	)
+	minPasswordLowercase = settings.RegisterIntSetting(
+		"server.user_login.password_complexity.min_lowercase",
+		"the minimum number of lowercase letters in passwords set in cleartext via SQL",
+		0,
+		settings.NonNegativeInt,
+	)
+	minPasswordDigits = settings.RegisterIntSetting(
+		"server.user_login.password_complexity.min_digits",
+		"the minimum number of digits in passwords set in cleartext via SQL",
+		0,
+		settings.NonNegativeInt,
+	)
+	minPasswordSymbols = settings.RegisterIntSetting(
+		"server.user_login.password_complexity.min_symbols",
+		"the minimum number of characters which are neither letters nor digits "+
+			"in passwords set in cleartext via SQL",
+		0,
+		settings.NonNegativeInt,
+	)
+)
+
+// CheckPasswordComplexity returns ErrPasswordTooSimple, with a hint listing
+// the requirements, if the password does not contain the minimum number of
+// characters of each class configured by the
+// server.user_login.password_complexity cluster settings.
+func CheckPasswordComplexity(sv *settings.Values, password string) error {
+	var upper, lower, digits, symbols int64
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		case unicode.IsDigit(r):
+			digits++
+		case !unicode.IsLetter(r):
+			symbols++
+		}
+	}
+	minUpper, minLower := minPasswordUppercase.Get(sv), minPasswordLowercase.Get(sv)
+	minDigits, minSymbols := minPasswordDigits.Get(sv), minPasswordSymbols.Get(sv)
+	if upper >= minUpper && lower >= minLower && digits >= minDigits && symbols >= minSymbols {
+		return nil
+	}
+	return errors.WithHintf(ErrPasswordTooSimple,
+		"Passwords must contain at least %d uppercase letters, %d lowercase letters, "+
+			"%d digits and %d other characters.",
+		minUpper, minLower, minDigits, minSymbols)
+}
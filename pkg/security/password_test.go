@@ -0,0 +1,57 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package security
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPasswordComplexity(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettings()
+	sv := &st.SV
+
+	// No requirement by default.
+	require.NoError(t, CheckPasswordComplexity(sv, "a"))
+
+	minPasswordUppercase.Override(sv, 1)
+	minPasswordLowercase.Override(sv, 2)
+	minPasswordDigits.Override(sv, 1)
+	minPasswordSymbols.Override(sv, 1)
+	for _, tc := range []struct {
+		password string
+		ok       bool
+	}{
+		{password: "Ab1!", ok: false},
+		{password: "abc1!", ok: false},
+		{password: "ABc1!", ok: false},
+		{password: "Abc!", ok: false},
+		{password: "Abc1", ok: false},
+		{password: "Abc1!", ok: true},
+		{password: "1 Abc", ok: true},
+		{password: "Éé1é€", ok: true},
+	} {
+		t.Run(tc.password, func(t *testing.T) {
+			err := CheckPasswordComplexity(sv, tc.password)
+			if tc.ok {
+				require.NoError(t, err)
+			} else {
+				require.True(t, errors.Is(err, ErrPasswordTooSimple), "unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,363 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package security
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/errors"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// This file implements the SCRAM-SHA-256 authentication mechanism (RFC
+// 5802, RFC 7677) in the way PostgreSQL uses it: the server stores a
+// "verifier" derived from the password, and clients prove knowledge of
+// the password without sending it, or anything from which it could be
+// replayed, over the wire.
+//
+// Verifiers are stored in the same column as bcrypt hashes, using the
+// PostgreSQL textual representation:
+//
+//    SCRAM-SHA-256$<iterations>:<salt>$<StoredKey>:<ServerKey>
+//
+// where the salt and the keys are base64-encoded.
+
+// ScramMechanismName is the name of the SASL mechanism implemented here.
+const ScramMechanismName = "SCRAM-SHA-256"
+
+// scramHashPrefix is the prefix of the textual representation of
+// SCRAM-SHA-256 verifiers.
+const scramHashPrefix = ScramMechanismName + "$"
+
+// ScramIterationCount is the number of PBKDF2 iterations used when
+// computing new SCRAM verifiers. It is exposed for testing.
+//
+// The default matches the one used by PostgreSQL.
+var ScramIterationCount = 4096
+
+// scramSaltLength is the length in bytes of the random salt used for new
+// SCRAM verifiers.
+const scramSaltLength = 16
+
+// scramNonceLength is the length in bytes of the random nonce generated by
+// the server during a SCRAM exchange.
+const scramNonceLength = 18
+
+// PasswordHashMethod identifies the algorithm used to encode the passwords
+// stored in system.users.
+type PasswordHashMethod int64
+
+const (
+	// HashBCrypt is the CockroachDB-specific bcrypt encoding. Passwords
+	// encoded this way can only be checked against cleartext passwords.
+	HashBCrypt PasswordHashMethod = 1
+	// HashSCRAMSHA256 is the SCRAM-SHA-256 verifier encoding. Passwords
+	// encoded this way can be checked against cleartext passwords and
+	// can be used for SCRAM-SHA-256 authentication.
+	HashSCRAMSHA256 PasswordHashMethod = 2
+)
+
+// PasswordHashMethodSetting is the cluster setting that configures which
+// encoding is used for passwords set via CREATE/ALTER USER/ROLE WITH
+// PASSWORD.
+//
+// To migrate a cluster to SCRAM authentication, the setting is first
+// changed to scram-sha-256; then users reset their password, which stores
+// a SCRAM verifier; finally the HBA configuration is changed to require
+// the scram-sha-256 method. Users whose password is still stored as a
+// bcrypt hash can keep using the password method in the meantime.
+var PasswordHashMethodSetting = settings.RegisterEnumSetting(
+	"server.user_login.password_encryption",
+	"which hash method to use to encode cleartext passwords passed via ALTER/CREATE USER/ROLE WITH PASSWORD; "+
+		"scram-sha-256 falls back to crdb-bcrypt until the cluster version is upgraded",
+	"crdb-bcrypt",
+	map[int64]string{
+		int64(HashBCrypt):      "crdb-bcrypt",
+		int64(HashSCRAMSHA256): "scram-sha-256",
+	},
+)
+
+// ScramVerifier is the information stored by the server to authenticate a
+// user with SCRAM-SHA-256.
+type ScramVerifier struct {
+	Iterations int
+	Salt       []byte
+	StoredKey  []byte
+	ServerKey  []byte
+}
+
+// IsScramHash returns true iff the given hashed password is a SCRAM
+// verifier.
+func IsScramHash(hashedPassword []byte) bool {
+	return bytes.HasPrefix(hashedPassword, []byte(scramHashPrefix))
+}
+
+// HashPasswordScram computes a SCRAM-SHA-256 verifier for the given
+// password using a random salt and returns its textual representation.
+func HashPasswordScram(password string) ([]byte, error) {
+	salt := make([]byte, scramSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	v := makeScramVerifier(password, salt, ScramIterationCount)
+	return v.Encode(), nil
+}
+
+// MockScramVerifier returns the verifier used to run a SCRAM exchange with a
+// client connecting as a user which cannot authenticate with SCRAM, such as a
+// user with no password or whose password is stored as a bcrypt hash. No
+// password matches the verifier. Its salt is derived from the given server
+// secret and user name, so that it is stable across attempts and
+// unpredictable by clients: the exchange does not reveal that the user cannot
+// authenticate with SCRAM.
+func MockScramVerifier(secret []byte, username string) ScramVerifier {
+	key := scramHMAC(secret, []byte(username))
+	return ScramVerifier{
+		Iterations: ScramIterationCount,
+		Salt:       scramHMAC(key, []byte("Salt"))[:scramSaltLength],
+		StoredKey:  scramHMAC(key, []byte("Stored Key")),
+		ServerKey:  scramHMAC(key, []byte("Server Key")),
+	}
+}
+
+// makeScramVerifier computes the SCRAM-SHA-256 verifier of a password.
+//
+// Note that the SASLprep normalization is not applied to the password: it is
+// used as-is, which is also what PostgreSQL does for passwords that are not
+// valid UTF-8.
+func makeScramVerifier(password string, salt []byte, iterations int) ScramVerifier {
+	saltedPassword := pbkdf2.Key([]byte(password), salt, iterations, sha256.Size, sha256.New)
+	clientKey := scramHMAC(saltedPassword, []byte("Client Key"))
+	storedKey := sha256.Sum256(clientKey)
+	serverKey := scramHMAC(saltedPassword, []byte("Server Key"))
+	return ScramVerifier{
+		Iterations: iterations,
+		Salt:       salt,
+		StoredKey:  storedKey[:],
+		ServerKey:  serverKey,
+	}
+}
+
+// Encode returns the textual representation of the verifier.
+func (v ScramVerifier) Encode() []byte {
+	enc := base64.StdEncoding
+	var buf bytes.Buffer
+	buf.WriteString(scramHashPrefix)
+	buf.WriteString(strconv.Itoa(v.Iterations))
+	buf.WriteByte(':')
+	buf.WriteString(enc.EncodeToString(v.Salt))
+	buf.WriteByte('$')
+	buf.WriteString(enc.EncodeToString(v.StoredKey))
+	buf.WriteByte(':')
+	buf.WriteString(enc.EncodeToString(v.ServerKey))
+	return buf.Bytes()
+}
+
+// ParseScramVerifier decodes the textual representation of a SCRAM-SHA-256
+// verifier.
+func ParseScramVerifier(hashedPassword []byte) (ScramVerifier, error) {
+	var v ScramVerifier
+	if !IsScramHash(hashedPassword) {
+		return v, errors.New("not a SCRAM-SHA-256 verifier")
+	}
+	parts := strings.Split(string(hashedPassword[len(scramHashPrefix):]), "$")
+	if len(parts) != 2 {
+		return v, errors.New("malformed SCRAM-SHA-256 verifier")
+	}
+	iterSalt := strings.Split(parts[0], ":")
+	keys := strings.Split(parts[1], ":")
+	if len(iterSalt) != 2 || len(keys) != 2 {
+		return v, errors.New("malformed SCRAM-SHA-256 verifier")
+	}
+	var err error
+	if v.Iterations, err = strconv.Atoi(iterSalt[0]); err != nil || v.Iterations <= 0 {
+		return v, errors.New("invalid iteration count in SCRAM-SHA-256 verifier")
+	}
+	enc := base64.StdEncoding
+	if v.Salt, err = enc.DecodeString(iterSalt[1]); err != nil {
+		return v, errors.Wrap(err, "invalid salt in SCRAM-SHA-256 verifier")
+	}
+	if v.StoredKey, err = enc.DecodeString(keys[0]); err != nil || len(v.StoredKey) != sha256.Size {
+		return v, errors.New("invalid stored key in SCRAM-SHA-256 verifier")
+	}
+	if v.ServerKey, err = enc.DecodeString(keys[1]); err != nil || len(v.ServerKey) != sha256.Size {
+		return v, errors.New("invalid server key in SCRAM-SHA-256 verifier")
+	}
+	return v, nil
+}
+
+// compareScramAndPassword checks a cleartext password against a SCRAM
+// verifier.
+func compareScramAndPassword(hashedPassword []byte, password string) error {
+	v, err := ParseScramVerifier(hashedPassword)
+	if err != nil {
+		return err
+	}
+	candidate := makeScramVerifier(password, v.Salt, v.Iterations)
+	if subtle.ConstantTimeCompare(candidate.StoredKey, v.StoredKey) != 1 ||
+		subtle.ConstantTimeCompare(candidate.ServerKey, v.ServerKey) != 1 {
+		return errors.New("password does not match SCRAM-SHA-256 verifier")
+	}
+	return nil
+}
+
+func scramHMAC(key, msg []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write(msg)
+	return h.Sum(nil)
+}
+
+// ErrScramProofMismatch is returned by (*ScramServerExchange).HandleClientFinal
+// when the client did not prove knowledge of the password.
+var ErrScramProofMismatch = errors.New("SCRAM-SHA-256 client proof does not match")
+
+// ScramServerExchange holds the server-side state of a SCRAM-SHA-256
+// exchange. The exchange proceeds as follows:
+//
+//   client-first-message  -> HandleClientFirst -> server-first-message
+//   client-final-message  -> HandleClientFinal -> server-final-message
+//
+// Channel binding is not supported.
+type ScramServerExchange struct {
+	verifier ScramVerifier
+
+	// gs2Header is the GS2 header sent by the client in its first message.
+	gs2Header string
+	// nonce is the combined client and server nonce.
+	nonce string
+	// clientFirstBare and serverFirst are the messages exchanged so far,
+	// needed to compute the AuthMessage.
+	clientFirstBare string
+	serverFirst     string
+}
+
+// NewScramServerExchange starts a SCRAM-SHA-256 exchange against the given
+// verifier.
+func NewScramServerExchange(verifier ScramVerifier) *ScramServerExchange {
+	return &ScramServerExchange{verifier: verifier}
+}
+
+// HandleClientFirst processes the client-first-message and returns the
+// server-first-message.
+func (e *ScramServerExchange) HandleClientFirst(msg []byte) ([]byte, error) {
+	serverNonce := make([]byte, scramNonceLength)
+	if _, err := rand.Read(serverNonce); err != nil {
+		return nil, err
+	}
+	return e.handleClientFirstWithNonce(string(msg), base64.StdEncoding.EncodeToString(serverNonce))
+}
+
+func (e *ScramServerExchange) handleClientFirstWithNonce(
+	msg string, serverNonce string,
+) ([]byte, error) {
+	// client-first-message = gs2-header client-first-message-bare
+	// gs2-header = gs2-cbind-flag "," [ authzid ] ","
+	if len(msg) < 3 {
+		return nil, errors.New("malformed SCRAM client-first-message")
+	}
+	switch msg[0] {
+	case 'n', 'y':
+		// No channel binding, or channel binding supported by the client
+		// but not by the server, which we don't advertise.
+	case 'p':
+		return nil, errors.New("SCRAM channel binding is not supported")
+	default:
+		return nil, errors.New("malformed SCRAM client-first-message")
+	}
+	if msg[1] != ',' {
+		return nil, errors.New("malformed SCRAM client-first-message")
+	}
+	idx := strings.IndexByte(msg[2:], ',')
+	if idx < 0 {
+		return nil, errors.New("malformed SCRAM client-first-message")
+	}
+	if idx != 0 {
+		return nil, errors.New("SCRAM authorization identities are not supported")
+	}
+	e.gs2Header = msg[:3]
+	e.clientFirstBare = msg[3:]
+
+	// client-first-message-bare = [reserved-mext ","] username "," nonce
+	// The user name is ignored, as is done by PostgreSQL: the user was
+	// already specified in the startup message.
+	var clientNonce string
+	for _, attr := range strings.Split(e.clientFirstBare, ",") {
+		if strings.HasPrefix(attr, "m=") {
+			return nil, errors.New("SCRAM mandatory extensions are not supported")
+		}
+		if strings.HasPrefix(attr, "r=") {
+			clientNonce = attr[2:]
+		}
+	}
+	if clientNonce == "" {
+		return nil, errors.New("SCRAM client-first-message does not contain a nonce")
+	}
+	e.nonce = clientNonce + serverNonce
+	e.serverFirst = "r=" + e.nonce +
+		",s=" + base64.StdEncoding.EncodeToString(e.verifier.Salt) +
+		",i=" + strconv.Itoa(e.verifier.Iterations)
+	return []byte(e.serverFirst), nil
+}
+
+// HandleClientFinal processes the client-final-message and returns the
+// server-final-message. ErrScramProofMismatch is returned if the client
+// proof is invalid.
+func (e *ScramServerExchange) HandleClientFinal(msg []byte) ([]byte, error) {
+	// client-final-message = client-final-message-without-proof "," proof
+	s := string(msg)
+	idx := strings.LastIndex(s, ",p=")
+	if idx < 0 {
+		return nil, errors.New("SCRAM client-final-message does not contain a proof")
+	}
+	withoutProof := s[:idx]
+	proof, err := base64.StdEncoding.DecodeString(s[idx+len(",p="):])
+	if err != nil || len(proof) != sha256.Size {
+		return nil, errors.New("malformed SCRAM client proof")
+	}
+
+	var channelBinding, nonce string
+	for _, attr := range strings.Split(withoutProof, ",") {
+		switch {
+		case strings.HasPrefix(attr, "c="):
+			channelBinding = attr[2:]
+		case strings.HasPrefix(attr, "r="):
+			nonce = attr[2:]
+		}
+	}
+	if channelBinding != base64.StdEncoding.EncodeToString([]byte(e.gs2Header)) {
+		return nil, errors.New("SCRAM channel binding does not match the client-first-message")
+	}
+	if nonce != e.nonce {
+		return nil, errors.New("SCRAM nonce does not match")
+	}
+
+	authMessage := []byte(e.clientFirstBare + "," + e.serverFirst + "," + withoutProof)
+	clientSignature := scramHMAC(e.verifier.StoredKey, authMessage)
+	clientKey := make([]byte, sha256.Size)
+	for i := range clientKey {
+		clientKey[i] = proof[i] ^ clientSignature[i]
+	}
+	storedKey := sha256.Sum256(clientKey)
+	if subtle.ConstantTimeCompare(storedKey[:], e.verifier.StoredKey) != 1 {
+		return nil, ErrScramProofMismatch
+	}
+
+	serverSignature := scramHMAC(e.verifier.ServerKey, authMessage)
+	return []byte("v=" + base64.StdEncoding.EncodeToString(serverSignature)), nil
+}
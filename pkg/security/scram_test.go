@@ -0,0 +1,106 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package security
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScramVerifier(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	hashed, err := HashPasswordScram("pencil")
+	require.NoError(t, err)
+	require.True(t, IsScramHash(hashed))
+
+	v, err := ParseScramVerifier(hashed)
+	require.NoError(t, err)
+	require.Equal(t, ScramIterationCount, v.Iterations)
+	require.Equal(t, hashed, v.Encode())
+
+	require.NoError(t, CompareHashAndPassword(hashed, "pencil"))
+	require.Error(t, CompareHashAndPassword(hashed, "pen"))
+
+	// Bcrypt hashes are still accepted.
+	bcryptHashed, err := HashPasswordWithMethod(HashBCrypt, "pencil")
+	require.NoError(t, err)
+	require.False(t, IsScramHash(bcryptHashed))
+	require.NoError(t, CompareHashAndPassword(bcryptHashed, "pencil"))
+
+	for _, bad := range []string{
+		"SCRAM-SHA-256$",
+		"SCRAM-SHA-256$4096:abc",
+		"SCRAM-SHA-256$x:c2FsdA==$a:b",
+		"SCRAM-SHA-256$4096:c2FsdA==$a:b",
+	} {
+		_, err := ParseScramVerifier([]byte(bad))
+		require.Error(t, err, bad)
+	}
+}
+
+// TestScramExchange checks the server side of the exchange against the
+// example in RFC 7677, section 3.
+func TestScramExchange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	salt, err := base64.StdEncoding.DecodeString("W22ZaJ0SNY7soEsUEjb6gQ==")
+	require.NoError(t, err)
+	v := makeScramVerifier("pencil", salt, 4096)
+
+	const serverNonce = "%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0"
+	const clientFinalWithoutProof = "c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0"
+
+	e := NewScramServerExchange(v)
+	serverFirst, err := e.handleClientFirstWithNonce("n,,n=user,r=rOprNGfwEbeRWgbNEkqO", serverNonce)
+	require.NoError(t, err)
+	require.Equal(t,
+		"r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096",
+		string(serverFirst))
+
+	serverFinal, err := e.HandleClientFinal([]byte(
+		clientFinalWithoutProof + ",p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ="))
+	require.NoError(t, err)
+	require.Equal(t, "v=6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4=", string(serverFinal))
+
+	// A wrong proof is rejected.
+	e = NewScramServerExchange(v)
+	_, err = e.handleClientFirstWithNonce("n,,n=user,r=rOprNGfwEbeRWgbNEkqO", serverNonce)
+	require.NoError(t, err)
+	_, err = e.HandleClientFinal([]byte(
+		clientFinalWithoutProof + ",p=AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="))
+	require.Equal(t, ErrScramProofMismatch, err)
+
+	// Channel binding is not supported.
+	e = NewScramServerExchange(v)
+	_, err = e.handleClientFirstWithNonce("p=tls-server-end-point,,n=user,r=abc", serverNonce)
+	require.Error(t, err)
+}
+
+func TestMockScramVerifier(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	secret := []byte("secret")
+	v := MockScramVerifier(secret, "alice")
+	// The salt looks like the salt of a real verifier, and is stable across
+	// attempts.
+	require.Len(t, v.Salt, scramSaltLength)
+	require.Equal(t, ScramIterationCount, v.Iterations)
+	require.Equal(t, v, MockScramVerifier(secret, "alice"))
+	// It depends on the user and on the server secret.
+	require.NotEqual(t, v.Salt, MockScramVerifier(secret, "bob").Salt)
+	require.NotEqual(t, v.Salt, MockScramVerifier([]byte("other"), "alice").Salt)
+	// The user's name is not a valid password.
+	require.Error(t, CompareHashAndPassword(v.Encode(), "alice"))
+}
@@ -1396,6 +1396,11 @@ func (s *adminServer) checkReadinessForHealthCheck(ctx context.Context) error {
 		// has requested DrainMode_LEASES but not DrainMode_CLIENT.
 		return status.Errorf(codes.Unavailable, "node is shutting down")
 	}
+	if !l.Membership.Active() {
+		// The node is being decommissioned and will be removed from the
+		// cluster; clients should move to other nodes.
+		return status.Errorf(codes.Unavailable, "node is decommissioning")
+	}
 
 	if !s.server.sqlServer.acceptingClients.Get() {
 		return status.Errorf(codes.Unavailable, "node is not accepting SQL clients")
@@ -34,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/liveness/livenesspb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server/debug"
@@ -1377,6 +1378,28 @@ func TestHealthAPI(t *testing.T) {
 		t.Errorf("server not ready after SQL listener is ready again: %v", err)
 	}
 
+	// A decommissioning node is not ready.
+	if err := ts.Decommission(
+		ctx, livenesspb.MembershipStatus_DECOMMISSIONING, []roachpb.NodeID{ts.NodeID()},
+	); err != nil {
+		t.Fatal(err)
+	}
+	testutils.SucceedsSoon(t, func() error {
+		err := getAdminJSONProto(s, "health?ready=1", &resp)
+		if !testutils.IsError(err, `(?s)503 Service Unavailable.*"error": "node is decommissioning"`) {
+			return errors.Errorf("expected decommissioning error, got %v", err)
+		}
+		return nil
+	})
+	if err := ts.Decommission(
+		ctx, livenesspb.MembershipStatus_ACTIVE, []roachpb.NodeID{ts.NodeID()},
+	); err != nil {
+		t.Fatal(err)
+	}
+	testutils.SucceedsSoon(t, func() error {
+		return getAdminJSONProto(s, "health?ready=1", &resp)
+	})
+
 	// Expire this node's liveness record by pausing heartbeats and advancing the
 	// server's clock.
 	defer ts.nodeLiveness.PauseAllHeartbeatsForTest()()
@@ -0,0 +1,154 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	gojson "encoding/json"
+	"mime"
+	"net/http"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// This file implements the session management of the API endpoints
+// which authenticate their requests with the APISessionHeader header
+// instead of the session cookie.
+//
+// A session is created by a POST to the login endpoint of a JSON
+// object of the form:
+//
+//     {"username": "my_user", "password": "my_password"}
+//
+// The response is a JSON object of the form:
+//
+//     {"session": "<session>"}
+//
+// The session must then be passed in the APISessionHeader header of
+// the requests to the API endpoints. It expires after
+// server.web_session_timeout, or when it is revoked by a POST to the
+// logout endpoint with the session in the header.
+
+const (
+	// apiV2LoginPath is the path of the API login endpoint.
+	apiV2LoginPath = "/api/v2/login/"
+	// apiV2LogoutPath is the path of the API logout endpoint.
+	apiV2LogoutPath = "/api/v2/logout/"
+)
+
+type apiV2LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type apiV2LoginResponse struct {
+	Session string `json:"session"`
+}
+
+type apiV2LogoutResponse struct {
+	LoggedOut bool `json:"logged_out"`
+}
+
+// apiV2Login creates a session for the user whose credentials are in the
+// body of the request.
+func (s *authenticationServer) apiV2Login(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if r.Method != http.MethodPost {
+		http.Error(w, "the login endpoint only accepts POST requests", http.StatusMethodNotAllowed)
+		return
+	}
+	if !isJSONRequest(r) {
+		http.Error(w, "the request must have the application/json content type",
+			http.StatusUnsupportedMediaType)
+		return
+	}
+	var req apiV2LoginRequest
+	if err := gojson.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Username == "" {
+		http.Error(w, "no username was provided", http.StatusBadRequest)
+		return
+	}
+
+	// As in UserLogin, the username is normalized so that the sessions of
+	// a user are all recorded under the same name.
+	username, _ := security.MakeSQLUsernameFromUserInput(req.Username, security.UsernameValidation)
+	verified, expired, err := s.verifyPassword(ctx, username, req.Password)
+	if pgwire.IsLockedOutError(err) {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		log.Errorf(ctx, "verifying the password of %s: %v", username, err)
+		http.Error(w, "an internal error occurred", http.StatusInternalServerError)
+		return
+	}
+	if expired {
+		http.Error(w, "the password for "+username.Normalized()+" has expired", http.StatusUnauthorized)
+		return
+	}
+	if !verified {
+		http.Error(w, "the provided credentials did not match any account on the server",
+			http.StatusUnauthorized)
+		return
+	}
+
+	cookie, err := s.createSessionFor(ctx, username)
+	if err != nil {
+		log.Errorf(ctx, "creating a session for %s: %v", username, err)
+		http.Error(w, "an internal error occurred", http.StatusInternalServerError)
+		return
+	}
+	writeAPIV2JSONResponse(ctx, w, &apiV2LoginResponse{Session: cookie.Value})
+}
+
+// apiV2Logout revokes the session of the request. It must be wrapped in an
+// authenticationMux which reads the session from the APISessionHeader
+// header.
+func (s *authenticationServer) apiV2Logout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if r.Method != http.MethodPost {
+		http.Error(w, "the logout endpoint only accepts POST requests", http.StatusMethodNotAllowed)
+		return
+	}
+	sessionID, ok := ctx.Value(webSessionIDKey{}).(int64)
+	if !ok {
+		http.Error(w, "no session was provided", http.StatusUnauthorized)
+		return
+	}
+	found, err := s.revokeSession(ctx, sessionID)
+	if err != nil {
+		log.Errorf(ctx, "revoking session %d: %v", sessionID, err)
+		http.Error(w, "an internal error occurred", http.StatusInternalServerError)
+		return
+	}
+	writeAPIV2JSONResponse(ctx, w, &apiV2LogoutResponse{LoggedOut: found})
+}
+
+// isJSONRequest returns true if the body of the request has the
+// application/json content type. A browser cannot send a cross-origin
+// request with this content type unless the origin allows it, so
+// requiring it protects the endpoints against cross-site request forgery.
+func isJSONRequest(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "application/json"
+}
+
+func writeAPIV2JSONResponse(ctx context.Context, w http.ResponseWriter, resp interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := gojson.NewEncoder(w).Encode(resp); err != nil {
+		log.Warningf(ctx, "error writing API response: %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIV2LoginLogout(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `CREATE USER apiuser WITH PASSWORD 'abc'`)
+
+	client, err := s.GetHTTPClient()
+	require.NoError(t, err)
+	post := func(path, session, body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, s.AdminURL()+path, bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if session != "" {
+			req.Header.Set(APISessionHeader, session)
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+	sqlAPIStatus := func(session string) int {
+		resp := post(sqlAPIPath, session, `{"statements": [{"sql": "SELECT 1"}]}`)
+		require.NoError(t, resp.Body.Close())
+		return resp.StatusCode
+	}
+
+	// Invalid credentials are rejected.
+	resp := post(apiV2LoginPath, "", `{"username": "apiuser", "password": "abd"}`)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = post(apiV2LoginPath, "", `{"username": "apiuser", "password": "abc"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var login apiV2LoginResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&login))
+	require.NoError(t, resp.Body.Close())
+	require.NotEmpty(t, login.Session)
+
+	require.Equal(t, http.StatusUnauthorized, sqlAPIStatus(""))
+	require.Equal(t, http.StatusOK, sqlAPIStatus(login.Session))
+
+	// The session cannot be used once revoked.
+	resp = post(apiV2LogoutPath, login.Session, ``)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var logout apiV2LogoutResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&logout))
+	require.NoError(t, resp.Body.Close())
+	require.True(t, logout.LoggedOut)
+	require.Equal(t, http.StatusUnauthorized, sqlAPIStatus(login.Session))
+}
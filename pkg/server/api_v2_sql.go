@@ -0,0 +1,380 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	gojson "encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// This file implements an HTTP endpoint which executes SQL statements
+// and returns their results as JSON. It is meant for the environments
+// where speaking pgwire is impractical, for example serverless
+// functions or browsers.
+//
+// A request is a POST of a JSON object of the following form:
+//
+//     {
+//       "database": "defaultdb",         // optional
+//       "application_name": "my_app",    // optional
+//       "timeout": "5s",                 // optional
+//       "max_result_size": 10000,        // optional, in bytes
+//       "statements": [
+//         {"sql": "SELECT $1::INT", "arguments": [1]},
+//         {"sql": "SELECT * FROM t ORDER BY k", "offset": 100},
+//         ...
+//       ]
+//     }
+//
+// The request must have the application/json content type, and the
+// session of the user must be passed in the APISessionHeader header;
+// see api_v2_auth.go for how to create one. The session cookie is not
+// accepted, so that a page of another origin cannot make the browser of
+// a logged in user execute statements.
+//
+// The statements are executed, in order, in a single transaction, as
+// the user authenticated by the session of the request. Each
+// entry must contain exactly one statement; its arguments are bound to
+// the placeholders $1, $2, etc.
+//
+// The response contains, for each statement, its tag, the number of
+// rows affected and, for the statements which return rows, the result
+// columns and rows. The rows of a result are truncated, and the result
+// is marked as such, once the total size of the rows of the response
+// exceeds max_result_size; the execution of the statement then stops.
+// If a statement fails, the transaction is rolled back, and the
+// response contains the error and the index of the statement which
+// failed.
+//
+// The timeout and max_result_size of a request are capped at
+// maxSQLAPITimeout and maxSQLAPIMaxResultSize.
+//
+// The results are paginated with the offset of the statements. The
+// first offset rows of the result of a statement are skipped. A
+// truncated result contains the offset of its next page, which is
+// fetched by sending the request again with that offset. The request is
+// executed again, in a new transaction, for every page. Pagination is
+// therefore meant for read-only statements whose results are ordered.
+
+// sqlAPIPath is the path of the SQL execution endpoint.
+const sqlAPIPath = "/api/v2/sql/"
+
+// defaultSQLAPIMaxResultSize is the default maximum size, in bytes, of
+// the rows returned by a request.
+const defaultSQLAPIMaxResultSize = 10000
+
+// maxSQLAPIMaxResultSize is the maximum size, in bytes, of the rows
+// returned by a request.
+const maxSQLAPIMaxResultSize = 10 << 20
+
+// defaultSQLAPITimeout is the default timeout of a request.
+const defaultSQLAPITimeout = 5 * time.Second
+
+// maxSQLAPITimeout is the maximum timeout of a request.
+const maxSQLAPITimeout = time.Minute
+
+type sqlAPIRequest struct {
+	Database        string              `json:"database,omitempty"`
+	ApplicationName string              `json:"application_name,omitempty"`
+	Timeout         string              `json:"timeout,omitempty"`
+	MaxResultSize   int                 `json:"max_result_size,omitempty"`
+	Statements      []sqlAPIRequestStmt `json:"statements"`
+}
+
+type sqlAPIRequestStmt struct {
+	SQL       string        `json:"sql"`
+	Arguments []interface{} `json:"arguments,omitempty"`
+	// Offset is the number of rows of the result to skip.
+	Offset int `json:"offset,omitempty"`
+}
+
+type sqlAPIResponse struct {
+	Results []sqlAPIResult `json:"results,omitempty"`
+	Error   *sqlAPIError   `json:"error,omitempty"`
+}
+
+type sqlAPIResult struct {
+	// Statement is the index of the statement in the request, starting
+	// at 1.
+	Statement    int                   `json:"statement"`
+	Tag          string                `json:"tag"`
+	RowsAffected int                   `json:"rows_affected"`
+	Columns      []sqlAPIColumn        `json:"columns,omitempty"`
+	Rows         [][]gojson.RawMessage `json:"rows,omitempty"`
+	// Truncated is set if some of the rows were not returned because of
+	// max_result_size.
+	Truncated bool `json:"truncated,omitempty"`
+	// NextOffset is the offset of the next page of a truncated result.
+	NextOffset int `json:"next_offset,omitempty"`
+}
+
+type sqlAPIColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Oid  uint32 `json:"oid"`
+}
+
+type sqlAPIError struct {
+	Message string `json:"message"`
+	Code    string `json:"code"`
+	// Statement is the index of the statement which failed, or 0 if the
+	// error is not specific to a statement.
+	Statement int `json:"statement,omitempty"`
+}
+
+// sqlAPIServer serves the SQL execution endpoint.
+type sqlAPIServer struct {
+	db *kv.DB
+	ie *sql.InternalExecutor
+	// insecure is set if the server runs in insecure mode, in which case
+	// the requests without a session are executed as root.
+	insecure bool
+}
+
+func newSQLAPIServer(db *kv.DB, ie *sql.InternalExecutor, insecure bool) *sqlAPIServer {
+	return &sqlAPIServer{db: db, ie: ie, insecure: insecure}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (s *sqlAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if r.Method != http.MethodPost {
+		http.Error(w, "the SQL API only accepts POST requests", http.StatusMethodNotAllowed)
+		return
+	}
+	if !isJSONRequest(r) {
+		writeSQLAPIError(ctx, w, http.StatusUnsupportedMediaType,
+			&sqlAPIError{Message: "the request must have the application/json content type"})
+		return
+	}
+
+	var req sqlAPIRequest
+	dec := gojson.NewDecoder(r.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&req); err != nil {
+		writeSQLAPIError(ctx, w, http.StatusBadRequest,
+			&sqlAPIError{Message: fmt.Sprintf("invalid request: %v", err)})
+		return
+	}
+	if len(req.Statements) == 0 {
+		writeSQLAPIError(ctx, w, http.StatusBadRequest,
+			&sqlAPIError{Message: "no statements specified"})
+		return
+	}
+	timeout := defaultSQLAPITimeout
+	if req.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(req.Timeout); err != nil || timeout <= 0 {
+			writeSQLAPIError(ctx, w, http.StatusBadRequest,
+				&sqlAPIError{Message: fmt.Sprintf("invalid timeout: %q", req.Timeout)})
+			return
+		}
+		if timeout > maxSQLAPITimeout {
+			timeout = maxSQLAPITimeout
+		}
+	}
+	maxResultSize := req.MaxResultSize
+	if maxResultSize <= 0 {
+		maxResultSize = defaultSQLAPIMaxResultSize
+	} else if maxResultSize > maxSQLAPIMaxResultSize {
+		maxResultSize = maxSQLAPIMaxResultSize
+	}
+
+	user, ok := s.sqlAPIUser(ctx)
+	if !ok {
+		writeSQLAPIError(ctx, w, http.StatusUnauthorized,
+			&sqlAPIError{Message: "the request is not authenticated"})
+		return
+	}
+
+	// Parse the statements and their arguments up front, so that the
+	// syntax errors are reported before anything is executed.
+	stmts := make([]parser.Statement, len(req.Statements))
+	args := make([][]interface{}, len(req.Statements))
+	for i, reqStmt := range req.Statements {
+		stmt, err := parser.ParseOne(reqStmt.SQL)
+		if err == nil {
+			args[i], err = sqlAPIArguments(reqStmt.Arguments)
+		}
+		if err == nil && reqStmt.Offset < 0 {
+			err = errors.Newf("invalid offset: %d", reqStmt.Offset)
+		}
+		if err != nil {
+			writeSQLAPIError(ctx, w, http.StatusBadRequest, makeSQLAPIError(err, i+1))
+			return
+		}
+		stmts[i] = stmt
+	}
+
+	override := sessiondata.InternalExecutorOverride{
+		User:            user,
+		Database:        req.Database,
+		ApplicationName: req.ApplicationName,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var resp sqlAPIResponse
+	var failedStmt int
+	err := s.db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		// The transaction may be retried, in which case the results of the
+		// previous attempt are discarded.
+		resp.Results = make([]sqlAPIResult, 0, len(stmts))
+		remaining := maxResultSize
+		for i, stmt := range stmts {
+			failedStmt = i + 1
+			res := sqlAPIResult{
+				Statement: i + 1,
+				Tag:       stmt.AST.StatementTag(),
+			}
+			if stmt.AST.StatementType() != tree.Rows {
+				n, err := s.ie.ExecEx(ctx, "sql-api", txn, override, stmt.SQL, args[i]...)
+				if err != nil {
+					return err
+				}
+				res.RowsAffected = n
+				resp.Results = append(resp.Results, res)
+				continue
+			}
+			// The rows are encoded as they are produced, and the execution
+			// stops once max_result_size is exceeded.
+			res.Rows = [][]gojson.RawMessage{}
+			offset := req.Statements[i].Offset
+			skipped := 0
+			cols, err := s.ie.QueryForEachRowEx(ctx, "sql-api", txn, override,
+				func(ctx context.Context, row tree.Datums) error {
+					if skipped < offset {
+						skipped++
+						return nil
+					}
+					if remaining <= 0 {
+						res.Truncated = true
+						res.NextOffset = offset + len(res.Rows)
+						return sql.ErrLimitedResultClosed
+					}
+					encoded := make([]gojson.RawMessage, len(row))
+					for j, d := range row {
+						jd, err := tree.AsJSON(d, time.UTC)
+						if err != nil {
+							return err
+						}
+						var buf bytes.Buffer
+						jd.Format(&buf)
+						encoded[j] = buf.Bytes()
+						remaining -= buf.Len()
+					}
+					res.Rows = append(res.Rows, encoded)
+					return nil
+				}, stmt.SQL, args[i]...)
+			if err != nil {
+				return err
+			}
+			res.RowsAffected = len(res.Rows)
+			res.Columns = make([]sqlAPIColumn, len(cols))
+			for j, col := range cols {
+				res.Columns[j] = sqlAPIColumn{Name: col.Name, Type: col.Typ.SQLString(), Oid: uint32(col.Typ.Oid())}
+			}
+			resp.Results = append(resp.Results, res)
+		}
+		failedStmt = 0
+		return nil
+	})
+	if err != nil {
+		// The results of the statements which succeeded are returned along
+		// with the error, even though their effects were rolled back.
+		resp.Error = makeSQLAPIError(err, failedStmt)
+		writeSQLAPIResponse(ctx, w, http.StatusBadRequest, &resp)
+		return
+	}
+	writeSQLAPIResponse(ctx, w, http.StatusOK, &resp)
+}
+
+// sqlAPIUser returns the user authenticated by the session of the
+// request. In insecure mode, the requests without a session are executed
+// as root, like the other API endpoints do in that case. Otherwise, it
+// returns false if the request has no session.
+func (s *sqlAPIServer) sqlAPIUser(ctx context.Context) (security.SQLUsername, bool) {
+	if u, ok := ctx.Value(webSessionUserKey{}).(string); ok {
+		// At this point the user is already logged in, so we can assume the
+		// username has been normalized already.
+		return security.MakeSQLUsernameFromPreNormalizedString(u), true
+	}
+	if s.insecure {
+		return security.RootUserName(), true
+	}
+	return security.SQLUsername{}, false
+}
+
+// sqlAPIArguments converts the arguments of a statement, as decoded
+// from JSON, into values that the internal executor accepts. Numbers
+// are converted to integers if possible and to floats otherwise; arrays
+// and objects are converted to JSONB values.
+func sqlAPIArguments(in []interface{}) ([]interface{}, error) {
+	out := make([]interface{}, len(in))
+	for i, arg := range in {
+		switch t := arg.(type) {
+		case nil, bool, string:
+			out[i] = t
+		case gojson.Number:
+			if n, err := t.Int64(); err == nil {
+				out[i] = n
+			} else if f, err := t.Float64(); err == nil {
+				out[i] = f
+			} else {
+				return nil, errors.Newf("invalid numeric argument %d: %s", i+1, t)
+			}
+		default:
+			j, err := json.MakeJSON(t)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid argument %d", i+1)
+			}
+			out[i] = tree.NewDJSON(j)
+		}
+	}
+	return out, nil
+}
+
+func makeSQLAPIError(err error, stmt int) *sqlAPIError {
+	return &sqlAPIError{
+		Message:   err.Error(),
+		Code:      pgerror.GetPGCode(err).String(),
+		Statement: stmt,
+	}
+}
+
+func writeSQLAPIError(ctx context.Context, w http.ResponseWriter, code int, err *sqlAPIError) {
+	writeSQLAPIResponse(ctx, w, code, &sqlAPIResponse{Error: err})
+}
+
+func writeSQLAPIResponse(
+	ctx context.Context, w http.ResponseWriter, code int, resp *sqlAPIResponse,
+) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := gojson.NewEncoder(w).Encode(resp); err != nil {
+		log.Warningf(ctx, "error writing SQL API response: %v", err)
+	}
+}
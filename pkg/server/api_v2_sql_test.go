@@ -0,0 +1,222 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLAPI(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `CREATE DATABASE api; CREATE TABLE api.kv (k INT PRIMARY KEY, v STRING)`)
+
+	// do sends a request to the SQL API. The client of the authenticated
+	// user also sends the session cookie, which is ignored.
+	do := func(isAdmin, withHeader bool, contentType, req string) *http.Response {
+		authUser := authenticatedUserName()
+		if !isAdmin {
+			authUser = authenticatedUserNameNoAdmin()
+		}
+		client, cookie, err := s.(*TestServer).getAuthenticatedHTTPClientAndCookie(authUser, isAdmin)
+		require.NoError(t, err)
+		httpReq, err := http.NewRequest(
+			http.MethodPost, s.AdminURL()+sqlAPIPath, bytes.NewBufferString(req))
+		require.NoError(t, err)
+		httpReq.Header.Set("Content-Type", contentType)
+		if withHeader {
+			encoded, err := EncodeSessionCookie(cookie, false /* forHTTPSOnly */)
+			require.NoError(t, err)
+			httpReq.Header.Set(APISessionHeader, encoded.Value)
+		}
+		httpResp, err := client.Do(httpReq)
+		require.NoError(t, err)
+		return httpResp
+	}
+	post := func(isAdmin bool, req string) (int, sqlAPIResponse) {
+		httpResp := do(isAdmin, true /* withHeader */, "application/json", req)
+		defer httpResp.Body.Close()
+		var resp sqlAPIResponse
+		require.NoError(t, json.NewDecoder(httpResp.Body).Decode(&resp))
+		return httpResp.StatusCode, resp
+	}
+
+	t.Run("statements", func(t *testing.T) {
+		code, resp := post(true, `{
+			"database": "api",
+			"statements": [
+				{"sql": "INSERT INTO kv VALUES ($1, $2), ($3, $4)", "arguments": [1, "a", 2, null]},
+				{"sql": "SELECT k, v FROM kv WHERE k >= $1 ORDER BY k", "arguments": [1]}
+			]
+		}`)
+		require.Equal(t, http.StatusOK, code, "%+v", resp.Error)
+		require.Nil(t, resp.Error)
+		require.Len(t, resp.Results, 2)
+
+		require.Equal(t, "INSERT", resp.Results[0].Tag)
+		require.Equal(t, 2, resp.Results[0].RowsAffected)
+
+		sel := resp.Results[1]
+		require.Equal(t, "SELECT", sel.Tag)
+		require.Equal(t, []sqlAPIColumn{
+			{Name: "k", Type: "INT8", Oid: 20},
+			{Name: "v", Type: "STRING", Oid: 25},
+		}, sel.Columns)
+		require.Len(t, sel.Rows, 2)
+		require.Equal(t, `1`, string(sel.Rows[0][0]))
+		require.Equal(t, `"a"`, string(sel.Rows[0][1]))
+		require.Equal(t, `null`, string(sel.Rows[1][1]))
+		require.False(t, sel.Truncated)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		code, resp := post(true, `{
+			"database": "api",
+			"max_result_size": 1,
+			"statements": [{"sql": "SELECT k FROM kv ORDER BY k"}]
+		}`)
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, resp.Results, 1)
+		require.Len(t, resp.Results[0].Rows, 1)
+		require.True(t, resp.Results[0].Truncated)
+
+		// The execution of a statement stops once the rows exceed the
+		// maximum size, before the timeout.
+		code, resp = post(true, `{
+			"max_result_size": 100,
+			"statements": [{"sql": "SELECT generate_series(1, 1 << 62)"}]
+		}`)
+		require.Equal(t, http.StatusOK, code, "%+v", resp.Error)
+		require.Len(t, resp.Results, 1)
+		require.NotEmpty(t, resp.Results[0].Rows)
+		require.True(t, resp.Results[0].Truncated)
+	})
+
+	t.Run("pagination", func(t *testing.T) {
+		page := func(offset int) sqlAPIResult {
+			code, resp := post(true, fmt.Sprintf(`{
+				"database": "api",
+				"max_result_size": 1,
+				"statements": [{"sql": "SELECT k FROM kv ORDER BY k", "offset": %d}]
+			}`, offset))
+			require.Equal(t, http.StatusOK, code, "%+v", resp.Error)
+			require.Len(t, resp.Results, 1)
+			return resp.Results[0]
+		}
+		res := page(0)
+		require.Len(t, res.Rows, 1)
+		require.Equal(t, `1`, string(res.Rows[0][0]))
+		require.True(t, res.Truncated)
+		require.Equal(t, 1, res.NextOffset)
+
+		res = page(res.NextOffset)
+		require.Len(t, res.Rows, 1)
+		require.Equal(t, `2`, string(res.Rows[0][0]))
+		require.False(t, res.Truncated)
+		require.Zero(t, res.NextOffset)
+
+		code, resp := post(true, `{"statements": [{"sql": "SELECT 1", "offset": -1}]}`)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.NotNil(t, resp.Error)
+		require.Equal(t, 1, resp.Error.Statement)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		code, resp := post(true, `{
+			"database": "api",
+			"statements": [
+				{"sql": "INSERT INTO kv VALUES (3, 'c')"},
+				{"sql": "INSERT INTO kv VALUES (1, 'a')"}
+			]
+		}`)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.NotNil(t, resp.Error)
+		require.Equal(t, 2, resp.Error.Statement)
+		require.Equal(t, "23505", resp.Error.Code)
+		// The statements are executed in a single transaction, so the first
+		// insert was rolled back.
+		sqlDB.CheckQueryResults(t, `SELECT count(*) FROM api.kv WHERE k = 3`, [][]string{{"0"}})
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		code, resp := post(true, `{"statements": [{"sql": "SELECT 1"}, {"sql": "SELEC 2"}]}`)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.NotNil(t, resp.Error)
+		require.Equal(t, 2, resp.Error.Statement)
+		require.Equal(t, "42601", resp.Error.Code)
+		require.Empty(t, resp.Results)
+	})
+
+	t.Run("forgery", func(t *testing.T) {
+		// The session cookie alone does not authenticate the request.
+		httpResp := do(true /* isAdmin */, false /* withHeader */, "application/json",
+			`{"statements": [{"sql": "SELECT 1"}]}`)
+		require.NoError(t, httpResp.Body.Close())
+		require.Equal(t, http.StatusUnauthorized, httpResp.StatusCode)
+		// The content types which a form can send are rejected.
+		httpResp = do(true /* isAdmin */, true /* withHeader */, "text/plain",
+			`{"statements": [{"sql": "SELECT 1"}]}`)
+		require.NoError(t, httpResp.Body.Close())
+		require.Equal(t, http.StatusUnsupportedMediaType, httpResp.StatusCode)
+	})
+
+	t.Run("privileges", func(t *testing.T) {
+		// The statements are executed as the user of the web session.
+		code, resp := post(false, `{"statements": [{"sql": "SELECT * FROM api.kv"}]}`)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.NotNil(t, resp.Error)
+		require.Equal(t, "42501", resp.Error.Code)
+	})
+}
+
+func TestSQLAPIWithoutSession(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	for _, insecure := range []bool{false, true} {
+		t.Run(fmt.Sprintf("insecure=%t", insecure), func(t *testing.T) {
+			// Without web sessions, the requests are only executed as root in
+			// insecure mode.
+			s, _, _ := serverutils.StartServer(t, base.TestServerArgs{
+				Insecure:                        insecure,
+				DisableWebSessionAuthentication: true,
+			})
+			defer s.Stopper().Stop(context.Background())
+			client, err := s.GetHTTPClient()
+			require.NoError(t, err)
+			httpResp, err := client.Post(s.AdminURL()+sqlAPIPath, "application/json",
+				bytes.NewBufferString(`{"statements": [{"sql": "SELECT 1"}]}`))
+			require.NoError(t, err)
+			require.NoError(t, httpResp.Body.Close())
+			if insecure {
+				require.Equal(t, http.StatusOK, httpResp.StatusCode)
+			} else {
+				require.Equal(t, http.StatusUnauthorized, httpResp.StatusCode)
+			}
+		})
+	}
+}
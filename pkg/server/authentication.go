@@ -27,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
@@ -53,6 +54,12 @@ const (
 	secretLength = 16
 	// SessionCookieName is the name of the cookie used for HTTP auth.
 	SessionCookieName = "session"
+	// APISessionHeader is the name of the header which carries the session
+	// of the requests to the API endpoints which do not accept the session
+	// cookie. A browser only sends this header if a script of the origin
+	// which logged in sets it, which protects these endpoints against
+	// cross-site request forgery.
+	APISessionHeader = "X-Cockroach-API-Session"
 
 	// DemoLoginPath is the demo shell auto-login URL.
 	DemoLoginPath = "/demologin"
@@ -147,6 +154,9 @@ func (s *authenticationServer) UserLogin(
 	// Verify the provided username/password pair.
 	verified, expired, err := s.verifyPassword(ctx, username, req.Password)
 	if err != nil {
+		if pgwire.IsLockedOutError(err) {
+			return nil, status.Errorf(codes.Unauthenticated, "%v", err)
+		}
 		return nil, apiInternalError(ctx, err)
 	}
 	if expired {
@@ -312,16 +322,9 @@ func (s *authenticationServer) UserLogout(
 	}
 
 	// Revoke the session.
-	if n, err := s.server.sqlServer.internalExecutor.ExecEx(
-		ctx,
-		"revoke-auth-session",
-		nil, /* txn */
-		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
-		`UPDATE system.web_sessions SET "revokedAt" = now() WHERE id = $1`,
-		sessionID,
-	); err != nil {
+	if found, err := s.revokeSession(ctx, int64(sessionID)); err != nil {
 		return nil, apiInternalError(ctx, err)
-	} else if n == 0 {
+	} else if !found {
 		err := status.Errorf(
 			codes.InvalidArgument,
 			"session with id %d nonexistent", sessionID)
@@ -342,6 +345,20 @@ func (s *authenticationServer) UserLogout(
 	return &serverpb.UserLogoutResponse{}, nil
 }
 
+// revokeSession revokes the session with the given ID. It returns false if
+// the session does not exist.
+func (s *authenticationServer) revokeSession(ctx context.Context, sessionID int64) (bool, error) {
+	n, err := s.server.sqlServer.internalExecutor.ExecEx(
+		ctx,
+		"revoke-auth-session",
+		nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		`UPDATE system.web_sessions SET "revokedAt" = now() WHERE id = $1`,
+		sessionID,
+	)
+	return n > 0, err
+}
+
 // verifySession verifies the existence and validity of the session claimed by
 // the supplied SessionCookie. Returns three parameters: a boolean indicating if
 // the session was valid, the username associated with the session (if
@@ -410,6 +427,10 @@ WHERE id = $1`
 //
 // The caller is responsible for ensuring that the username is normalized.
 // (CockroachDB has case-insensitive usernames, unlike PostgreSQL.)
+//
+// The attempts are counted by the node's login lockout, which is shared with
+// SQL logins. If the user is locked out, an error recognized by
+// pgwire.IsLockedOutError is returned.
 func (s *authenticationServer) verifyPassword(
 	ctx context.Context, username security.SQLUsername, password string,
 ) (valid bool, expired bool, err error) {
@@ -422,6 +443,10 @@ func (s *authenticationServer) verifyPassword(
 	if !exists || !canLogin {
 		return false, false, nil
 	}
+	lockout := s.server.sqlServer.pgServer.LoginLockout()
+	if err := lockout.Check(username, timeutil.Now()); err != nil {
+		return false, false, err
+	}
 	hashedPassword, err := pwRetrieveFn(ctx)
 	if err != nil {
 		return false, false, err
@@ -437,7 +462,9 @@ func (s *authenticationServer) verifyPassword(
 		}
 	}
 
-	return security.CompareHashAndPassword(hashedPassword, password) == nil, false, nil
+	valid = security.CompareHashAndPassword(hashedPassword, password) == nil
+	lockout.ReportAttempt(username, valid, timeutil.Now())
+	return valid, false, nil
 }
 
 // CreateAuthSecret creates a secret, hash pair to populate a session auth token.
@@ -514,6 +541,11 @@ type authenticationMux struct {
 	// If allowAnonymous is false, the mux returns an error if there is no
 	// valid session.
 	allowAnonymous bool
+
+	// useSessionHeader, if true, indicates that the session is read from
+	// the APISessionHeader header of the requests instead of the session
+	// cookie.
+	useSessionHeader bool
 }
 
 func newAuthenticationMuxAllowAnonymous(
@@ -534,6 +566,17 @@ func newAuthenticationMux(s *authenticationServer, inner http.Handler) *authenti
 	}
 }
 
+// newAPIAuthenticationMux returns an authenticationMux which reads the
+// session from the APISessionHeader header of the requests.
+func newAPIAuthenticationMux(s *authenticationServer, inner http.Handler) *authenticationMux {
+	return &authenticationMux{
+		server:           s,
+		inner:            inner,
+		allowAnonymous:   false,
+		useSessionHeader: true,
+	}
+}
+
 type webSessionUserKey struct{}
 type webSessionIDKey struct{}
 
@@ -551,7 +594,11 @@ func (am *authenticationMux) ServeHTTP(w http.ResponseWriter, req *http.Request)
 		if log.V(1) {
 			log.Infof(req.Context(), "web session error: %v", err)
 		}
-		http.Error(w, "a valid authentication cookie is required", http.StatusUnauthorized)
+		msg := "a valid authentication cookie is required"
+		if am.useSessionHeader {
+			msg = fmt.Sprintf("a valid %s header is required", APISessionHeader)
+		}
+		http.Error(w, msg, http.StatusUnauthorized)
 		return
 	}
 	am.inner.ServeHTTP(w, req)
@@ -590,9 +637,18 @@ func (am *authenticationMux) getSession(
 	w http.ResponseWriter, req *http.Request,
 ) (string, *serverpb.SessionCookie, error) {
 	// Validate the returned cookie.
-	rawCookie, err := req.Cookie(SessionCookieName)
-	if err != nil {
-		return "", nil, err
+	var rawCookie *http.Cookie
+	if am.useSessionHeader {
+		value := req.Header.Get(APISessionHeader)
+		if value == "" {
+			return "", nil, errors.Newf("no %s header was provided", APISessionHeader)
+		}
+		rawCookie = &http.Cookie{Name: SessionCookieName, Value: value}
+	} else {
+		var err error
+		if rawCookie, err = req.Cookie(SessionCookieName); err != nil {
+			return "", nil, err
+		}
 	}
 
 	cookie, err := decodeSessionCookie(rawCookie)
@@ -34,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/debug"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/ts"
@@ -294,6 +295,43 @@ func TestVerifyPassword(t *testing.T) {
 	}
 }
 
+// TestVerifyPasswordLockout verifies that the HTTP logins lock users out after
+// too many failed attempts, and share the lockout with the SQL logins.
+func TestVerifyPasswordLockout(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	ts := s.(*TestServer)
+
+	for _, stmt := range []string{
+		`CREATE USER azure_diamond WITH PASSWORD 'hunter2'`,
+		`SET CLUSTER SETTING server.user_login.lockout.max_failed_attempts = 2`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	username := security.MakeSQLUsernameFromPreNormalizedString("azure_diamond")
+
+	for i := 0; i < 2; i++ {
+		valid, _, err := ts.authentication.verifyPassword(ctx, username, "hunter")
+		if err != nil || valid {
+			t.Fatalf("expected the password to be rejected, got valid = %t, err = %v", valid, err)
+		}
+	}
+	// The user is locked out, even with the right password.
+	if _, _, err := ts.authentication.verifyPassword(ctx, username, "hunter2"); !pgwire.IsLockedOutError(err) {
+		t.Fatalf("expected the user to be locked out, got %v", err)
+	}
+	// The lockout also applies to the SQL logins.
+	if err := ts.sqlServer.pgServer.LoginLockout().Check(username, timeutil.Now()); !pgwire.IsLockedOutError(err) {
+		t.Fatalf("expected the user to be locked out of SQL logins, got %v", err)
+	}
+}
+
 func TestCreateSession(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -15,6 +15,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"runtime"
 	"sort"
 	"time"
 
@@ -39,6 +40,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/bootstrap"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -170,6 +172,11 @@ type Node struct {
 	additionalStoreInitCh chan struct{}
 
 	perReplicaServer kvserver.Server
+
+	// cpuUtilization, if set, returns the CPU utilization of the process,
+	// normalized between 0 and 1 by the number of CPUs. It is used to adjust
+	// the number of KV admission slots.
+	cpuUtilization func() float64
 }
 
 var _ roachpb.InternalServer = &Node{}
@@ -304,6 +311,9 @@ func NewNode(
 		sqlExec:    sqlExec,
 		clusterID:  clusterID,
 	}
+	if cfg.KVAdmissionQ != nil {
+		reg.AddMetricStruct(cfg.KVAdmissionQ.Metrics())
+	}
 	n.perReplicaServer = kvserver.MakeServer(&n.Descriptor, n.stores)
 	return n
 }
@@ -448,6 +458,9 @@ func (n *Node) start(
 	}
 
 	n.startComputePeriodicMetrics(n.stopper, base.DefaultMetricsSampleInterval)
+	if n.storeCfg.KVAdmissionQ != nil {
+		n.startAdjustAdmissionSlots(n.stopper, admissionSlotsAdjustInterval)
+	}
 
 	// Be careful about moving this line above where we start stores; store
 	// migrations rely on the fact that the cluster version has not been updated
@@ -668,6 +681,54 @@ func (n *Node) startComputePeriodicMetrics(stopper *stop.Stopper, interval time.
 	})
 }
 
+// admissionSlotsAdjustInterval is the interval at which the number of KV
+// admission slots is recomputed from the CPU utilization of the node and the
+// state of its stores.
+const admissionSlotsAdjustInterval = time.Second
+
+// startAdjustAdmissionSlots starts a loop which periodically adjusts the
+// number of batches admitted concurrently on this node, reducing it while the
+// CPUs of the node are close to saturation or any store has too many L0
+// sub-levels or files.
+func (n *Node) startAdjustAdmissionSlots(stopper *stop.Stopper, interval time.Duration) {
+	ctx := n.AnnotateCtx(context.Background())
+	_ = stopper.RunAsyncTask(ctx, "adjust-admission-slots", func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				var maxL0SubLevels, maxL0FileCount int64
+				if err := n.stores.VisitStores(func(store *kvserver.Store) error {
+					m, err := store.Engine().GetMetrics()
+					if err != nil {
+						return err
+					}
+					if m.L0SublevelCount > maxL0SubLevels {
+						maxL0SubLevels = m.L0SublevelCount
+					}
+					if m.L0FileCount > maxL0FileCount {
+						maxL0FileCount = m.L0FileCount
+					}
+					return nil
+				}); err != nil {
+					log.Warningf(ctx, "unable to read engine metrics: %s", err)
+					continue
+				}
+				var cpuUtilization float64
+				if n.cpuUtilization != nil {
+					cpuUtilization = n.cpuUtilization()
+				}
+				n.storeCfg.KVAdmissionQ.SetTotalSlots(admission.ComputeKVSlots(
+					&n.storeCfg.Settings.SV, runtime.GOMAXPROCS(0), cpuUtilization, maxL0SubLevels, maxL0FileCount,
+				))
+			case <-stopper.ShouldQuiesce():
+				return
+			}
+		}
+	})
+}
+
 // computePeriodicMetrics instructs each store to compute the value of
 // complicated metrics.
 func (n *Node) computePeriodicMetrics(ctx context.Context, tick int) error {
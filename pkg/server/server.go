@@ -61,6 +61,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/contention"
 	_ "github.com/cockroachdb/cockroach/pkg/sql/gcjob" // register jobs declared outside of pkg/sql
 	"github.com/cockroachdb/cockroach/pkg/sql/optionalnodeliveness"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
@@ -71,6 +72,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/ts"
 	"github.com/cockroachdb/cockroach/pkg/ui"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/httputil"
@@ -546,6 +548,11 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		ExternalStorage:         externalStorage,
 		ExternalStorageFromURI:  externalStorageFromURI,
 		ProtectedTimestampCache: protectedtsProvider,
+		KVAdmissionQ: admission.NewWorkQueue(
+			st, admission.ComputeKVSlots(
+				&st.SV, runtime.GOMAXPROCS(0), 0, /* cpuUtilization */
+				0 /* l0SubLevels */, 0 /* l0FileCount */),
+		),
 	}
 	if storeTestingKnobs := cfg.TestingKnobs.Store; storeTestingKnobs != nil {
 		storeCfg.TestingKnobs = *storeTestingKnobs.(*kvserver.StoreTestingKnobs)
@@ -570,6 +577,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	node := NewNode(
 		storeCfg, recorder, registry, stopper,
 		txnMetrics, nil /* execCfg */, &rpcContext.ClusterID)
+	node.cpuUtilization = runtimeSampler.CPUCombinedPercentNorm.Value
 	lateBoundNode = node
 	roachpb.RegisterInternalServer(grpcServer.Server, node)
 	kvserver.RegisterPerReplicaServer(grpcServer.Server, node.perReplicaServer)
@@ -594,6 +602,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	// TODO(tbg): give adminServer only what it needs (and avoid circular deps).
 	sAdmin := newAdminServer(lateBoundServer, internalExecutor)
 	sessionRegistry := sql.NewSessionRegistry()
+	contentionRegistry := contention.NewRegistry()
 
 	sStatus := newStatusServer(
 		cfg.AmbientCtx,
@@ -609,6 +618,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		node.stores,
 		stopper,
 		sessionRegistry,
+		contentionRegistry,
 		internalExecutor,
 	)
 	// TODO(tbg): don't pass all of Server into this to avoid this hack.
@@ -653,6 +663,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		registry:                 registry,
 		recorder:                 recorder,
 		sessionRegistry:          sessionRegistry,
+		contentionRegistry:       contentionRegistry,
 		circularInternalExecutor: internalExecutor,
 		circularJobRegistry:      jobRegistry,
 		jobAdoptionStopFile:      jobAdoptionStopFile,
@@ -1724,6 +1735,18 @@ func (s *Server) PreStart(ctx context.Context) error {
 	s.mux.Handle(loginPath, gwMux)
 	s.mux.Handle(logoutPath, authHandler)
 
+	// Register the SQL execution endpoint.
+	var sqlAPIHandler http.Handler = newSQLAPIServer(
+		s.db, s.sqlServer.internalExecutor, s.cfg.Insecure)
+	if s.cfg.RequireWebSession() {
+		sqlAPIHandler = newAPIAuthenticationMux(s.authentication, sqlAPIHandler)
+		// Register the endpoints which manage the sessions of the API.
+		s.mux.Handle(apiV2LoginPath, http.HandlerFunc(s.authentication.apiV2Login))
+		s.mux.Handle(apiV2LogoutPath, newAPIAuthenticationMux(
+			s.authentication, http.HandlerFunc(s.authentication.apiV2Logout)))
+	}
+	s.mux.Handle(sqlAPIPath, sqlAPIHandler)
+
 	if s.cfg.EnableDemoLoginEndpoint {
 		s.mux.Handle(DemoLoginPath, http.HandlerFunc(s.authentication.demoLogin))
 	}
@@ -52,6 +52,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/contention"
 	"github.com/cockroachdb/cockroach/pkg/sql/roleoption"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
@@ -129,9 +130,10 @@ func propagateGatewayMetadata(ctx context.Context) context.Context {
 // and the full statusServer.
 type baseStatusServer struct {
 	log.AmbientContext
-	privilegeChecker *adminPrivilegeChecker
-	sessionRegistry  *sql.SessionRegistry
-	st               *cluster.Settings
+	privilegeChecker   *adminPrivilegeChecker
+	sessionRegistry    *sql.SessionRegistry
+	contentionRegistry *contention.Registry
+	st                 *cluster.Settings
 }
 
 // getLocalSessions returns a list of local sessions on this node. Note that the
@@ -190,6 +192,34 @@ func (b *baseStatusServer) getLocalSessions(
 	return userSessions, nil
 }
 
+// getLocalContentionEvents returns the contention events observed by this
+// node. Note that the NodeID field is unset.
+func (b *baseStatusServer) getLocalContentionEvents(
+	ctx context.Context,
+) ([]serverpb.ContentionEventsEntry, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = b.AnnotateCtx(ctx)
+
+	if _, err := b.privilegeChecker.requireAdminUser(ctx); err != nil {
+		return nil, err
+	}
+
+	entries := b.contentionRegistry.Entries()
+	events := make([]serverpb.ContentionEventsEntry, len(entries))
+	for i, e := range entries {
+		events[i] = serverpb.ContentionEventsEntry{
+			TableID:                  uint32(e.TableID),
+			IndexID:                  uint32(e.IndexID),
+			NumContentionEvents:      e.NumContentionEvents,
+			CumulativeContentionTime: e.CumulativeContentionTime,
+			Key:                      e.Key,
+			TxnID:                    e.TxnID,
+			Count:                    int64(e.Count),
+		}
+	}
+	return events, nil
+}
+
 type sessionFinder func(sessions []serverpb.Session) (serverpb.Session, error)
 
 func findSessionBySessionID(sessionID []byte) sessionFinder {
@@ -333,15 +363,17 @@ func newStatusServer(
 	stores *kvserver.Stores,
 	stopper *stop.Stopper,
 	sessionRegistry *sql.SessionRegistry,
+	contentionRegistry *contention.Registry,
 	internalExecutor *sql.InternalExecutor,
 ) *statusServer {
 	ambient.AddLogTag("status", nil)
 	server := &statusServer{
 		baseStatusServer: &baseStatusServer{
-			AmbientContext:   ambient,
-			privilegeChecker: adminServer.adminPrivilegeChecker,
-			sessionRegistry:  sessionRegistry,
-			st:               st,
+			AmbientContext:     ambient,
+			privilegeChecker:   adminServer.adminPrivilegeChecker,
+			sessionRegistry:    sessionRegistry,
+			contentionRegistry: contentionRegistry,
+			st:                 st,
 		},
 		cfg:              cfg,
 		admin:            adminServer,
@@ -1578,7 +1610,9 @@ func (s *statusServer) Ranges(
 				WritesPerSecond:  rep.WritesPerSecond(),
 			},
 			Problems: serverpb.RangeProblems{
-				Unavailable:            metrics.Unavailable,
+				// A replica whose circuit breaker is tripped is unable to
+				// replicate commands, even if a quorum of its range is live.
+				Unavailable:            metrics.Unavailable || metrics.CircuitBreakerTripped,
 				LeaderNotLeaseHolder:   metrics.Leader && metrics.LeaseValid && !metrics.Leaseholder,
 				NoRaftLeader:           !kvserver.HasRaftLeader(raftStatus) && !metrics.Quiescent,
 				Underreplicated:        metrics.Underreplicated,
@@ -1930,6 +1964,62 @@ func (s *statusServer) ListSessions(
 	return response, nil
 }
 
+// ListLocalContentionEvents returns the contention events observed by this
+// node.
+func (s *statusServer) ListLocalContentionEvents(
+	ctx context.Context, _ *serverpb.ListContentionEventsRequest,
+) (*serverpb.ListContentionEventsResponse, error) {
+	events, err := s.getLocalContentionEvents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range events {
+		events[i].NodeID = s.gossip.NodeID.Get()
+	}
+	return &serverpb.ListContentionEventsResponse{Events: events}, nil
+}
+
+// ListContentionEvents returns the contention events observed by all nodes in
+// the cluster.
+func (s *statusServer) ListContentionEvents(
+	ctx context.Context, req *serverpb.ListContentionEventsRequest,
+) (*serverpb.ListContentionEventsResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+
+	if _, err := s.privilegeChecker.requireAdminUser(ctx); err != nil {
+		return nil, err
+	}
+
+	response := &serverpb.ListContentionEventsResponse{
+		Events: make([]serverpb.ContentionEventsEntry, 0),
+		Errors: make([]serverpb.ListSessionsError, 0),
+	}
+
+	dialFn := func(ctx context.Context, nodeID roachpb.NodeID) (interface{}, error) {
+		client, err := s.dialNode(ctx, nodeID)
+		return client, err
+	}
+	nodeFn := func(ctx context.Context, client interface{}, _ roachpb.NodeID) (interface{}, error) {
+		status := client.(serverpb.StatusClient)
+		return status.ListLocalContentionEvents(ctx, req)
+	}
+	responseFn := func(_ roachpb.NodeID, nodeResp interface{}) {
+		events := nodeResp.(*serverpb.ListContentionEventsResponse)
+		response.Events = append(response.Events, events.Events...)
+	}
+	errorFn := func(nodeID roachpb.NodeID, err error) {
+		errResponse := serverpb.ListSessionsError{NodeID: nodeID, Message: err.Error()}
+		response.Errors = append(response.Errors, errResponse)
+	}
+
+	if err := s.iterateNodes(ctx, "contention events list", dialFn, nodeFn, responseFn, errorFn); err != nil {
+		err := serverpb.ListSessionsError{Message: err.Error()}
+		response.Errors = append(response.Errors, err)
+	}
+	return response, nil
+}
+
 // CancelSession responds to a session cancellation request by canceling the
 // target session's associated context.
 func (s *statusServer) CancelSession(
@@ -70,10 +70,6 @@ func (n *alterTableSetLocalityNode) Next(runParams) (bool, error) { return false
 func (n *alterTableSetLocalityNode) Values() tree.Datums          { return tree.Datums{} }
 func (n *alterTableSetLocalityNode) Close(context.Context)        {}
 
-func (n *alterTableSetLocalityNode) alterTableLocalityGlobalToRegionalByTable() error {
-	return unimplemented.New("alter table locality from GLOBAL to REGIONAL BY TABLE", "implementation pending")
-}
-
 func (n *alterTableSetLocalityNode) alterTableLocalityRegionalByTableToGlobal(
 	params runParams, desc *dbdesc.Immutable,
 ) error {
@@ -143,14 +139,17 @@ func (n *alterTableSetLocalityNode) alterTableLocalityRegionalByTableToGlobal(
 	return nil
 }
 
-func (n *alterTableSetLocalityNode) alterTableLocalityRegionalByTableToRegionalByTable(
+// alterTableLocalityToRegionalByTable alters a GLOBAL or REGIONAL BY TABLE
+// table to REGIONAL BY TABLE. Neither locality affects the layout of the
+// table's data, so only the locality and zone configuration are updated.
+func (n *alterTableSetLocalityNode) alterTableLocalityToRegionalByTable(
 	params runParams, desc *dbdesc.Immutable,
 ) error {
-	const operation string = "alter table locality REGIONAL BY TABLE to REGIONAL BY TABLE"
+	const operation string = "alter table locality to REGIONAL BY TABLE"
 	if err := assertIsMultiRegionDatabase(desc, operation); err != nil {
 		return err
 	}
-	if !n.tableDesc.IsLocalityRegionalByTable() {
+	if !n.tableDesc.IsLocalityRegionalByTable() && !n.tableDesc.IsLocalityGlobal() {
 		return errors.AssertionFailedf(
 			"invalid call %q on incorrect table locality. %v",
 			operation,
@@ -278,7 +277,8 @@ func (n *alterTableSetLocalityNode) startExec(params runParams) error {
 			// GLOBAL to REGIONAL BY ROW
 			return unimplemented.New("alter table locality to REGIONAL BY ROW", "implementation pending")
 		case tree.LocalityLevelTable:
-			if err = n.alterTableLocalityGlobalToRegionalByTable(); err != nil {
+			err = n.alterTableLocalityToRegionalByTable(params, desc)
+			if err != nil {
 				return err
 			}
 		default:
@@ -294,7 +294,7 @@ func (n *alterTableSetLocalityNode) startExec(params runParams) error {
 		case tree.LocalityLevelRow:
 			return unimplemented.New("alter table locality to REGIONAL BY ROW", "implementation pending")
 		case tree.LocalityLevelTable:
-			err = n.alterTableLocalityRegionalByTableToRegionalByTable(params, desc)
+			err = n.alterTableLocalityToRegionalByTable(params, desc)
 			if err != nil {
 				return err
 			}
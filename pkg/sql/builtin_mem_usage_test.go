@@ -13,9 +13,12 @@ package sql
 import (
 	"context"
 	gosql "database/sql"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
@@ -120,3 +123,73 @@ func TestEvaluatedMemoryIsChecked(t *testing.T) {
 		})
 	}
 }
+
+// TestQueryAndSessionMemoryLimits verifies that the sql.mem.query.max and
+// sql.mem.session.max settings stop queries that use more memory than
+// allowed, and that the session limit only applies to new sessions.
+func TestQueryAndSessionMemoryLimits(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, sqlDB, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	if err := createTableWithLongStrings(sqlDB); err != nil {
+		t.Fatal(err)
+	}
+
+	const statement = `SELECT length(concat_agg(a)) FROM d.t`
+	// expectOOM checks that the error names the monitor of the operator that
+	// requested the memory and the monitor whose limit was exceeded, once.
+	expectOOM := func(t *testing.T, err error, monitor string) {
+		t.Helper()
+		pqErr := (*pq.Error)(nil)
+		if !errors.As(err, &pqErr) || pgcode.MakeCode(string(pqErr.Code)) != pgcode.OutOfMemory {
+			t.Fatalf("expected \"%s\" to exceed the memory limit, got %v", statement, err)
+		}
+		re := regexp.MustCompile(`([\w-]+): ` + monitor + `: memory budget exceeded`)
+		if m := re.FindStringSubmatch(pqErr.Message); m == nil || m[1] == monitor ||
+			strings.Count(pqErr.Message, monitor+":") != 1 {
+			t.Fatalf("expected the error to name the operator and %q, got %q", monitor, pqErr.Message)
+		}
+	}
+	sv := &s.ClusterSettings().SV
+
+	t.Run("query", func(t *testing.T) {
+		execinfra.SettingQueryMemBytes.Override(sv, lowMemoryBudget)
+		_, err := sqlDB.Exec(statement)
+		expectOOM(t, err, "flow")
+
+		execinfra.SettingQueryMemBytes.Override(sv, 0)
+		if _, err := sqlDB.Exec(statement); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("session", func(t *testing.T) {
+		// Open a session before the limit is set. It is not affected by the
+		// limit, since the limit is read when a session starts.
+		oldConn, err := sqlDB.Conn(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer oldConn.Close()
+		if _, err := oldConn.ExecContext(ctx, `SELECT 1`); err != nil {
+			t.Fatal(err)
+		}
+
+		sessionMemLimit.Override(sv, lowMemoryBudget)
+		defer sessionMemLimit.Override(sv, 0)
+
+		newDB := serverutils.OpenDBConn(t, s.ServingSQLAddr(), "" /* useDatabase */, false /* insecure */, s.Stopper())
+		// The flow's monitor is a descendant of the session's monitor, so the
+		// memory used by the query counts towards the session limit.
+		_, err = newDB.Exec(statement)
+		expectOOM(t, err, "session root")
+
+		if _, err := oldConn.ExecContext(ctx, statement); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -76,6 +76,10 @@ const (
 	CrdbInternalZonesTableID
 	CrdbInternalInvalidDescriptorsTableID
 	CrdbInternalClusterDatabasePrivilegesTableID
+	CrdbInternalNodeContentionEventsTableID
+	CrdbInternalHotRangesTableID
+	CrdbInternalClusterStmtStatsTableID
+	CrdbInternalClusterContentionEventsTableID
 	InformationSchemaID
 	InformationSchemaAdministrableRoleAuthorizationsID
 	InformationSchemaApplicableRolesID
@@ -345,6 +345,17 @@ CREATE TABLE system.sqlliveness (
     expiration       DECIMAL NOT NULL,
   	FAMILY fam0_session_id_expiration (session_id, expiration)
 )`
+
+	// NotificationsTableSchema is the schema of the table through which the
+	// notifications generated by NOTIFY are delivered to all the nodes.
+	NotificationsTableSchema = `
+CREATE TABLE system.notifications (
+    id      INT8 DEFAULT unique_rowid() PRIMARY KEY NOT NULL,
+    created TIMESTAMPTZ NOT NULL DEFAULT now(),
+    channel STRING NOT NULL,
+    payload STRING NOT NULL,
+    FAMILY "primary" (id, created, channel, payload)
+)`
 )
 
 func pk(name string) descpb.IndexDescriptor {
@@ -1690,6 +1701,36 @@ var (
 		FormatVersion:  descpb.InterleavedFormatVersion,
 		NextMutationID: 1,
 	})
+
+	// NotificationsTable is the descriptor for the notifications table.
+	NotificationsTable = tabledesc.NewImmutable(descpb.TableDescriptor{
+		Name:                    "notifications",
+		ID:                      keys.NotificationsTableID,
+		ParentID:                keys.SystemDatabaseID,
+		UnexposedParentSchemaID: keys.PublicSchemaID,
+		Version:                 1,
+		Columns: []descpb.ColumnDescriptor{
+			{Name: "id", ID: 1, Type: types.Int, DefaultExpr: &uniqueRowIDString, Nullable: false},
+			{Name: "created", ID: 2, Type: types.TimestampTZ, DefaultExpr: &nowTZString, Nullable: false},
+			{Name: "channel", ID: 3, Type: types.String, Nullable: false},
+			{Name: "payload", ID: 4, Type: types.String, Nullable: false},
+		},
+		NextColumnID: 5,
+		Families: []descpb.ColumnFamilyDescriptor{
+			{
+				Name:        "primary",
+				ColumnNames: []string{"id", "created", "channel", "payload"},
+				ColumnIDs:   []descpb.ColumnID{1, 2, 3, 4},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: pk("id"),
+		NextIndexID:  2,
+		Privileges: descpb.NewCustomSuperuserPrivilegeDescriptor(
+			descpb.SystemAllowedPrivileges[keys.NotificationsTableID], security.NodeUserName()),
+		FormatVersion:  descpb.InterleavedFormatVersion,
+		NextMutationID: 1,
+	})
 )
 
 // newCommentPrivilegeDescriptor returns a privilege descriptor for comment table
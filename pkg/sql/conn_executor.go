@@ -34,6 +34,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirecancel"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
@@ -253,6 +255,14 @@ type Server struct {
 
 	reCache *tree.RegexpCache
 
+	// notifications keeps track of the sessions executing LISTEN on this node,
+	// and delivers them the notifications sent on any node.
+	notifications *notificationRegistry
+
+	// stalePlans limits the rate of the statistics refreshes caused by stale
+	// plans.
+	stalePlans *stalePlanRateLimiter
+
 	// pool is the parent monitor for all session monitors except "internal" ones.
 	pool *mon.BytesMonitor
 
@@ -291,6 +301,8 @@ func NewServer(cfg *ExecutorConfig, pool *mon.BytesMonitor) *Server {
 		sqlStats:        sqlStats{st: cfg.Settings, apps: make(map[string]*appStats)},
 		reportedStats:   sqlStats{st: cfg.Settings, apps: make(map[string]*appStats)},
 		reCache:         tree.NewRegexpCache(512),
+		notifications:   newNotificationRegistry(),
+		stalePlans:      newStalePlanRateLimiter(),
 	}
 }
 
@@ -315,8 +327,22 @@ func makeMetrics(internal bool) Metrics {
 				6*metricsSampleInterval),
 			SQLTxnsOpen: metric.NewGauge(getMetricMeta(MetaSQLTxnsOpen, internal)),
 
+			SQLSelectServiceLatency: metric.NewLatency(getMetricMeta(MetaSelectServiceLatency, internal),
+				6*metricsSampleInterval),
+			SQLUpdateServiceLatency: metric.NewLatency(getMetricMeta(MetaUpdateServiceLatency, internal),
+				6*metricsSampleInterval),
+			SQLInsertServiceLatency: metric.NewLatency(getMetricMeta(MetaInsertServiceLatency, internal),
+				6*metricsSampleInterval),
+			SQLDeleteServiceLatency: metric.NewLatency(getMetricMeta(MetaDeleteServiceLatency, internal),
+				6*metricsSampleInterval),
+			SQLDdlServiceLatency: metric.NewLatency(getMetricMeta(MetaDdlServiceLatency, internal),
+				6*metricsSampleInterval),
+
 			TxnAbortCount: metric.NewCounter(getMetricMeta(MetaTxnAbort, internal)),
 			FailureCount:  metric.NewCounter(getMetricMeta(MetaFailure, internal)),
+
+			MemBudgetExceededCount: metric.NewCounter(getMetricMeta(MetaMemBudgetExceeded, internal)),
+			TxnAutoRetryCount:      metric.NewCounter(getMetricMeta(MetaTxnAutoRetry, internal)),
 		},
 		StartedStatementCounters:  makeStartedStatementCounters(internal),
 		ExecutedStatementCounters: makeExecutedStatementCounters(internal),
@@ -334,6 +360,8 @@ func (s *Server) Start(ctx context.Context, stopper *stop.Stopper) {
 	s.PeriodicallyClearSQLStats(ctx, stopper, MaxSQLStatReset, &s.reportedStats, s.ResetReportedStats)
 	// Start a second loop to clear SQL stats at the requested interval.
 	s.PeriodicallyClearSQLStats(ctx, stopper, SQLStatReset, &s.sqlStats, s.ResetSQLStats)
+	// Start delivering the notifications sent by NOTIFY.
+	s.notifications.start(ctx, stopper, s.cfg)
 }
 
 // ResetSQLStats resets the executor's collected sql statistics.
@@ -400,12 +428,17 @@ func (s *Server) GetExecutorConfig() *ExecutorConfig {
 //
 // Args:
 // args: The initial session parameters. They are validated by SetupConn
-//   and an error is returned if this validation fails.
+//
+//	and an error is returned if this validation fails.
+//
 // stmtBuf: The incoming statement for the new connExecutor.
 // clientComm: The interface through which the new connExecutor is going to
-//   produce results for the client.
+//
+//	produce results for the client.
+//
 // memMetrics: The metrics that statements executed on this connection will
-//   contribute to.
+//
+//	contribute to.
 func (s *Server) SetupConn(
 	ctx context.Context,
 	args SessionArgs,
@@ -427,6 +460,7 @@ func (s *Server) SetupConn(
 		ctx, sd, args.SessionDefaults, stmtBuf, clientComm, memMetrics, &s.Metrics,
 		s.sqlStats.getStatsForApplication(sd.ApplicationName),
 	)
+	ex.queryCancelKey = pgwirecancel.MakeBackendKeyData(s.cfg.NodeID.SQLInstanceID())
 	return ConnectionHandler{ex}, nil
 }
 
@@ -449,6 +483,19 @@ func (h ConnectionHandler) GetUnqualifiedIntSize() *types.T {
 	return parser.NakedIntTypeFromDefaultIntSize(size)
 }
 
+// GetQueryCancelKey returns the key that the client can use to cancel the
+// queries of the session with a pgwire CancelRequest.
+func (h ConnectionHandler) GetQueryCancelKey() pgwirecancel.BackendKeyData {
+	return h.ex.queryCancelKey
+}
+
+// ShouldSendNotice returns whether a notice generated outside of the
+// execution of a statement, e.g. during the session set-up, can be sent to
+// the client.
+func (h ConnectionHandler) ShouldSendNotice(notice pgnotice.Notice) bool {
+	return shouldSendNotice(&h.ex.server.cfg.Settings.SV, h.ex.sessionData, notice)
+}
+
 // GetParamStatus retrieves the configured value of the session
 // variable identified by varName. This is used for the initial
 // message sent to a client during a session set-up.
@@ -539,10 +586,12 @@ func (s *Server) newConnExecutor(
 	appStats *appStats,
 ) *connExecutor {
 	// Create the various monitors.
-	// The session monitors are started in activate().
-	sessionRootMon := mon.NewMonitor(
+	// The session monitors are started in activate(). The session memory limit
+	// is read here, once per session.
+	sessionRootMon := mon.NewMonitorWithLimit(
 		"session root",
 		mon.MemoryResource,
+		sessionMemLimit.Get(&s.cfg.Settings.SV),
 		memMetrics.CurBytesCount,
 		memMetrics.MaxBytesHist,
 		-1 /* increment */, math.MaxInt64, s.cfg.Settings,
@@ -828,6 +877,10 @@ func (ex *connExecutor) close(ctx context.Context, closeType closeType) {
 		log.Warningf(ctx, "error while cleaning up connExecutor: %s", err)
 	}
 
+	if ex.notificationListener != nil {
+		ex.server.notifications.unlistenAll(ex.notificationListener)
+	}
+
 	if ex.hasCreatedTemporarySchema && !ex.server.cfg.TestingKnobs.DisableTempObjectsCleanupOnSessionExit {
 		ie := MakeInternalExecutor(ctx, ex.server, MemoryMetrics{}, ex.server.cfg.Settings)
 		err := cleanupSessionTempObjects(
@@ -1014,6 +1067,10 @@ type connExecutor struct {
 		// executed within another higher-level txn.
 		onTxnRestart func()
 
+		// notifications accumulates the effects of LISTEN, UNLISTEN and NOTIFY
+		// until the transaction commits.
+		notifications txnNotifications
+
 		// savepoints maintains the stack of savepoints currently open.
 		savepoints savepointStack
 		// savepointsAtTxnRewindPos is a snapshot of the savepoints stack before
@@ -1097,6 +1154,10 @@ type connExecutor struct {
 		// cancels the session if the idle time in a transaction exceeds the
 		// idle_in_transaction_session_timeout.
 		IdleInTransactionSessionTimeout timeout
+
+		// terminationErr, if set, is the reason for which the session was
+		// canceled. It is returned to the client when the connection is closed.
+		terminationErr error
 	}
 
 	// curStmtAST is the statement that's currently being prepared or executed, if
@@ -1105,6 +1166,11 @@ type connExecutor struct {
 
 	sessionID ClusterWideID
 
+	// queryCancelKey is the key used by the client to cancel the queries of
+	// the session with a pgwire CancelRequest. It is zero for the sessions
+	// which are not associated with a pgwire connection.
+	queryCancelKey pgwirecancel.BackendKeyData
+
 	// activated determines whether activate() was called already.
 	// When this is set, close() must be called to release resources.
 	activated bool
@@ -1117,6 +1183,10 @@ type connExecutor struct {
 	// responds to user queries or an internal one.
 	executorType executorType
 
+	// notificationListener is set once the session has executed LISTEN. It
+	// accumulates the notifications to send to the client.
+	notificationListener *notificationListener
+
 	// hasCreatedTemporarySchema is set if the executor has created a
 	// temporary schema, which requires special cleanup on close.
 	hasCreatedTemporarySchema bool
@@ -1235,6 +1305,10 @@ func (ex *connExecutor) resetExtraTxnState(ctx context.Context, ev txnEvent) err
 
 	switch ev {
 	case txnCommit, txnRollback:
+		if ev == txnCommit {
+			ex.commitNotifications()
+		}
+		ex.extraTxnState.notifications.reset()
 		ex.extraTxnState.savepoints.clear()
 		// After txn is finished, we need to call onTxnFinish (if it's non-nil).
 		if ex.extraTxnState.onTxnFinish != nil {
@@ -1242,6 +1316,7 @@ func (ex *connExecutor) resetExtraTxnState(ctx context.Context, ev txnEvent) err
 			ex.extraTxnState.onTxnFinish = nil
 		}
 	case txnRestart:
+		ex.extraTxnState.notifications.reset()
 		if ex.extraTxnState.onTxnRestart != nil {
 			ex.extraTxnState.onTxnRestart()
 		}
@@ -1284,7 +1359,8 @@ func (ex *connExecutor) DescriptorValidationContext(ctx context.Context) context
 // Args:
 // parentMon: The root monitor.
 // reserved: Memory reserved for the connection. The connExecutor takes
-//   ownership of this memory.
+//
+//	ownership of this memory.
 func (ex *connExecutor) activate(
 	ctx context.Context, parentMon *mon.BytesMonitor, reserved mon.BoundAccount,
 ) {
@@ -1359,13 +1435,16 @@ func (ex *connExecutor) run(
 	ex.onCancelSession = onCancel
 
 	ex.sessionID = ex.generateID()
-	ex.server.cfg.SessionRegistry.register(ex.sessionID, ex)
+	ex.server.cfg.SessionRegistry.register(ex.sessionID, ex.queryCancelKey, ex)
 	ex.planner.extendedEvalCtx.setSessionID(ex.sessionID)
-	defer ex.server.cfg.SessionRegistry.deregister(ex.sessionID)
+	defer ex.server.cfg.SessionRegistry.deregister(ex.sessionID, ex.queryCancelKey)
 
 	for {
 		ex.curStmtAST = nil
 		if err := ctx.Err(); err != nil {
+			if termErr := ex.sessionTerminationErr(); termErr != nil {
+				return termErr
+			}
 			return err
 		}
 
@@ -1374,6 +1453,9 @@ func (ex *connExecutor) run(
 			if errors.IsAny(err, io.EOF, errDrainingComplete) {
 				return nil
 			}
+			if termErr := ex.sessionTerminationErr(); termErr != nil {
+				return termErr
+			}
 			return err
 		}
 	}
@@ -1547,7 +1629,14 @@ func (ex *connExecutor) execCmd(ctx context.Context) error {
 		payload = eventNonRetriableErrPayload{err: tcmd.Err}
 	case Sync:
 		// Note that the Sync result will flush results to the network connection.
-		res = ex.clientComm.CreateSyncResult(pos)
+		syncRes := ex.clientComm.CreateSyncResult(pos)
+		res = syncRes
+		if ex.notificationListener != nil && ex.idleConn() {
+			// Notifications are delivered in between transactions.
+			for _, n := range ex.notificationListener.drain() {
+				syncRes.BufferNotification(n)
+			}
+		}
 		if ex.draining {
 			// If we're draining, check whether this is a good time to finish the
 			// connection. If we're not inside a transaction, we stop processing
@@ -1632,7 +1721,7 @@ func (ex *connExecutor) execCmd(ctx context.Context) error {
 		// command and only sends Syncs once it received some data. But we ignore
 		// flush commands (just like we ignore any other commands) when skipping
 		// to the next batch.
-		if err := ex.clientComm.Flush(pos); err != nil {
+		if err := ex.clientComm.Flush(ctx, pos); err != nil {
 			return err
 		}
 		if err := ex.stmtBuf.seekToNextBatch(); err != nil {
@@ -1952,6 +2041,13 @@ func (ex *connExecutor) rewindPrepStmtNamespace(ctx context.Context) {
 // rewind is possible. If it is, client communication is blocked until the
 // rewindCapability is exercised.
 func (ex *connExecutor) getRewindTxnCapability() (rewindCapability, bool) {
+	// If the transaction was already retried as many times as allowed, let the
+	// client see the retryable error.
+	if maxRetries := maxAutoRetries.Get(&ex.server.cfg.Settings.SV); maxRetries > 0 &&
+		int64(ex.extraTxnState.autoRetryCounter) >= maxRetries {
+		return rewindCapability{}, false
+	}
+
 	cl := ex.clientComm.LockCommunication()
 
 	// If we already delivered results at or past the start position, we can't
@@ -2191,6 +2287,7 @@ func (ex *connExecutor) initPlanner(ctx context.Context, p *planner) {
 	p.sessionDataMutator = ex.dataMutator
 	p.noticeSender = nil
 	p.preparedStatements = ex.getPrepStmtsAccessor()
+	p.notifications = connExNotificationsAccessor{ex: ex}
 
 	p.queryCacheSession.Init()
 	p.optPlanningCtx.init(p)
@@ -2244,6 +2341,7 @@ func (ex *connExecutor) txnStateTransitionsApplyWrapper(
 
 	if advInfo.code == rewind {
 		ex.extraTxnState.autoRetryCounter++
+		ex.metrics.EngineMetrics.TxnAutoRetryCount.Inc(1)
 	}
 
 	// Handle transaction events which cause updates to txnState.
@@ -2360,15 +2458,44 @@ func (ex *connExecutor) cancelQuery(queryID ClusterWideID) bool {
 	return false
 }
 
+// cancelCurrentQueries is part of the registrySession interface.
+func (ex *connExecutor) cancelCurrentQueries() bool {
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+	for _, queryMeta := range ex.mu.ActiveQueries {
+		queryMeta.cancel()
+	}
+	return len(ex.mu.ActiveQueries) > 0
+}
+
 // cancelSession is part of the registrySession interface.
 func (ex *connExecutor) cancelSession() {
 	if ex.onCancelSession == nil {
 		return
 	}
-	// TODO(abhimadan): figure out how to send a nice error message to the client.
 	ex.onCancelSession()
 }
 
+// cancelSessionWithError cancels the session like cancelSession, and records
+// err as the reason for the cancellation. The error is sent to the client
+// before the connection is closed.
+func (ex *connExecutor) cancelSessionWithError(err error) {
+	ex.mu.Lock()
+	if ex.mu.terminationErr == nil {
+		ex.mu.terminationErr = err
+	}
+	ex.mu.Unlock()
+	ex.cancelSession()
+}
+
+// sessionTerminationErr returns the error passed to cancelSessionWithError, if
+// any.
+func (ex *connExecutor) sessionTerminationErr() error {
+	ex.mu.RLock()
+	defer ex.mu.RUnlock()
+	return ex.mu.terminationErr
+}
+
 // user is part of the registrySession interface.
 func (ex *connExecutor) user() security.SQLUsername {
 	return ex.sessionData.User()
@@ -120,7 +120,7 @@ func (ex *connExecutor) execStmt(
 		}
 		switch ev.(type) {
 		case eventNonRetriableErr:
-			ex.recordFailure()
+			ex.recordFailure(payload.(payloadWithError).errorCause())
 		}
 
 	case stateAborted:
@@ -137,7 +137,7 @@ func (ex *connExecutor) execStmt(
 		// Cancel the session if the idle time exceeds the idle in session timeout.
 		ex.mu.IdleInSessionTimeout = timeout{time.AfterFunc(
 			ex.sessionData.IdleInSessionTimeout,
-			ex.cancelSession,
+			func() { ex.cancelSessionWithError(sqlerrors.IdleSessionTimeoutError) },
 		)}
 	}
 
@@ -150,7 +150,9 @@ func (ex *connExecutor) execStmt(
 			default:
 				ex.mu.IdleInTransactionSessionTimeout = timeout{time.AfterFunc(
 					ex.sessionData.IdleInTransactionSessionTimeout,
-					ex.cancelSession,
+					func() {
+						ex.cancelSessionWithError(sqlerrors.IdleInTransactionSessionTimeoutError)
+					},
 				)}
 			}
 		}
@@ -169,8 +171,11 @@ func (ex *connExecutor) execStmt(
 	return ev, payload, err
 }
 
-func (ex *connExecutor) recordFailure() {
+func (ex *connExecutor) recordFailure(err error) {
 	ex.metrics.EngineMetrics.FailureCount.Inc(1)
+	if pgerror.GetPGCode(err) == pgcode.OutOfMemory {
+		ex.metrics.EngineMetrics.MemBudgetExceededCount.Inc(1)
+	}
 }
 
 // execPortal executes a prepared statement. It is a "wrapper" around execStmt
@@ -494,6 +499,7 @@ func (ex *connExecutor) execStmtInOpenState(
 				AST:             s.Statement,
 				NumPlaceholders: stmt.NumPlaceholders,
 				NumAnnotations:  stmt.NumAnnotations,
+				Hints:           stmt.Hints,
 			},
 			ex.generateID(),
 		)
@@ -598,6 +604,11 @@ func (ex *connExecutor) execStmtInOpenState(
 	prevSteppingMode := ex.state.mu.txn.ConfigureStepping(ctx, kv.SteppingEnabled)
 	defer func() { _ = ex.state.mu.txn.ConfigureStepping(ctx, prevSteppingMode) }()
 
+	// The statement's requests wait on conflicting locks for at most the
+	// session's lock_timeout.
+	prevLockTimeout := ex.state.mu.txn.SetLockTimeout(ex.sessionData.LockTimeout)
+	defer func() { _ = ex.state.mu.txn.SetLockTimeout(prevLockTimeout) }()
+
 	// Then we create a sequencing point.
 	//
 	// This is not the only place where a sequencing point is
@@ -838,7 +849,7 @@ func (ex *connExecutor) dispatchToExecutionEngine(
 	}
 
 	ex.sessionTracing.TracePlanCheckStart(ctx)
-	distributePlan := getPlanDistribution(
+	distributePlan := getStatementPlanDistribution(
 		ctx, planner, planner.execCfg.NodeID, ex.sessionData.DistSQLMode, planner.curPlan.main,
 	)
 	ex.sessionTracing.TracePlanCheckEnd(ctx, nil, distributePlan.WillDistribute())
@@ -884,13 +895,36 @@ func (ex *connExecutor) dispatchToExecutionEngine(
 	default:
 		planner.curPlan.flags.Set(planFlagNotDistributed)
 	}
+
+	// If the input of a mutation is read using distributed execution, the
+	// reads are performed by leaf txns concurrently with the writes of the
+	// root txn, and their refresh spans are only imported into the root txn
+	// once the flow finishes. The mutation must therefore not commit the txn
+	// itself, and the statement must be retried if the root txn refreshed its
+	// read timestamp in the meantime, since that refresh did not cover the
+	// reads of the leaf txns.
+	var mutationReadTS hlc.Timestamp
+	_, mutationTW := mutationWriter(planner.curPlan.main.planNode)
+	distributedMutation := distributePlan.WillDistribute() && mutationTW != nil
+	if distributedMutation {
+		mutationTW.disableAutoCommit()
+		mutationReadTS = planner.txn.ReadTimestamp()
+	}
+
 	ex.sessionTracing.TraceExecStart(ctx, "distributed")
 	stats, err := ex.execWithDistSQLEngine(
 		ctx, planner, stmt.AST.StatementType(), res, distributePlan.WillDistribute(), progAtomic,
 	)
+	if distributedMutation && res.Err() == nil && !planner.txn.ReadTimestamp().EqOrdering(mutationReadTS) {
+		res.SetError(planner.txn.GenerateForcedRetryableError(
+			ctx, "read timestamp changed during mutation with distributed input",
+		))
+	}
 	ex.sessionTracing.TraceExecEnd(ctx, res.Err(), res.RowsAffected())
 	ex.statsCollector.phaseTimes[plannerEndExecStmt] = timeutil.Now()
 
+	ex.maybeInvalidateStalePlan(ctx, planner, res)
+
 	// Record the statement summary. This also closes the plan if the
 	// plan has not been closed earlier.
 	ex.recordStatementSummary(
@@ -189,6 +189,7 @@ func (ex *connExecutor) execRollbackToSavepointInOpenState(
 	}
 
 	ex.extraTxnState.savepoints.popToIdx(idx)
+	ex.extraTxnState.notifications.rollbackToSavepoint()
 
 	if entry.kvToken.Initial() {
 		return eventTxnRestart{}, nil
@@ -267,6 +268,7 @@ func (ex *connExecutor) execRollbackToSavepointInAbortedState(
 	if err := ex.state.mu.txn.RollbackToSavepoint(ctx, entry.kvToken); err != nil {
 		return ex.makeErrEvent(err, s)
 	}
+	ex.extraTxnState.notifications.rollbackToSavepoint()
 
 	if entry.kvToken.Initial() {
 		return eventTxnRestart{}, nil
@@ -15,6 +15,7 @@ import (
 	gosql "database/sql"
 	"database/sql/driver"
 	"fmt"
+	"math"
 	"net/url"
 	"regexp"
 	"strings"
@@ -792,6 +793,78 @@ func TestErrorDuringPrepareInExplicitTransactionPropagates(t *testing.T) {
 	require.NoError(t, tx.Commit())
 }
 
+// TestMaxAutoRetries verifies that sql.txn.max_auto_retries bounds the number
+// of automatic retries of a transaction that keeps encountering retryable
+// errors, and that by default the number of retries isn't bounded.
+func TestMaxAutoRetries(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	filter := newDynamicRequestFilter()
+	s, sqlDB, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			Store: &kvserver.StoreTestingKnobs{
+				TestingRequestFilter: filter.filter,
+			},
+		},
+	})
+	defer s.Stopper().Stop(context.Background())
+
+	testDB := sqlutils.MakeSQLRunner(sqlDB)
+	testDB.Exec(t, "SET CLUSTER SETTING sql.stats.automatic_collection.enabled = false")
+	testDB.Exec(t, "CREATE TABLE foo (i INT PRIMARY KEY)")
+	tableID := sqlutils.QueryTableID(t, sqlDB, "defaultdb", "public", "foo")
+	tableKey := keys.SystemSQLCodec.TablePrefix(tableID)
+	tableSpan := roachpb.Span{Key: tableKey, EndKey: tableKey.PrefixEnd()}
+
+	// Fail the first numToFail scans of the table with an error that can't be
+	// retried by the TxnCoordSender, so that the connExecutor has to retry the
+	// transaction. Each attempt of the statement scans the table once.
+	var attempts, numToFail int64
+	filter.setFilter(func(ctx context.Context, ba roachpb.BatchRequest) *roachpb.Error {
+		if ba.Txn == nil {
+			return nil
+		}
+		req, ok := ba.GetArg(roachpb.Scan)
+		if !ok || !tableSpan.ContainsKey(req.Header().Key) {
+			return nil
+		}
+		if atomic.AddInt64(&attempts, 1) > atomic.LoadInt64(&numToFail) {
+			return nil
+		}
+		return roachpb.NewErrorWithTxn(
+			roachpb.NewTransactionRetryError(roachpb.RETRY_REASON_UNKNOWN, "boom"), ba.Txn)
+	})
+	run := func(toFail int64) (int64, error) {
+		atomic.StoreInt64(&attempts, 0)
+		atomic.StoreInt64(&numToFail, toFail)
+		_, err := sqlDB.Exec("SELECT * FROM foo")
+		return atomic.LoadInt64(&attempts), err
+	}
+
+	// By default, the transaction is retried until it succeeds.
+	testDB.CheckQueryResults(t, "SHOW CLUSTER SETTING sql.txn.max_auto_retries", [][]string{{"0"}})
+	n, err := run(10)
+	require.NoError(t, err)
+	require.Equal(t, int64(11), n)
+
+	const maxRetries = 3
+	testDB.Exec(t, fmt.Sprintf("SET CLUSTER SETTING sql.txn.max_auto_retries = %d", maxRetries))
+
+	// A transaction that succeeds within the allowed number of retries is not
+	// affected.
+	n, err = run(maxRetries)
+	require.NoError(t, err)
+	require.Equal(t, int64(maxRetries+1), n)
+
+	// Once the retries are exhausted, the client gets the retryable error.
+	n, err = run(math.MaxInt64)
+	var pqErr *pq.Error
+	require.True(t, errors.As(err, &pqErr), "expected a retryable error, got %v", err)
+	require.Equal(t, pgcode.SerializationFailure.String(), string(pqErr.Code))
+	require.Equal(t, int64(maxRetries+1), n)
+}
+
 // TestTrimFlushedStatements verifies that the conn executor trims the
 // statements buffer once the corresponding results are returned to the user.
 func TestTrimFlushedStatements(t *testing.T) {
@@ -631,7 +631,7 @@ type ClientComm interface {
 
 	// Flush delivers all the previous results to the client. The results might
 	// have been buffered, in which case this flushes the buffer.
-	Flush(pos CmdPos) error
+	Flush(ctx context.Context, pos CmdPos) error
 }
 
 // CommandResult represents the result of a statement. It which needs to be
@@ -773,6 +773,10 @@ type DeleteResult interface {
 // flushed.
 type SyncResult interface {
 	ResultBase
+
+	// BufferNotification buffers a notification generated by NOTIFY, to be sent
+	// to the client before the readyForQuery message.
+	BufferNotification(notification Notification)
 }
 
 // FlushResult represents the result of a Flush command. When this result is
@@ -872,6 +876,9 @@ type bufferedCommandResult struct {
 
 	// closeCallback, if set, is called when Close()/Discard() is called.
 	closeCallback func(*bufferedCommandResult, resCloseType, error)
+
+	// rowFn, if set, is called by AddRow() instead of buffering the row.
+	rowFn func(context.Context, tree.Datums) error
 }
 
 var _ RestrictedCommandResult = &bufferedCommandResult{}
@@ -905,6 +912,9 @@ func (r *bufferedCommandResult) AddRow(ctx context.Context, row tree.Datums) err
 	if r.errOnly {
 		panic("AddRow() called when errOnly is set")
 	}
+	if r.rowFn != nil {
+		return r.rowFn(ctx, row)
+	}
 	rowCopy := make(tree.Datums, len(row))
 	copy(rowCopy, row)
 	r.rows = append(r.rows, rowCopy)
@@ -949,6 +959,9 @@ func (r *bufferedCommandResult) Discard() {
 	}
 }
 
+// BufferNotification is part of the SyncResult interface.
+func (r *bufferedCommandResult) BufferNotification(Notification) {}
+
 // SetInferredTypes is part of the DescribeResult interface.
 func (r *bufferedCommandResult) SetInferredTypes([]oid.Oid) {}
 
@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/util/cache"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
@@ -36,11 +37,14 @@ import (
 // generated).
 // The datadriven test contains string representations of this struct which make
 // it easier to visualize.
-// TODO(asubiotto): Make this thread-safe once it's used.
+// Registry is safe for concurrent use.
 type Registry struct {
-	// indexMap is an LRU cache that keeps track of up to indexMapMaxSize
-	// contended indexes.
-	indexMap *indexMap
+	mu struct {
+		syncutil.Mutex
+		// indexMap is an LRU cache that keeps track of up to indexMapMaxSize
+		// contended indexes.
+		indexMap *indexMap
+	}
 }
 
 const (
@@ -185,9 +189,8 @@ func (m *indexMap) add(tableID descpb.ID, indexID descpb.IndexID, v *indexMapVal
 
 // NewRegistry creates a new Registry.
 func NewRegistry() *Registry {
-	r := &Registry{
-		indexMap: newIndexMap(),
-	}
+	r := &Registry{}
+	r.mu.indexMap = newIndexMap()
 	return r
 }
 
@@ -199,20 +202,67 @@ func (r *Registry) AddContentionEvent(c roachpb.ContentionEvent) error {
 	}
 	tableID := descpb.ID(rawTableID)
 	indexID := descpb.IndexID(rawIndexID)
-	if v, ok := r.indexMap.get(tableID, indexID); !ok {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if v, ok := r.mu.indexMap.get(tableID, indexID); !ok {
 		// This is the first contention event seen for the given tableID/indexID
 		// pair.
-		r.indexMap.add(tableID, indexID, newIndexMapValue(c))
+		r.mu.indexMap.add(tableID, indexID, newIndexMapValue(c))
 	} else {
 		v.addContentionEvent(c)
 	}
 	return nil
 }
 
+// Entry describes the contention events observed on a single key of an index
+// that were caused by a single transaction.
+type Entry struct {
+	TableID descpb.ID
+	IndexID descpb.IndexID
+	// NumContentionEvents and CumulativeContentionTime describe all contention
+	// events observed on the index, regardless of the key.
+	NumContentionEvents      uint64
+	CumulativeContentionTime time.Duration
+	Key                      roachpb.Key
+	// TxnID is the ID of the transaction that caused the contention events and
+	// Count is the number of times that transaction was observed on Key.
+	TxnID uuid.UUID
+	Count int
+}
+
+// Entries returns a snapshot of the contention information kept by the
+// Registry. The entries for a given index are ordered by key.
+func (r *Registry) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var entries []Entry
+	r.mu.indexMap.internalCache.Do(func(e *cache.Entry) {
+		key := e.Key.(indexMapKey)
+		v := e.Value.(*indexMapValue)
+		v.orderedKeyMap.Do(func(k, txnCache interface{}) bool {
+			txnCache.(*cache.UnorderedCache).Do(func(e *cache.Entry) {
+				entries = append(entries, Entry{
+					TableID:                  key.tableID,
+					IndexID:                  key.indexID,
+					NumContentionEvents:      v.numContentionEvents,
+					CumulativeContentionTime: v.cumulativeContentionTime,
+					Key:                      roachpb.Key(k.(comparableKey)),
+					TxnID:                    e.Key.(uuid.UUID),
+					Count:                    e.Value.(int),
+				})
+			})
+			return false
+		})
+	})
+	return entries
+}
+
 // String returns a string representation of the Registry.
 func (r *Registry) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	var b strings.Builder
-	r.indexMap.internalCache.Do(func(e *cache.Entry) {
+	r.mu.indexMap.internalCache.Do(func(e *cache.Entry) {
 		key := e.Key.(indexMapKey)
 		b.WriteString(fmt.Sprintf("tableID=%d indexID=%d\n", key.tableID, key.indexID))
 		writeChild := func(prefix, s string) {
@@ -19,10 +19,12 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/datadriven"
+	"github.com/stretchr/testify/require"
 )
 
 // TestRegistry runs the datadriven test found in testdata/contention_registry.
@@ -123,3 +125,47 @@ func TestRegistry(t *testing.T) {
 		}
 	})
 }
+
+func TestRegistryEntries(t *testing.T) {
+	r := NewRegistry()
+	require.Empty(t, r.Entries())
+
+	txnA, txnB := uuid.MakeV4(), uuid.MakeV4()
+	makeKey := func(key string) roachpb.Key {
+		return encoding.EncodeStringAscending(keys.MakeTableIDIndexID(nil, 53, 1), key)
+	}
+	for _, ev := range []struct {
+		key   string
+		txnID uuid.UUID
+	}{
+		{key: "b", txnID: txnA},
+		{key: "a", txnID: txnA},
+		{key: "b", txnID: txnA},
+		{key: "b", txnID: txnB},
+	} {
+		require.NoError(t, r.AddContentionEvent(roachpb.ContentionEvent{
+			Key:      makeKey(ev.key),
+			TxnMeta:  enginepb.TxnMeta{ID: ev.txnID},
+			Duration: time.Second,
+		}))
+	}
+
+	entries := r.Entries()
+	require.Len(t, entries, 3)
+	counts := make(map[string]int)
+	for i, e := range entries {
+		require.Equal(t, descpb.ID(53), e.TableID)
+		require.Equal(t, descpb.IndexID(1), e.IndexID)
+		require.Equal(t, uint64(4), e.NumContentionEvents)
+		require.Equal(t, 4*time.Second, e.CumulativeContentionTime)
+		if i > 0 {
+			require.True(t, entries[i-1].Key.Compare(e.Key) <= 0, "entries are not ordered by key")
+		}
+		counts[fmt.Sprintf("%s/%s", e.Key, e.TxnID)] = e.Count
+	}
+	require.Equal(t, map[string]int{
+		fmt.Sprintf("%s/%s", makeKey("a"), txnA): 1,
+		fmt.Sprintf("%s/%s", makeKey("b"), txnA): 2,
+		fmt.Sprintf("%s/%s", makeKey("b"), txnB): 1,
+	}, counts)
+}
@@ -58,6 +58,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -126,6 +127,10 @@ var crdbInternal = virtualSchema{
 		catconstants.CrdbInternalZonesTableID:                     crdbInternalZonesTable,
 		catconstants.CrdbInternalInvalidDescriptorsTableID:        crdbInternalInvalidDescriptorsTable,
 		catconstants.CrdbInternalClusterDatabasePrivilegesTableID: crdbInternalClusterDatabasePrivilegesTable,
+		catconstants.CrdbInternalNodeContentionEventsTableID:      crdbInternalNodeContentionEventsTable,
+		catconstants.CrdbInternalHotRangesTableID:                 crdbInternalHotRangesTable,
+		catconstants.CrdbInternalClusterStmtStatsTableID:          crdbInternalClusterStmtStatsTable,
+		catconstants.CrdbInternalClusterContentionEventsTableID:   crdbInternalClusterContentionEventsTable,
 	},
 	validWithNoDatabaseContext: true,
 }
@@ -926,6 +931,129 @@ CREATE TABLE crdb_internal.node_statement_statistics (
 	},
 }
 
+var crdbInternalClusterStmtStatsTable = virtualSchemaTable{
+	comment: `statement statistics aggregated across all nodes (in-memory, not durable; ` +
+		`cluster RPC; expensive!)`,
+	schema: `
+CREATE TABLE crdb_internal.statement_statistics (
+  application_name    STRING NOT NULL,
+  flags               STRING NOT NULL,
+  key                 STRING NOT NULL,
+  implicit_txn        BOOL NOT NULL,
+  node_ids            INT[] NOT NULL,
+  count               INT NOT NULL,
+  first_attempt_count INT NOT NULL,
+  max_retries         INT NOT NULL,
+  rows_avg            FLOAT NOT NULL,
+  rows_var            FLOAT NOT NULL,
+  service_lat_avg     FLOAT NOT NULL,
+  service_lat_var     FLOAT NOT NULL,
+  rows_read_avg       FLOAT NOT NULL,
+  rows_read_var       FLOAT NOT NULL,
+  bytes_read_avg      FLOAT NOT NULL,
+  bytes_read_var      FLOAT NOT NULL
+)`,
+	populate: func(ctx context.Context, p *planner, _ *dbdesc.Immutable, addRow func(...tree.Datum) error) error {
+		hasViewActivity, err := p.HasRoleOption(ctx, roleoption.VIEWACTIVITY)
+		if err != nil {
+			return err
+		}
+		if !hasViewActivity {
+			return pgerror.Newf(pgcode.InsufficientPrivilege,
+				"user %s does not have %s privilege", p.User(), roleoption.VIEWACTIVITY)
+		}
+		ss, err := p.extendedEvalCtx.NodesStatusServer.OptionalNodesStatusServer(
+			errorutil.FeatureNotAvailableToNonSystemTenantsIssue)
+		if err != nil {
+			return err
+		}
+		response, err := ss.Statements(ctx, &serverpb.StatementsRequest{})
+		if err != nil {
+			return err
+		}
+
+		// The statistics of a statement fingerprint are collected independently
+		// by every node which executed it, and combined here.
+		type fingerprintStats struct {
+			key     roachpb.StatementStatisticsKey
+			nodeIDs []roachpb.NodeID
+			stats   roachpb.StatementStatistics
+		}
+		byKey := make(map[roachpb.StatementStatisticsKey]*fingerprintStats)
+		var fingerprints []*fingerprintStats
+		for i := range response.Statements {
+			stmt := &response.Statements[i]
+			key := stmt.Key.KeyData
+			// The plan of a statement may differ across nodes; it is not part of
+			// the fingerprint.
+			key.Opt, key.Vec = false, false
+			f, ok := byKey[key]
+			if !ok {
+				f = &fingerprintStats{key: key}
+				byKey[key] = f
+				fingerprints = append(fingerprints, f)
+			}
+			f.nodeIDs = append(f.nodeIDs, stmt.Key.NodeID)
+			f.stats.Add(&stmt.Stats)
+		}
+		sort.Slice(fingerprints, func(i, j int) bool {
+			a, b := &fingerprints[i].key, &fingerprints[j].key
+			if a.App != b.App {
+				return a.App < b.App
+			}
+			if a.Query != b.Query {
+				return a.Query < b.Query
+			}
+			if a.Failed != b.Failed {
+				return !a.Failed
+			}
+			if a.DistSQL != b.DistSQL {
+				return !a.DistSQL
+			}
+			return !a.ImplicitTxn && b.ImplicitTxn
+		})
+
+		for _, f := range fingerprints {
+			var flags string
+			if f.key.DistSQL {
+				flags = "+"
+			}
+			if f.key.Failed {
+				flags = "!" + flags
+			}
+			sort.Slice(f.nodeIDs, func(i, j int) bool { return f.nodeIDs[i] < f.nodeIDs[j] })
+			nodeIDs := tree.NewDArray(types.Int)
+			for _, nodeID := range f.nodeIDs {
+				if err := nodeIDs.Append(tree.NewDInt(tree.DInt(nodeID))); err != nil {
+					return err
+				}
+			}
+			s := &f.stats
+			if err := addRow(
+				tree.NewDString(f.key.App),
+				tree.NewDString(flags),
+				tree.NewDString(f.key.Query),
+				tree.MakeDBool(tree.DBool(f.key.ImplicitTxn)),
+				nodeIDs,
+				tree.NewDInt(tree.DInt(s.Count)),
+				tree.NewDInt(tree.DInt(s.FirstAttemptCount)),
+				tree.NewDInt(tree.DInt(s.MaxRetries)),
+				tree.NewDFloat(tree.DFloat(s.NumRows.Mean)),
+				tree.NewDFloat(tree.DFloat(s.NumRows.GetVariance(s.Count))),
+				tree.NewDFloat(tree.DFloat(s.ServiceLat.Mean)),
+				tree.NewDFloat(tree.DFloat(s.ServiceLat.GetVariance(s.Count))),
+				tree.NewDFloat(tree.DFloat(s.RowsRead.Mean)),
+				tree.NewDFloat(tree.DFloat(s.RowsRead.GetVariance(s.Count))),
+				tree.NewDFloat(tree.DFloat(s.BytesRead.Mean)),
+				tree.NewDFloat(tree.DFloat(s.BytesRead.GetVariance(s.Count))),
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 // TODO(arul): Explore updating the schema below to have key be an INT and
 // statement_ids be INT[] now that we've moved to having uint64 as the type of
 // StmtID and TxnKey. Issue #55284
@@ -2497,29 +2625,10 @@ CREATE TABLE crdb_internal.ranges_no_leases (
 		if err := p.RequireAdminRole(ctx, "read crdb_internal.ranges_no_leases"); err != nil {
 			return nil, nil, err
 		}
-		descs, err := p.Descriptors().GetAllDescriptors(ctx, p.txn)
+		names, err := makeRangeNamesLookup(ctx, p)
 		if err != nil {
 			return nil, nil, err
 		}
-		// TODO(knz): maybe this could use internalLookupCtx.
-		dbNames := make(map[uint32]string)
-		tableNames := make(map[uint32]string)
-		indexNames := make(map[uint32]map[uint32]string)
-		parents := make(map[uint32]uint32)
-		for _, desc := range descs {
-			id := uint32(desc.GetID())
-			switch desc := desc.(type) {
-			case *tabledesc.Immutable:
-				parents[id] = uint32(desc.ParentID)
-				tableNames[id] = desc.GetName()
-				indexNames[id] = make(map[uint32]string)
-				for _, idx := range desc.PublicNonPrimaryIndexes() {
-					indexNames[id][uint32(idx.GetID())] = idx.GetName()
-				}
-			case *dbdesc.Immutable:
-				dbNames[id] = desc.GetName()
-			}
-		}
 		ranges, err := kvclient.ScanMetaKVs(ctx, p.txn, roachpb.Span{
 			Key:    keys.MinKey,
 			EndKey: keys.MaxKey,
@@ -2584,19 +2693,7 @@ CREATE TABLE crdb_internal.ranges_no_leases (
 				}
 			}
 
-			var dbName, tableName, indexName string
-			if _, tableID, err := p.ExecCfg().Codec.DecodeTablePrefix(desc.StartKey.AsRawKey()); err == nil {
-				parent := parents[tableID]
-				if parent != 0 {
-					tableName = tableNames[tableID]
-					dbName = dbNames[parent]
-					if _, _, idxID, err := p.ExecCfg().Codec.DecodeIndexPrefix(desc.StartKey.AsRawKey()); err == nil {
-						indexName = indexNames[tableID][idxID]
-					}
-				} else {
-					dbName = dbNames[tableID]
-				}
-			}
+			dbName, tableName, indexName := names.lookup(p.ExecCfg().Codec, desc.StartKey.AsRawKey())
 
 			splitEnforcedUntil := tree.DNull
 			if !desc.GetStickyBit().IsEmpty() {
@@ -2621,6 +2718,143 @@ CREATE TABLE crdb_internal.ranges_no_leases (
 	},
 }
 
+// rangeNamesLookup maps the start key of a range to the names of the
+// database, table and index that the range belongs to.
+type rangeNamesLookup struct {
+	dbNames    map[uint32]string
+	tableNames map[uint32]string
+	indexNames map[uint32]map[uint32]string
+	parents    map[uint32]uint32
+}
+
+func makeRangeNamesLookup(ctx context.Context, p *planner) (rangeNamesLookup, error) {
+	descs, err := p.Descriptors().GetAllDescriptors(ctx, p.txn)
+	if err != nil {
+		return rangeNamesLookup{}, err
+	}
+	// TODO(knz): maybe this could use internalLookupCtx.
+	l := rangeNamesLookup{
+		dbNames:    make(map[uint32]string),
+		tableNames: make(map[uint32]string),
+		indexNames: make(map[uint32]map[uint32]string),
+		parents:    make(map[uint32]uint32),
+	}
+	for _, desc := range descs {
+		id := uint32(desc.GetID())
+		switch desc := desc.(type) {
+		case *tabledesc.Immutable:
+			l.parents[id] = uint32(desc.ParentID)
+			l.tableNames[id] = desc.GetName()
+			l.indexNames[id] = make(map[uint32]string)
+			for _, idx := range desc.PublicNonPrimaryIndexes() {
+				l.indexNames[id][uint32(idx.GetID())] = idx.GetName()
+			}
+		case *dbdesc.Immutable:
+			l.dbNames[id] = desc.GetName()
+		}
+	}
+	return l, nil
+}
+
+// lookup returns the names of the database, table and index of the range
+// starting at the given key. The names which do not apply are empty.
+func (l rangeNamesLookup) lookup(
+	codec keys.SQLCodec, startKey roachpb.Key,
+) (dbName, tableName, indexName string) {
+	if _, tableID, err := codec.DecodeTablePrefix(startKey); err == nil {
+		parent := l.parents[tableID]
+		if parent != 0 {
+			tableName = l.tableNames[tableID]
+			dbName = l.dbNames[parent]
+			if _, _, idxID, err := codec.DecodeIndexPrefix(startKey); err == nil {
+				indexName = l.indexNames[tableID][idxID]
+			}
+		} else {
+			dbName = l.dbNames[tableID]
+		}
+	}
+	return dbName, tableName, indexName
+}
+
+var crdbInternalHotRangesTable = virtualSchemaTable{
+	comment: `hottest ranges of each store by queries per second (cluster RPC; expensive!)`,
+	schema: `
+CREATE TABLE crdb_internal.hot_ranges (
+  range_id           INT NOT NULL,
+  node_id            INT NOT NULL,
+  store_id           INT NOT NULL,
+  queries_per_second FLOAT NOT NULL,
+  start_pretty       STRING NOT NULL,
+  end_pretty         STRING NOT NULL,
+  database_name      STRING NOT NULL,
+  table_name         STRING NOT NULL,
+  index_name         STRING NOT NULL
+)
+`,
+	populate: func(ctx context.Context, p *planner, _ *dbdesc.Immutable, addRow func(...tree.Datum) error) error {
+		if err := p.RequireAdminRole(ctx, "read crdb_internal.hot_ranges"); err != nil {
+			return err
+		}
+		ss, err := p.extendedEvalCtx.NodesStatusServer.OptionalNodesStatusServer(
+			errorutil.FeatureNotAvailableToNonSystemTenantsIssue)
+		if err != nil {
+			return err
+		}
+		response, err := ss.HotRanges(ctx, &serverpb.HotRangesRequest{})
+		if err != nil {
+			return err
+		}
+		names, err := makeRangeNamesLookup(ctx, p)
+		if err != nil {
+			return err
+		}
+
+		type hotRange struct {
+			nodeID  roachpb.NodeID
+			storeID roachpb.StoreID
+			serverpb.HotRangesResponse_HotRange
+		}
+		var hotRanges []hotRange
+		for nodeID, nodeResp := range response.HotRangesByNodeID {
+			if nodeResp.ErrorMessage != "" {
+				log.Warningf(ctx, "unable to get hot ranges of n%d: %s", nodeID, nodeResp.ErrorMessage)
+				continue
+			}
+			for _, storeResp := range nodeResp.Stores {
+				for _, r := range storeResp.HotRanges {
+					hotRanges = append(hotRanges, hotRange{
+						nodeID:                     nodeID,
+						storeID:                    storeResp.StoreID,
+						HotRangesResponse_HotRange: r,
+					})
+				}
+			}
+		}
+		// The hottest ranges are listed first.
+		sort.Slice(hotRanges, func(i, j int) bool {
+			return hotRanges[i].QueriesPerSecond > hotRanges[j].QueriesPerSecond
+		})
+
+		for _, r := range hotRanges {
+			dbName, tableName, indexName := names.lookup(p.ExecCfg().Codec, r.Desc.StartKey.AsRawKey())
+			if err := addRow(
+				tree.NewDInt(tree.DInt(r.Desc.RangeID)),
+				tree.NewDInt(tree.DInt(r.nodeID)),
+				tree.NewDInt(tree.DInt(r.storeID)),
+				tree.NewDFloat(tree.DFloat(r.QueriesPerSecond)),
+				tree.NewDString(keys.PrettyPrint(nil /* valDirs */, r.Desc.StartKey.AsRawKey())),
+				tree.NewDString(keys.PrettyPrint(nil /* valDirs */, r.Desc.EndKey.AsRawKey())),
+				tree.NewDString(dbName),
+				tree.NewDString(tableName),
+				tree.NewDString(indexName),
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 // NamespaceKey represents a key from the namespace table.
 type NamespaceKey struct {
 	ParentID descpb.ID
@@ -3795,3 +4029,94 @@ CREATE TABLE crdb_internal.cluster_database_privileges (
 			})
 	},
 }
+
+// crdbInternalNodeContentionEventsTable exposes the contention events
+// aggregated by the contention registry of this node. Only the events of the
+// queries for which this node was the gateway are included.
+var crdbInternalNodeContentionEventsTable = virtualSchemaTable{
+	comment: `contention events observed by queries for which this node was the gateway (RAM; local node only)`,
+	schema: `
+CREATE TABLE crdb_internal.node_contention_events (
+  table_id                   INT NOT NULL,
+  index_id                   INT NOT NULL,
+  num_contention_events      INT NOT NULL,
+  cumulative_contention_time INTERVAL NOT NULL,
+  key                        BYTES NOT NULL,
+  txn_id                     UUID NOT NULL,
+  count                      INT NOT NULL
+)`,
+	populate: func(ctx context.Context, p *planner, _ *dbdesc.Immutable, addRow func(...tree.Datum) error) error {
+		if err := p.RequireAdminRole(ctx, "read crdb_internal.node_contention_events"); err != nil {
+			return err
+		}
+		response, err := p.extendedEvalCtx.SQLStatusServer.ListLocalContentionEvents(
+			ctx, &serverpb.ListContentionEventsRequest{},
+		)
+		if err != nil {
+			return err
+		}
+		return populateContentionEventsTable(ctx, addRow, response, false /* withNodeID */)
+	},
+}
+
+// crdbInternalClusterContentionEventsTable exposes the contention events
+// aggregated by the contention registries of all nodes in the cluster.
+var crdbInternalClusterContentionEventsTable = virtualSchemaTable{
+	comment: `contention events observed by queries across all nodes (cluster RPC; expensive!)`,
+	schema: `
+CREATE TABLE crdb_internal.cluster_contention_events (
+  node_id                    INT NOT NULL,
+  table_id                   INT NOT NULL,
+  index_id                   INT NOT NULL,
+  num_contention_events      INT NOT NULL,
+  cumulative_contention_time INTERVAL NOT NULL,
+  key                        BYTES NOT NULL,
+  txn_id                     UUID NOT NULL,
+  count                      INT NOT NULL
+)`,
+	populate: func(ctx context.Context, p *planner, _ *dbdesc.Immutable, addRow func(...tree.Datum) error) error {
+		if err := p.RequireAdminRole(ctx, "read crdb_internal.cluster_contention_events"); err != nil {
+			return err
+		}
+		response, err := p.extendedEvalCtx.SQLStatusServer.ListContentionEvents(
+			ctx, &serverpb.ListContentionEventsRequest{},
+		)
+		if err != nil {
+			return err
+		}
+		return populateContentionEventsTable(ctx, addRow, response, true /* withNodeID */)
+	},
+}
+
+func populateContentionEventsTable(
+	ctx context.Context,
+	addRow func(...tree.Datum) error,
+	response *serverpb.ListContentionEventsResponse,
+	withNodeID bool,
+) error {
+	for _, e := range response.Events {
+		cumulativeContentionTime := tree.NewDInterval(
+			duration.MakeDuration(e.CumulativeContentionTime.Nanoseconds(), 0 /* days */, 0 /* months */),
+			types.DefaultIntervalTypeMetadata,
+		)
+		row := []tree.Datum{
+			tree.NewDInt(tree.DInt(e.TableID)),
+			tree.NewDInt(tree.DInt(e.IndexID)),
+			tree.NewDInt(tree.DInt(e.NumContentionEvents)),
+			cumulativeContentionTime,
+			tree.NewDBytes(tree.DBytes(e.Key)),
+			tree.NewDUuid(tree.DUuid{UUID: e.TxnID}),
+			tree.NewDInt(tree.DInt(e.Count)),
+		}
+		if withNodeID {
+			row = append([]tree.Datum{tree.NewDInt(tree.DInt(e.NodeID))}, row...)
+		}
+		if err := addRow(row...); err != nil {
+			return err
+		}
+	}
+	for _, rpcErr := range response.Errors {
+		log.Warningf(ctx, "%v", rpcErr.Message)
+	}
+	return nil
+}
@@ -321,8 +321,17 @@ func (p *planner) checkPasswordAndGetHash(
 				"Passwords must be %d characters or longer.", minLength)
 		}
 	}
+	if err := security.CheckPasswordComplexity(&st.SV, password); err != nil {
+		return hashedPassword, err
+	}
 
-	hashedPassword, err = security.HashPassword(password)
+	method := security.PasswordHashMethod(security.PasswordHashMethodSetting.Get(&st.SV))
+	if method == security.HashSCRAMSHA256 && !st.Version.IsActive(ctx, clusterversion.SCRAMAuthentication) {
+		// Nodes running older versions cannot authenticate users whose password
+		// is stored as a SCRAM verifier.
+		method = security.HashBCrypt
+	}
+	hashedPassword, err = security.HashPasswordWithMethod(method, password)
 	if err != nil {
 		return hashedPassword, err
 	}
@@ -36,6 +36,14 @@ func (p *planner) Discard(ctx context.Context, s *tree.Discard) (planNode, error
 
 		// DEALLOCATE ALL
 		p.preparedStatements.DeleteAll(ctx)
+
+		// UNLISTEN *
+		if p.notifications != nil {
+			p.notifications.Unlisten("" /* channel */)
+		}
+
+		// DISCARD SEQUENCES
+		p.sessionDataMutator.ResetSequenceState()
 	default:
 		return nil, errors.AssertionFailedf("unknown mode for DISCARD: %d", s.Mode)
 	}
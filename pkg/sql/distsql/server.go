@@ -222,10 +222,15 @@ func (ds *ServerImpl) setupFlow(
 		)
 	}
 
-	// The monitor opened here is closed in Flow.Cleanup().
-	monitor := mon.NewMonitor(
+	// The monitor opened here is closed in Flow.Cleanup(). On the gateway, its
+	// parent is the monitor of the session's transaction, so the memory used by
+	// the flow counts towards sql.mem.session.max as well as sql.mem.query.max.
+	// On other nodes, its parent is the server's monitor, and only the query
+	// limit applies.
+	monitor := mon.NewMonitorWithLimit(
 		"flow",
 		mon.MemoryResource,
+		execinfra.SettingQueryMemBytes.Get(&ds.Settings.SV),
 		ds.Metrics.CurBytesCount,
 		ds.Metrics.MaxBytesHist,
 		-1, /* use default block size */
@@ -247,7 +252,9 @@ func (ds *ServerImpl) setupFlow(
 		}
 		// The flow will run in a LeafTxn because we do not want each distributed
 		// Txn to heartbeat the transaction.
-		return kv.NewLeafTxn(ctx, ds.DB, req.Flow.Gateway, tis), nil
+		leafTxn := kv.NewLeafTxn(ctx, ds.DB, req.Flow.Gateway, tis)
+		leafTxn.SetLockTimeout(req.EvalContext.SessionData.LockTimeout)
+		return leafTxn, nil
 	}
 
 	var evalCtx *tree.EvalContext
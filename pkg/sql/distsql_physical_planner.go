@@ -520,7 +520,19 @@ func checkSupportForPlanNode(node planNode) (distRecommendation, error) {
 		return canDistribute, nil
 
 	case *windowNode:
-		return checkSupportForPlanNode(n.plan)
+		rec, err := checkSupportForPlanNode(n.plan)
+		if err != nil {
+			return cannotDistribute, err
+		}
+		// If the rows can be hash-partitioned between windowers using the
+		// PARTITION BY columns, we distribute if possible.
+		for _, f := range n.funcs {
+			if len(f.partitionIdxs) > 0 {
+				rec = rec.compose(shouldDistribute)
+				break
+			}
+		}
+		return rec, nil
 
 	case *zeroNode:
 		return canDistribute, nil
@@ -627,6 +639,11 @@ type PlanningCtx struct {
 	// If set, we will record the mapping from planNode to tracing metadata to
 	// later allow associating statistics with the planNode.
 	traceMetadata execNodeTraceMetadata
+
+	// scanConcurrencyUsed is the number of additional TableReaders that were
+	// planned to parallelize scans, which is limited by
+	// sql.distsql.scan_concurrency_limit.
+	scanConcurrencyUsed int64
 }
 
 var _ physicalplan.ExprContext = &PlanningCtx{}
@@ -1188,9 +1205,10 @@ func (dsp *DistSQLPlanner) CheckNodeHealthAndVersion(
 }
 
 // createTableReaders generates a plan consisting of table reader processors,
-// one for each node that has spans that we are reading.
+// one for each node that has spans that we are reading. If spanPartitions is
+// non-nil, it is used instead of partitioning the spans of the scan.
 func (dsp *DistSQLPlanner) createTableReaders(
-	planCtx *PlanningCtx, n *scanNode,
+	planCtx *PlanningCtx, n *scanNode, spanPartitions []SpanPartition,
 ) (*PhysicalPlan, error) {
 	if n.colCfg.addUnwantedAsHidden {
 		panic("addUnwantedAsHidden not supported")
@@ -1220,6 +1238,7 @@ func (dsp *DistSQLPlanner) createTableReaders(
 			cols:                  n.cols,
 			colsToTableOrdinalMap: scanNodeToTableOrdinalMap,
 			containsSystemColumns: n.containsSystemColumns,
+			spanPartitions:        spanPartitions,
 		},
 	)
 	return p, err
@@ -1241,6 +1260,9 @@ type tableReaderPlanningInfo struct {
 	cols                  []*descpb.ColumnDescriptor
 	colsToTableOrdinalMap []int
 	containsSystemColumns bool
+	// spanPartitions, if set, determines the placement of the table readers
+	// instead of partitioning spans.
+	spanPartitions []SpanPartition
 }
 
 func (dsp *DistSQLPlanner) planTableReaders(
@@ -1250,7 +1272,9 @@ func (dsp *DistSQLPlanner) planTableReaders(
 		spanPartitions []SpanPartition
 		err            error
 	)
-	if planCtx.isLocal {
+	if info.spanPartitions != nil {
+		spanPartitions = info.spanPartitions
+	} else if planCtx.isLocal {
 		spanPartitions = []SpanPartition{{dsp.gatewayNodeID, info.spans}}
 	} else if info.post.Limit == 0 {
 		// No hard limit - plan all table readers where their data live. Note
@@ -1271,6 +1295,12 @@ func (dsp *DistSQLPlanner) planTableReaders(
 		}
 		spanPartitions = []SpanPartition{{nodeID, info.spans}}
 	}
+	if info.spanPartitions == nil && info.post.Limit == 0 && info.spec.LimitHint == 0 {
+		spanPartitions, err = dsp.maybeParallelizeScan(planCtx, spanPartitions)
+		if err != nil {
+			return err
+		}
+	}
 
 	corePlacement := make([]physicalplan.ProcessorCorePlacement, len(spanPartitions))
 	for i, sp := range spanPartitions {
@@ -1351,6 +1381,98 @@ func (dsp *DistSQLPlanner) planTableReaders(
 	return nil
 }
 
+// maybeParallelizeScan splits the span partitions of a scan without limits in
+// a distributed plan at range boundaries, so that the ranges of a partition
+// are read by several TableReaders on the same node concurrently. The number of additional
+// TableReaders planned for a query is limited by
+// sql.distsql.scan_concurrency_limit. The resulting partitions are ordered
+// such that their union is still ordered by key, so any ordering required from
+// the scan is maintained by merging the output streams.
+func (dsp *DistSQLPlanner) maybeParallelizeScan(
+	planCtx *PlanningCtx, partitions []SpanPartition,
+) ([]SpanPartition, error) {
+	if planCtx.isLocal {
+		// Local plans run in the RootTxn, which doesn't permit concurrency.
+		return partitions, nil
+	}
+	budget := scanConcurrencyLimit.Get(&dsp.st.SV) - planCtx.scanConcurrencyUsed
+	if budget <= 0 {
+		return partitions, nil
+	}
+	res := make([]SpanPartition, 0, len(partitions))
+	for _, p := range partitions {
+		if budget <= 0 {
+			res = append(res, p)
+			continue
+		}
+		pieces, err := dsp.splitSpansAtRangeBoundaries(planCtx, p.Spans)
+		if err != nil {
+			return nil, err
+		}
+		numReaders := int64(len(pieces))
+		if numReaders > budget+1 {
+			numReaders = budget + 1
+		}
+		if numReaders <= 1 {
+			res = append(res, p)
+			continue
+		}
+		// Assign contiguous groups of ranges of roughly equal size to each
+		// reader, merging adjacent pieces back together.
+		for i := int64(0); i < numReaders; i++ {
+			start := i * int64(len(pieces)) / numReaders
+			end := (i + 1) * int64(len(pieces)) / numReaders
+			var spans roachpb.Spans
+			for _, piece := range pieces[start:end] {
+				if n := len(spans); n > 0 && spans[n-1].EndKey.Equal(piece.Key) {
+					spans[n-1].EndKey = piece.EndKey
+					continue
+				}
+				spans = append(spans, piece)
+			}
+			res = append(res, SpanPartition{Node: p.Node, Spans: spans})
+		}
+		budget -= numReaders - 1
+		planCtx.scanConcurrencyUsed += numReaders - 1
+	}
+	return res, nil
+}
+
+// splitSpansAtRangeBoundaries splits the given spans into pieces that are each
+// contained within a single range.
+func (dsp *DistSQLPlanner) splitSpansAtRangeBoundaries(
+	planCtx *PlanningCtx, spans roachpb.Spans,
+) (roachpb.Spans, error) {
+	ctx := planCtx.ctx
+	it := planCtx.spanIter
+	var pieces roachpb.Spans
+	for _, span := range spans {
+		rSpan, err := keys.SpanAddr(span)
+		if err != nil {
+			return nil, err
+		}
+		lastKey := rSpan.Key
+		for it.Seek(ctx, span, kvcoord.Ascending); ; it.Next(ctx) {
+			if !it.Valid() {
+				return nil, it.Error()
+			}
+			endKey := it.Desc().EndKey
+			if rSpan.EndKey.Less(endKey) {
+				endKey = rSpan.EndKey
+			}
+			pieces = append(pieces, roachpb.Span{
+				Key:    lastKey.AsRawKey(),
+				EndKey: endKey.AsRawKey(),
+			})
+			if !endKey.Less(rSpan.EndKey) {
+				break
+			}
+			lastKey = endKey
+		}
+	}
+	return pieces, nil
+}
+
 // selectRenders takes a PhysicalPlan that produces the results corresponding to
 // the select data source (a n.source) and updates it to produce results
 // corresponding to the render node itself. An evaluator stage is added if the
@@ -2470,6 +2592,22 @@ func getTypesForPlanResult(node planNode, planToStreamColMap []int) ([]*types.T,
 func (dsp *DistSQLPlanner) createPlanForJoin(
 	planCtx *PlanningCtx, n *joinNode,
 ) (*PhysicalPlan, error) {
+	if interleavedJoinsEnabled.Get(&dsp.st.SV) {
+		if ij, ok := makeInterleavedJoinInfo(planCtx.EvalContext().Codec, n); ok {
+			if !planCtx.isLocal {
+				plan, ok, err := dsp.createPlanForInterleavedJoin(planCtx, n, ij)
+				if err != nil || ok {
+					return plan, err
+				}
+			}
+			// If the join cannot be distributed, it is performed by a single
+			// scan of the interleaved keyspace on the gateway.
+			if planCtx.planner != nil && canMergeInterleavedScans(n, ij) {
+				return dsp.createPlanForInterleavedScanJoin(planCtx, n, ij)
+			}
+		}
+	}
+
 	leftPlan, err := dsp.createPhysPlanForPlanNode(planCtx, n.left.plan)
 	if err != nil {
 		return nil, err
@@ -2478,7 +2616,18 @@ func (dsp *DistSQLPlanner) createPlanForJoin(
 	if err != nil {
 		return nil, err
 	}
+	info, err := makeJoinPlanningInfo(planCtx, n, leftPlan, rightPlan)
+	if err != nil {
+		return nil, err
+	}
+	return dsp.planJoiners(planCtx, info, n.reqOrdering), nil
+}
 
+// makeJoinPlanningInfo creates the information needed to plan the joiners of
+// the given joinNode on top of the physical plans of its inputs.
+func makeJoinPlanningInfo(
+	planCtx *PlanningCtx, n *joinNode, leftPlan, rightPlan *PhysicalPlan,
+) (*joinPlanningInfo, error) {
 	leftMap, rightMap := leftPlan.PlanToStreamColMap, rightPlan.PlanToStreamColMap
 	helper := &joinPlanningHelper{
 		numLeftOutCols:          n.pred.numLeftCols,
@@ -2526,7 +2675,7 @@ func (dsp *DistSQLPlanner) createPlanForJoin(
 		leftPlanDistribution:  leftPlan.GetLastStageDistribution(),
 		rightPlanDistribution: rightPlan.GetLastStageDistribution(),
 	}
-	return dsp.planJoiners(planCtx, &info, n.reqOrdering), nil
+	return &info, nil
 }
 
 func (dsp *DistSQLPlanner) planJoiners(
@@ -2679,7 +2828,7 @@ func (dsp *DistSQLPlanner) createPhysPlanForPlanNode(
 		}
 
 	case *scanNode:
-		plan, err = dsp.createTableReaders(planCtx, n)
+		plan, err = dsp.createTableReaders(planCtx, n, nil /* spanPartitions */)
 
 	case *sortNode:
 		plan, err = dsp.createPhysPlanForPlanNode(planCtx, n.plan)
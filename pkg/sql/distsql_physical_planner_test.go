@@ -33,6 +33,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsql"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
@@ -45,6 +46,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/testutils/skip"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -887,6 +889,90 @@ func TestPartitionSpans(t *testing.T) {
 	}
 }
 
+// Test that the span partitions of a scan are split at range boundaries among
+// several TableReaders, within the limit of sql.distsql.scan_concurrency_limit.
+func TestMaybeParallelizeScan(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ranges := []testSpanResolverRange{{"A", 1}, {"B", 1}, {"C", 1}, {"D", 2}, {"E", 2}}
+	partitions := []SpanPartition{
+		{Node: 1, Spans: roachpb.Spans{
+			{Key: roachpb.Key("A1"), EndKey: roachpb.Key("B5")},
+			{Key: roachpb.Key("C"), EndKey: roachpb.Key("C5")},
+		}},
+		{Node: 2, Spans: roachpb.Spans{{Key: roachpb.Key("D"), EndKey: roachpb.Key("X")}}},
+	}
+
+	testCases := []struct {
+		limit int64
+		// expected result: the spans of each partition, in order.
+		expected [][][2]string
+	}{
+		{
+			limit: 0,
+			expected: [][][2]string{
+				{{"A1", "B5"}, {"C", "C5"}},
+				{{"D", "X"}},
+			},
+		},
+		{
+			limit: 1,
+			expected: [][][2]string{
+				{{"A1", "B"}},
+				{{"B", "B5"}, {"C", "C5"}},
+				{{"D", "X"}},
+			},
+		},
+		{
+			limit: 10,
+			expected: [][][2]string{
+				{{"A1", "B"}},
+				{{"B", "B5"}},
+				{{"C", "C5"}},
+				{{"D", "E"}},
+				{{"E", "X"}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(strconv.Itoa(int(tc.limit)), func(t *testing.T) {
+			tsp := &testSpanResolver{ranges: ranges}
+			for i := 1; i <= 2; i++ {
+				tsp.nodes = append(tsp.nodes, &roachpb.NodeDescriptor{NodeID: roachpb.NodeID(i)})
+			}
+			st := cluster.MakeTestingClusterSettings()
+			scanConcurrencyLimit.Override(&st.SV, tc.limit)
+			dsp := DistSQLPlanner{
+				planVersion:   execinfra.Version,
+				st:            st,
+				gatewayNodeID: 1,
+				spanResolver:  tsp,
+			}
+			planCtx := dsp.NewPlanningCtx(context.Background(), &extendedEvalContext{
+				EvalContext: tree.EvalContext{Codec: keys.SystemSQLCodec},
+			}, nil /* planner */, nil /* txn */, true /* distribute */)
+
+			res, err := dsp.maybeParallelizeScan(planCtx, partitions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var actual [][][2]string
+			for _, p := range res {
+				var spans [][2]string
+				for _, s := range p.Spans {
+					spans = append(spans, [2]string{string(s.Key), string(s.EndKey)})
+				}
+				actual = append(actual, spans)
+			}
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("expected partitions:\n  %v\ngot:\n  %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
 // Test that span partitioning takes into account the advertised acceptable
 // versions of each node. Spans for which the owner node doesn't support our
 // plan's version will be planned on the gateway.
@@ -1259,3 +1345,171 @@ func TestCheckNodeHealth(t *testing.T) {
 		})
 	}
 }
+
+func TestAlignInterleavedPartitions(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const tableID = 52
+	codec := keys.SystemSQLCodec
+	index := &descpb.IndexDescriptor{ID: 1, ColumnIDs: []descpb.ColumnID{1}}
+	// key returns the key of the row of the root index with the given value
+	// and, if child is non-zero, the key of an interleaved row inside it.
+	key := func(val int64, child int64) roachpb.Key {
+		k := encoding.EncodeVarintAscending(codec.IndexPrefix(tableID, 1), val)
+		if child != 0 {
+			k = encoding.EncodeVarintAscending(k, child)
+		}
+		return k
+	}
+	span := func(start, end roachpb.Key) roachpb.Span {
+		return roachpb.Span{Key: start, EndKey: end}
+	}
+
+	testCases := []struct {
+		name       string
+		partitions []SpanPartition
+		expected   []SpanPartition
+		ok         bool
+	}{
+		{
+			name: "aligned",
+			partitions: []SpanPartition{
+				{Node: 1, Spans: roachpb.Spans{span(key(1, 0), key(5, 0))}},
+				{Node: 2, Spans: roachpb.Spans{span(key(5, 0), key(9, 0))}},
+			},
+			expected: []SpanPartition{
+				{Node: 1, Spans: roachpb.Spans{span(key(1, 0), key(5, 0))}},
+				{Node: 2, Spans: roachpb.Spans{span(key(5, 0), key(9, 0))}},
+			},
+			ok: true,
+		},
+		{
+			name: "split-inside-row",
+			partitions: []SpanPartition{
+				{Node: 2, Spans: roachpb.Spans{span(key(5, 3), key(9, 0))}},
+				{Node: 1, Spans: roachpb.Spans{span(key(1, 0), key(5, 3))}},
+			},
+			expected: []SpanPartition{
+				{Node: 2, Spans: roachpb.Spans{span(key(6, 0), key(9, 0))}},
+				{Node: 1, Spans: roachpb.Spans{span(key(1, 0), key(6, 0))}},
+			},
+			ok: true,
+		},
+		{
+			name: "row-spans-partition",
+			partitions: []SpanPartition{
+				{Node: 1, Spans: roachpb.Spans{span(key(1, 0), key(5, 3))}},
+				{Node: 2, Spans: roachpb.Spans{span(key(5, 3), key(5, 7))}},
+				{Node: 3, Spans: roachpb.Spans{span(key(5, 7), key(9, 0))}},
+			},
+			ok: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ok := alignInterleavedPartitions(codec, tableID, index, tc.partitions)
+			require.Equal(t, tc.ok, ok)
+			if ok {
+				require.Equal(t, tc.expected, tc.partitions)
+			}
+		})
+	}
+}
+
+func TestInterleavedRowSpans(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const tableID = 52
+	codec := keys.SystemSQLCodec
+	index := &descpb.IndexDescriptor{ID: 1, ColumnIDs: []descpb.ColumnID{1}}
+	indexPrefix := codec.IndexPrefix(tableID, 1)
+	row := func(val int64) roachpb.Key {
+		return encoding.EncodeVarintAscending(indexPrefix[:len(indexPrefix):len(indexPrefix)], val)
+	}
+	// family returns the key of the given column family of a row of a table
+	// with multiple column families.
+	family := func(val int64, famID uint32) roachpb.Key {
+		return keys.MakeFamilyKey(row(val), famID)
+	}
+	// sentinel returns the key at which the rows interleaved into a row start.
+	sentinel := func(val int64) roachpb.Key {
+		return encoding.EncodeInterleavedSentinel(row(val))
+	}
+	span := func(start, end roachpb.Key) roachpb.Span {
+		return roachpb.Span{Key: start, EndKey: end}
+	}
+
+	testCases := []struct {
+		name     string
+		spans    roachpb.Spans
+		expected roachpb.Spans
+		ok       bool
+	}{
+		{
+			name:     "full",
+			spans:    roachpb.Spans{span(indexPrefix, indexPrefix.PrefixEnd())},
+			expected: roachpb.Spans{span(indexPrefix, indexPrefix.PrefixEnd())},
+			ok:       true,
+		},
+		{
+			name:     "rows",
+			spans:    roachpb.Spans{span(row(1), row(3)), span(row(5), row(5).PrefixEnd())},
+			expected: roachpb.Spans{span(row(1), row(3)), span(row(5), row(5).PrefixEnd())},
+			ok:       true,
+		},
+		{
+			name:     "before-interleaved-rows",
+			spans:    roachpb.Spans{span(row(1), sentinel(3)), span(row(5), sentinel(5))},
+			expected: roachpb.Spans{span(row(1), row(3).PrefixEnd()), span(row(5), row(5).PrefixEnd())},
+			ok:       true,
+		},
+		{
+			name:  "point",
+			spans: roachpb.Spans{{Key: row(5)}},
+			ok:    false,
+		},
+		{
+			name: "families",
+			spans: roachpb.Spans{
+				span(family(5, 0), family(5, 0).PrefixEnd()),
+				span(family(5, 2), family(5, 2).PrefixEnd()),
+			},
+			ok: false,
+		},
+		{
+			name:  "some-families",
+			spans: roachpb.Spans{span(row(1), sentinel(3)), span(family(5, 0), family(5, 0).PrefixEnd())},
+			ok:    false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spans, ok := interleavedRowSpans(codec, tableID, index, tc.spans)
+			require.Equal(t, tc.ok, ok)
+			if ok {
+				require.Equal(t, tc.expected, spans)
+			}
+		})
+	}
+}
+
+func TestIntersectSpans(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	span := func(start, end string) roachpb.Span {
+		return roachpb.Span{Key: roachpb.Key(start), EndKey: roachpb.Key(end)}
+	}
+	a := roachpb.Spans{span("a", "c"), span("e", "h"), span("j", "k")}
+	b := roachpb.Spans{span("b", "f"), span("g", "j")}
+	res, ok := intersectSpans(a, b)
+	require.True(t, ok)
+	require.Equal(t, roachpb.Spans{span("b", "c"), span("e", "f"), span("g", "h")}, res)
+
+	_, ok = intersectSpans(roachpb.Spans{{Key: roachpb.Key("a")}}, b)
+	require.False(t, ok)
+}
@@ -11,13 +11,20 @@
 package sql
 
 import (
+	"bytes"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
 	"github.com/cockroachdb/cockroach/pkg/sql/physicalplan"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/errors"
@@ -223,3 +230,366 @@ func findJoinProcessorNodes(
 	// data for either source. In the future we should be smarter here.
 	return getNodesOfRouters(append(leftRouters, rightRouters...), processors)
 }
+
+// interleavedJoinsEnabled controls whether joins between an interleaved table
+// and the root of its interleave hierarchy are planned as local joins, or as a
+// single scan of the interleaved keyspace.
+var interleavedJoinsEnabled = settings.RegisterBoolSetting(
+	"sql.distsql.interleaved_joins.enabled",
+	"if set we plan interleaved table joins as local joins on the nodes that "+
+		"store the interleaved rows, or as a single scan of the interleaved rows, "+
+		"instead of redistributing the rows of both tables",
+	true,
+)
+
+// interleavedJoinInfo describes a join between the root table of an interleave
+// hierarchy (the ancestor) and a table interleaved into it (the descendant).
+type interleavedJoinInfo struct {
+	ancestor, descendant *scanNode
+	// ancestorIsLeft is true if the ancestor is the left input of the join.
+	ancestorIsLeft bool
+	// ancestorSpans are the spans of the ancestor scan, extended to cover the
+	// rows interleaved into the ancestor rows (see interleavedRowSpans).
+	ancestorSpans roachpb.Spans
+}
+
+// makeInterleavedJoinInfo determines whether the given join can be planned as
+// an interleaved join. This is the case if:
+//  - both inputs are unlimited forward scans,
+//  - the index of one scan is interleaved into the primary index of the other
+//    scan's table, which is the root of the interleave hierarchy,
+//  - the join has equality on all the columns of the interleave prefix, and
+//  - the join does not output unmatched rows of the descendant, which might
+//    lie outside of the ancestor spans, and
+//  - the ancestor spans cover whole rows of the ancestor, so that they can be
+//    extended to cover the rows interleaved into them.
+// Since all the rows of the descendant that match a row of the ancestor are
+// stored next to that row, such a join can be performed on the nodes that hold
+// the ancestor rows, without redistributing any rows.
+func makeInterleavedJoinInfo(codec keys.SQLCodec, n *joinNode) (interleavedJoinInfo, bool) {
+	var ij interleavedJoinInfo
+	leftScan, ok := n.left.plan.(*scanNode)
+	if !ok {
+		return ij, false
+	}
+	rightScan, ok := n.right.plan.(*scanNode)
+	if !ok {
+		return ij, false
+	}
+	for _, s := range []*scanNode{leftScan, rightScan} {
+		if s.reverse || s.hardLimit != 0 || s.lockingStrength != descpb.ScanLockingStrength_FOR_NONE {
+			return ij, false
+		}
+	}
+
+	ancestorEqIndices, descendantEqIndices := n.pred.leftEqualityIndices, n.pred.rightEqualityIndices
+	switch {
+	case isInterleaveRoot(leftScan, rightScan):
+		ij = interleavedJoinInfo{ancestor: leftScan, descendant: rightScan, ancestorIsLeft: true}
+	case isInterleaveRoot(rightScan, leftScan):
+		ij = interleavedJoinInfo{ancestor: rightScan, descendant: leftScan}
+		ancestorEqIndices, descendantEqIndices = descendantEqIndices, ancestorEqIndices
+	default:
+		return ij, false
+	}
+
+	switch n.pred.joinType {
+	case descpb.InnerJoin, descpb.LeftSemiJoin:
+	case descpb.LeftOuterJoin, descpb.LeftAntiJoin:
+		if !ij.ancestorIsLeft {
+			return ij, false
+		}
+	case descpb.RightOuterJoin:
+		if ij.ancestorIsLeft {
+			return ij, false
+		}
+	default:
+		return ij, false
+	}
+
+	// Each column of the interleave prefix must be equated with the
+	// corresponding column of the descendant index.
+	for i, ancestorColID := range ij.ancestor.index.ColumnIDs {
+		descendantColID := ij.descendant.index.ColumnIDs[i]
+		found := false
+		for j := range ancestorEqIndices {
+			if ij.ancestor.cols[ancestorEqIndices[j]].ID == ancestorColID &&
+				ij.descendant.cols[descendantEqIndices[j]].ID == descendantColID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return ij, false
+		}
+	}
+	ij.ancestorSpans, ok = interleavedRowSpans(
+		codec, ij.ancestor.desc.ID, ij.ancestor.index, ij.ancestor.spans,
+	)
+	return ij, ok
+}
+
+// isInterleaveRoot returns true if the index of the ancestor scan is the root
+// of the interleave hierarchy of the index of the descendant scan.
+func isInterleaveRoot(ancestor, descendant *scanNode) bool {
+	if len(ancestor.index.Interleave.Ancestors) != 0 {
+		return false
+	}
+	ancestors := descendant.index.Interleave.Ancestors
+	if len(ancestors) == 0 {
+		return false
+	}
+	root := &ancestors[0]
+	return root.TableID == ancestor.desc.ID && root.IndexID == ancestor.index.ID &&
+		int(root.SharedPrefixLen) == len(ancestor.index.ColumnIDs)
+}
+
+// createPlanForInterleavedJoin plans the given interleaved join by placing a
+// joiner on each node that holds ancestor rows; the joiner reads the ancestor
+// rows and the descendant rows interleaved into them from local table readers.
+// It returns false if the join cannot be planned this way, in which case the
+// caller should plan a regular join.
+func (dsp *DistSQLPlanner) createPlanForInterleavedJoin(
+	planCtx *PlanningCtx, n *joinNode, ij interleavedJoinInfo,
+) (*PhysicalPlan, bool, error) {
+	ancestorPartitions, err := dsp.PartitionSpans(planCtx, ij.ancestorSpans)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(ancestorPartitions) < 2 {
+		// The regular plan already joins the rows on a single node.
+		return nil, false, nil
+	}
+	if !alignInterleavedPartitions(
+		planCtx.EvalContext().Codec, ij.ancestor.desc.ID, ij.ancestor.index, ancestorPartitions,
+	) {
+		return nil, false, nil
+	}
+	descendantPartitions := make([]SpanPartition, len(ancestorPartitions))
+	for i := range ancestorPartitions {
+		spans, ok := intersectSpans(ij.descendant.spans, ancestorPartitions[i].Spans)
+		if !ok || len(spans) == 0 {
+			return nil, false, nil
+		}
+		descendantPartitions[i] = SpanPartition{Node: ancestorPartitions[i].Node, Spans: spans}
+	}
+
+	ancestorPlan, err := dsp.createTableReaders(planCtx, ij.ancestor, ancestorPartitions)
+	if err != nil {
+		return nil, false, err
+	}
+	descendantPlan, err := dsp.createTableReaders(planCtx, ij.descendant, descendantPartitions)
+	if err != nil {
+		return nil, false, err
+	}
+	leftPlan, rightPlan := ancestorPlan, descendantPlan
+	if !ij.ancestorIsLeft {
+		leftPlan, rightPlan = rightPlan, leftPlan
+	}
+	if !localJoinRoutersMatch(&leftPlan.PhysicalPlan, &rightPlan.PhysicalPlan) {
+		return nil, false, nil
+	}
+	info, err := makeJoinPlanningInfo(planCtx, n, leftPlan, rightPlan)
+	if err != nil {
+		return nil, false, err
+	}
+
+	p := planCtx.NewPhysicalPlan()
+	physicalplan.MergePlans(
+		&p.PhysicalPlan, &info.leftPlan.PhysicalPlan, &info.rightPlan.PhysicalPlan,
+		info.leftPlanDistribution, info.rightPlanDistribution,
+	)
+	p.AddLocalJoinStage(
+		info.makeCoreSpec(), info.post,
+		info.leftPlan.GetResultTypes(), info.rightPlan.GetResultTypes(),
+		info.leftMergeOrd, info.rightMergeOrd,
+		info.leftPlan.ResultRouters, info.rightPlan.ResultRouters, info.joinResultTypes,
+	)
+	p.PlanToStreamColMap = info.joinToStreamColMap
+	p.SetMergeOrdering(dsp.convertOrdering(n.reqOrdering, p.PlanToStreamColMap))
+	telemetry.Inc(sqltelemetry.InterleavedJoinCounter)
+	return p, true, nil
+}
+
+// createPlanForInterleavedScanJoin plans the given interleaved join as an
+// interleavedJoinNode, which performs the join with a single scan of the
+// interleaved keyspace on the gateway.
+func (dsp *DistSQLPlanner) createPlanForInterleavedScanJoin(
+	planCtx *PlanningCtx, n *joinNode, ij interleavedJoinInfo,
+) (*PhysicalPlan, error) {
+	telemetry.Inc(sqltelemetry.InterleavedScanJoinCounter)
+	return dsp.wrapPlan(planCtx, newInterleavedJoinNode(n, ij))
+}
+
+// localJoinRoutersMatch returns true if the result routers of the given plans
+// can be paired up by AddLocalJoinStage, that is if both plans have the same
+// number of result routers and the i-th routers of both plans are on the same
+// node. The table readers planned for the same span partitions always satisfy
+// this, but the plans might have been extended with other stages.
+func localJoinRoutersMatch(left, right *physicalplan.PhysicalPlan) bool {
+	if len(left.ResultRouters) != len(right.ResultRouters) {
+		return false
+	}
+	for i := range left.ResultRouters {
+		if left.Processors[left.ResultRouters[i]].Node != right.Processors[right.ResultRouters[i]].Node {
+			return false
+		}
+	}
+	return true
+}
+
+// alignInterleavedPartitions moves the boundaries between the given partitions
+// of the spans of an interleave root index so that each row of the index ends
+// up in the same partition as all the rows interleaved into it. The spans of
+// each partition remain sorted. It returns false if a boundary cannot be
+// moved, in which case the partitions might have been modified.
+func alignInterleavedPartitions(
+	codec keys.SQLCodec,
+	tableID descpb.ID,
+	index *descpb.IndexDescriptor,
+	partitions []SpanPartition,
+) bool {
+	type piece struct {
+		partition int
+		span      roachpb.Span
+	}
+	var pieces []piece
+	for i := range partitions {
+		for _, sp := range partitions[i].Spans {
+			pieces = append(pieces, piece{partition: i, span: sp})
+		}
+	}
+	sort.Slice(pieces, func(i, j int) bool {
+		return pieces[i].span.Key.Compare(pieces[j].span.Key) < 0
+	})
+
+	for i := 1; i < len(pieces); i++ {
+		prev, cur := &pieces[i-1], &pieces[i]
+		if prev.partition == cur.partition || !prev.span.EndKey.Equal(cur.span.Key) {
+			// Non-adjacent spans come from different scan spans, which never
+			// split a row of the root index.
+			continue
+		}
+		rowEnd, ok := interleaveRowEnd(codec, tableID, index, cur.span.Key)
+		if !ok {
+			return false
+		}
+		if rowEnd == nil {
+			// The boundary is already at the start of a row.
+			continue
+		}
+		if rowEnd.Compare(cur.span.EndKey) >= 0 {
+			return false
+		}
+		prev.span.EndKey = rowEnd
+		cur.span.Key = rowEnd
+	}
+
+	for i := range partitions {
+		partitions[i].Spans = partitions[i].Spans[:0]
+	}
+	for _, p := range pieces {
+		partitions[p.partition].Spans = append(partitions[p.partition].Spans, p.span)
+	}
+	return true
+}
+
+// interleaveRowEnd returns the end of the keyspace of the row of the given
+// interleave root index that contains the given key, including the rows that
+// are interleaved into it. It returns nil if key does not fall strictly inside
+// the keyspace of a row, and false if key cannot be decoded.
+func interleaveRowEnd(
+	codec keys.SQLCodec, tableID descpb.ID, index *descpb.IndexDescriptor, key roachpb.Key,
+) (roachpb.Key, bool) {
+	rest, decodedTableID, decodedIndexID, err := codec.DecodeIndexPrefix(key)
+	if err != nil || descpb.ID(decodedTableID) != tableID || descpb.IndexID(decodedIndexID) != index.ID {
+		return nil, false
+	}
+	for range index.ColumnIDs {
+		if len(rest) == 0 {
+			return nil, true
+		}
+		l, err := encoding.PeekLength(rest)
+		if err != nil {
+			return nil, false
+		}
+		rest = rest[l:]
+	}
+	if len(rest) == 0 {
+		return nil, true
+	}
+	return key[:len(key)-len(rest)].PrefixEnd(), true
+}
+
+// interleavedRowSpans returns the given spans of an interleave root index,
+// extended to cover the rows interleaved into the rows of the index that they
+// read. The spans of a scan of the index end before the interleaved rows of
+// their last row (at the interleave sentinel), so such spans are extended to
+// the end of that row. It returns false if a span starts or ends anywhere else
+// inside the keyspace of a row, which is the case of point spans and of the
+// spans which only read some of the column families of a row: the rows
+// interleaved into such a row cannot be read along with it.
+func interleavedRowSpans(
+	codec keys.SQLCodec, tableID descpb.ID, index *descpb.IndexDescriptor, spans roachpb.Spans,
+) (roachpb.Spans, bool) {
+	indexPrefix := codec.IndexPrefix(uint32(tableID), uint32(index.ID))
+	res := make(roachpb.Spans, len(spans))
+	for i, sp := range spans {
+		if len(sp.EndKey) == 0 {
+			return nil, false
+		}
+		if bytes.HasPrefix(sp.Key, indexPrefix) {
+			if rowEnd, ok := interleaveRowEnd(codec, tableID, index, sp.Key); !ok || rowEnd != nil {
+				return nil, false
+			}
+		}
+		res[i] = sp
+		if !bytes.HasPrefix(sp.EndKey, indexPrefix) {
+			// The key lies outside of the index, so it doesn't split a row.
+			continue
+		}
+		rowEnd, ok := interleaveRowEnd(codec, tableID, index, sp.EndKey)
+		if !ok {
+			return nil, false
+		}
+		if rowEnd == nil {
+			continue
+		}
+		// The span must end with the interleave sentinel right after the
+		// columns of the row.
+		rowPrefix := sp.EndKey[:len(sp.EndKey)-1]
+		if _, isSentinel := encoding.DecodeIfInterleavedSentinel(sp.EndKey[len(rowPrefix):]); !isSentinel ||
+			!rowEnd.Equal(rowPrefix.PrefixEnd()) {
+			return nil, false
+		}
+		res[i].EndKey = rowEnd
+	}
+	return res, true
+}
+
+// intersectSpans returns the intersection of two sorted sets of
+// non-overlapping spans. It returns false if any of the spans is a point span.
+func intersectSpans(a, b roachpb.Spans) (roachpb.Spans, bool) {
+	var res roachpb.Spans
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		if len(a[i].EndKey) == 0 || len(b[j].EndKey) == 0 {
+			return nil, false
+		}
+		start, end := a[i].Key, a[i].EndKey
+		if b[j].Key.Compare(start) > 0 {
+			start = b[j].Key
+		}
+		if b[j].EndKey.Compare(end) < 0 {
+			end = b[j].EndKey
+		}
+		if start.Compare(end) < 0 {
+			res = append(res, roachpb.Span{Key: start, EndKey: end})
+		}
+		if a[i].EndKey.Compare(b[j].EndKey) < 0 {
+			i++
+		} else {
+			j++
+		}
+	}
+	return res, true
+}
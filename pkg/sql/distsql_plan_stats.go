@@ -90,7 +90,7 @@ func (dsp *DistSQLPlanner) createStatsPlan(
 	}
 	scan.isFull = true
 
-	p, err := dsp.createTableReaders(planCtx, &scan)
+	p, err := dsp.createTableReaders(planCtx, &scan, nil /* spanPartitions */)
 	if err != nil {
 		return nil, err
 	}
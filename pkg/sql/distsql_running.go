@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/colflow"
+	"github.com/cockroachdb/cockroach/pkg/sql/contention"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsql"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
@@ -324,6 +325,7 @@ func (dsp *DistSQLPlanner) Run(
 
 	recv.outputTypes = plan.GetResultTypes()
 	recv.contendedQueryMetric = dsp.distSQLSrv.Metrics.ContendedQueriesCount
+	recv.contentionRegistry = dsp.distSQLSrv.ContentionRegistry
 
 	vectorizedThresholdMet := plan.MaxEstimatedRowCount >= evalCtx.SessionData.VectorizeRowCountThreshold
 
@@ -450,6 +452,10 @@ type DistSQLReceiver struct {
 	// contendedQueryMetric is a Counter that is incremented at most once if the
 	// query produces at least one contention event.
 	contendedQueryMetric *metric.Counter
+
+	// contentionRegistry is used to aggregate the contention events observed
+	// by the query, if set.
+	contentionRegistry *contention.Registry
 }
 
 // rowResultWriter is a subset of CommandResult to be used with the
@@ -659,6 +665,16 @@ func (r *DistSQLReceiver) Push(
 			r.contendedQueryMetric.Inc(1)
 			r.contendedQueryMetric = nil
 		}
+		if r.contentionRegistry != nil {
+			for _, ev := range meta.ContentionEvents {
+				if err := r.contentionRegistry.AddContentionEvent(ev); err != nil {
+					// Events on keys that don't belong to a table index can't be
+					// aggregated by the registry, so they are not considered an error
+					// of the query.
+					log.VEventf(r.ctx, 2, "could not record contention event %s: %v", &ev, err)
+				}
+			}
+		}
 		// Release the meta object. It is unsafe for use after this call.
 		meta.Release()
 		return r.status
@@ -745,7 +761,9 @@ var (
 	// indicating an unsupported feature of row count limits was attempted.
 	ErrLimitedResultNotSupported = unimplemented.NewWithIssue(40195, "multiple active portals not supported")
 	// ErrLimitedResultClosed is a sentinel error produced by pgwire
-	// indicating the portal should be closed without error.
+	// indicating the portal should be closed without error. The row
+	// callbacks of InternalExecutor.QueryForEachRowEx also return it to
+	// stop the execution of the statement.
 	ErrLimitedResultClosed = errors.New("row count limit closed")
 )
 
@@ -11,20 +11,25 @@
 package sql
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsql"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
@@ -33,6 +38,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 )
 
@@ -310,3 +317,90 @@ func TestDistSQLReceiverReportsContention(t *testing.T) {
 		}
 	})
 }
+
+// TestDistributedMutationInput verifies that a mutation whose input is read by
+// a distributed flow doesn't commit the transaction in its last batch, and
+// fails with a retryable error if the read timestamp of the transaction moved
+// while it ran.
+func TestDistributedMutationInput(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	// putsWithCommit counts the batches which write to the table with the
+	// prefix tableKey and commit their transaction.
+	var tableKey atomic.Value
+	tableKey.Store(roachpb.Key(nil))
+	var putsWithCommit int64
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			Store: &kvserver.StoreTestingKnobs{
+				TestingRequestFilter: func(_ context.Context, ba roachpb.BatchRequest) *roachpb.Error {
+					prefix := tableKey.Load().(roachpb.Key)
+					if _, ok := ba.GetArg(roachpb.EndTxn); !ok || prefix == nil {
+						return nil
+					}
+					for _, ru := range ba.Requests {
+						if put, ok := ru.GetInner().(*roachpb.PutRequest); ok && bytes.HasPrefix(put.Key, prefix) {
+							atomic.AddInt64(&putsWithCommit, 1)
+							break
+						}
+					}
+					return nil
+				},
+			},
+		},
+	})
+	defer s.Stopper().Stop(ctx)
+
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+	r := sqlutils.MakeSQLRunner(conn)
+	r.Exec(t, `SET distsql = always`)
+	r.Exec(t, `CREATE DATABASE d`)
+	r.Exec(t, `CREATE TABLE d.src (k INT PRIMARY KEY, v INT)`)
+	r.Exec(t, `CREATE TABLE d.dst (k INT PRIMARY KEY, v INT)`)
+	r.Exec(t, `INSERT INTO d.src SELECT i, i FROM generate_series(1, 10) AS g(i)`)
+	var dstID uint32
+	r.QueryRow(t, `SELECT 'd.dst'::regclass::oid::int`).Scan(&dstID)
+	tableKey.Store(keys.SystemSQLCodec.TablePrefix(dstID))
+
+	const upsert = `UPSERT INTO d.dst SELECT * FROM d.src`
+	sv := &s.ClusterSettings().SV
+
+	t.Run("auto-commit", func(t *testing.T) {
+		// By default, the mutation commits the transaction in its last batch.
+		r.Exec(t, upsert)
+		require.Equal(t, int64(1), atomic.SwapInt64(&putsWithCommit, 0))
+
+		// When its input is distributed, it doesn't.
+		distributeMutationReadsClusterMode.Override(sv, true)
+		defer distributeMutationReadsClusterMode.Override(sv, false)
+		r.CheckQueryResults(t,
+			`SELECT info FROM [EXPLAIN `+upsert+`] WHERE info LIKE 'distribution%'`,
+			[][]string{{"distribution: full"}},
+		)
+		r.Exec(t, upsert)
+		require.Equal(t, int64(0), atomic.LoadInt64(&putsWithCommit))
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		distributeMutationReadsClusterMode.Override(sv, true)
+		defer distributeMutationReadsClusterMode.Override(sv, false)
+
+		r.Exec(t, `BEGIN`)
+		defer r.Exec(t, `ROLLBACK`)
+		r.Exec(t, `SELECT count(*) FROM d.src`)
+		// A write committed after the transaction started makes the mutation
+		// hit a WriteTooOld condition, which moves the read timestamp of the
+		// transaction forward when it refreshes.
+		_, err := db.Exec(`UPSERT INTO d.dst VALUES (1, 100)`)
+		require.NoError(t, err)
+		_, err = conn.ExecContext(ctx, upsert)
+		var pqErr *pq.Error
+		require.True(t, errors.As(err, &pqErr), "unexpected error: %v", err)
+		require.Equal(t, pgcode.SerializationFailure, pgcode.MakeCode(string(pqErr.Code)), "%v", err)
+		require.Contains(t, pqErr.Message, "read timestamp changed during mutation with distributed input")
+	})
+}
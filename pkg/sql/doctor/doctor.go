@@ -55,7 +55,8 @@ type NamespaceTable []NamespaceTableRow
 // `system.namespace`.
 type namespaceReverseMap map[int64][]descpb.NameInfo
 
-// JobsTable represents data read from `system.jobs`.
+// JobsTable represents the jobs read from `system.jobs` which are not in a
+// terminal state.
 type JobsTable []jobs.JobMetadata
 
 func newDescGetter(ctx context.Context, rows []DescriptorTableRow) (catalog.MapDescGetter, error) {
@@ -289,7 +290,8 @@ func ExamineDescriptors(
 	return !problemsFound, err
 }
 
-// ExamineJobs runs a suite of consistency checks over the system.jobs table.
+// ExamineJobs runs a suite of consistency checks over the system.jobs table,
+// and over the references to jobs from the descriptor table.
 func ExamineJobs(
 	ctx context.Context,
 	descTable DescriptorTable,
@@ -335,6 +337,27 @@ func ExamineJobs(
 			}
 		}
 	}
+
+	// Every mutation of a table must be owned by a job which has not terminated
+	// yet, otherwise the mutation is orphaned and will never be completed or
+	// rolled back.
+	jobIDs := make(map[int64]struct{}, len(jobsTable))
+	for _, j := range jobsTable {
+		jobIDs[j.ID] = struct{}{}
+	}
+	for _, row := range descTable {
+		table, ok := descGetter[descpb.ID(row.ID)].(catalog.TableDescriptor)
+		if !ok || table.Dropped() {
+			continue
+		}
+		for _, mj := range table.GetMutationJobs() {
+			if _, ok := jobIDs[mj.JobID]; !ok {
+				problemsFound = true
+				fmt.Fprint(stdout, reportMsg(table,
+					"mutation %d refers to job %d which is not running", mj.MutationID, mj.JobID))
+			}
+		}
+	}
 	return !problemsFound, nil
 }
 
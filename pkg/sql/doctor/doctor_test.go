@@ -434,6 +434,33 @@ job 200: schema change GC refers to missing table descriptor(s) [3]
 	job 200 can be safely deleted
 job 300: schema change GC refers to missing table descriptor(s) [3]
 	existing descriptors that still need to be dropped []
+`,
+		},
+		{
+			descTable: doctor.DescriptorTable{
+				{
+					ID: 2,
+					DescBytes: toBytes(t, &descpb.Descriptor{Union: &descpb.Descriptor_Table{
+						Table: &descpb.TableDescriptor{
+							ID:       2,
+							ParentID: 1,
+							Name:     "t",
+							MutationJobs: []descpb.TableDescriptor_MutationJob{
+								{MutationID: 1, JobID: 100},
+								{MutationID: 2, JobID: 200},
+							},
+						},
+					}}),
+				},
+			},
+			jobsTable: doctor.JobsTable{
+				{
+					ID:      100,
+					Payload: &jobspb.Payload{Details: jobspb.WrapPayloadDetails(jobspb.SchemaChangeDetails{})},
+				},
+			},
+			expected: `Examining 1 running jobs...
+   Table   2: ParentID   1, ParentSchemaID 29, Name 't': mutation 2 refers to job 200 which is not running
 `,
 		},
 	}
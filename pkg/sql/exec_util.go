@@ -57,6 +57,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirecancel"
 	"github.com/cockroachdb/cockroach/pkg/sql/physicalplan"
 	"github.com/cockroachdb/cockroach/pkg/sql/querycache"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -250,6 +251,37 @@ var preferLookupJoinsForFKs = settings.RegisterBoolSetting(
 	false,
 )
 
+// distributeMutationReadsClusterMode controls whether the input of mutations
+// can be planned using distributed execution.
+var distributeMutationReadsClusterMode = settings.RegisterBoolSetting(
+	"sql.distsql.distribute_mutation_reads.enabled",
+	"if set, the input of INSERT ... SELECT, UPSERT ... SELECT and UPDATE ... FROM "+
+		"statements can be read using distributed execution; rows are still written by the gateway",
+	false,
+)
+
+// sessionMemLimit is the maximum amount of memory a single session can use.
+// It is read once, when the session's root monitor is created, so changes to
+// it do not affect the sessions that are already open.
+var sessionMemLimit = settings.RegisterByteSizeSetting(
+	"sql.mem.session.max",
+	"maximum amount of memory in bytes a single session can use; 0 means no limit. "+
+		"The limit is read when a session starts, so changes only apply to new sessions",
+	0,
+	settings.NonNegativeInt,
+)
+
+// maxAutoRetries bounds the number of times a transaction is automatically
+// retried by the server after a retryable error.
+var maxAutoRetries = settings.RegisterIntSetting(
+	"sql.txn.max_auto_retries",
+	"maximum number of times a transaction that encountered a retryable error "+
+		"before any of its results were delivered to the client is automatically "+
+		"retried by the server; 0 means no limit",
+	0,
+	settings.NonNegativeInt,
+)
+
 // InterleavedTablesEnabled is the setting that controls whether it's possible
 // to create interleaved indexes or tables.
 var InterleavedTablesEnabled = settings.RegisterBoolSetting(
@@ -345,6 +377,30 @@ var experimentalDistSQLPlanningClusterMode = settings.RegisterEnumSetting(
 	},
 )
 
+// scanConcurrencyLimit is the number of additional TableReaders a single query
+// can plan in order to read the ranges of its scans concurrently.
+var scanConcurrencyLimit = settings.RegisterIntSetting(
+	"sql.distsql.scan_concurrency_limit",
+	"maximum number of additional table readers that a single query can plan "+
+		"to read the ranges of its unlimited scans concurrently; 0 disables "+
+		"the parallelization of scans within a node",
+	0,
+	settings.NonNegativeInt,
+)
+
+// defaultTxnPriorityClusterMode controls the cluster default for the
+// default_transaction_priority session variable.
+var defaultTxnPriorityClusterMode = settings.RegisterEnumSetting(
+	"sql.defaults.default_transaction_priority",
+	"default value for default_transaction_priority session setting",
+	"normal",
+	map[int64]string{
+		int64(tree.Low):    "low",
+		int64(tree.Normal): "normal",
+		int64(tree.High):   "high",
+	},
+)
+
 // VectorizeClusterSettingName is the name for the cluster setting that controls
 // the VectorizeClusterMode below.
 const VectorizeClusterSettingName = "sql.defaults.vectorize"
@@ -476,6 +532,18 @@ var (
 		Measurement: "SQL Statements",
 		Unit:        metric.Unit_COUNT,
 	}
+	MetaMemBudgetExceeded = metric.Metadata{
+		Name:        "sql.mem.budget_exceeded.count",
+		Help:        "Number of statements which failed because they exceeded a memory budget",
+		Measurement: "SQL Statements",
+		Unit:        metric.Unit_COUNT,
+	}
+	MetaTxnAutoRetry = metric.Metadata{
+		Name:        "sql.txn.auto_retry.count",
+		Help:        "Number of automatic retries of SQL transactions",
+		Measurement: "SQL Transactions",
+		Unit:        metric.Unit_COUNT,
+	}
 	MetaSQLTxnLatency = metric.Metadata{
 		Name:        "sql.txn.latency",
 		Help:        "Latency of SQL transactions",
@@ -489,6 +557,39 @@ var (
 		Unit:        metric.Unit_COUNT,
 	}
 
+	// Below are the metadata for the service latencies of the statement types.
+
+	MetaSelectServiceLatency = metric.Metadata{
+		Name:        "sql.select.service.latency",
+		Help:        "Latency of SQL SELECT statement execution",
+		Measurement: "Latency",
+		Unit:        metric.Unit_NANOSECONDS,
+	}
+	MetaUpdateServiceLatency = metric.Metadata{
+		Name:        "sql.update.service.latency",
+		Help:        "Latency of SQL UPDATE statement execution",
+		Measurement: "Latency",
+		Unit:        metric.Unit_NANOSECONDS,
+	}
+	MetaInsertServiceLatency = metric.Metadata{
+		Name:        "sql.insert.service.latency",
+		Help:        "Latency of SQL INSERT statement execution",
+		Measurement: "Latency",
+		Unit:        metric.Unit_NANOSECONDS,
+	}
+	MetaDeleteServiceLatency = metric.Metadata{
+		Name:        "sql.delete.service.latency",
+		Help:        "Latency of SQL DELETE statement execution",
+		Measurement: "Latency",
+		Unit:        metric.Unit_NANOSECONDS,
+	}
+	MetaDdlServiceLatency = metric.Metadata{
+		Name:        "sql.ddl.service.latency",
+		Help:        "Latency of SQL DDL statement execution",
+		Measurement: "Latency",
+		Unit:        metric.Unit_NANOSECONDS,
+	}
+
 	// Below are the metadata for the statement started counters.
 
 	MetaQueryStarted = metric.Metadata{
@@ -1020,6 +1121,49 @@ func getPlanDistribution(
 	return physicalplan.LocalPlan
 }
 
+// getStatementPlanDistribution is like getPlanDistribution, but is used for
+// the main plan of a statement. If sql.distsql.distribute_mutation_reads.enabled
+// is set and the plan performs a mutation, the distribution is determined by
+// the input of the mutation: the mutation itself is always run on the gateway
+// (it gets wrapped), so only its input needs to support distribution.
+func getStatementPlanDistribution(
+	ctx context.Context,
+	p *planner,
+	nodeID *base.SQLIDContainer,
+	distSQLMode sessiondata.DistSQLExecMode,
+	plan planMaybePhysical,
+) physicalplan.PlanDistribution {
+	if !plan.isPhysicalPlan() && distributeMutationReadsClusterMode.Get(&p.execCfg.Settings.SV) {
+		if source, _ := mutationWriter(plan.planNode); source != nil {
+			return getPlanDistribution(ctx, p, nodeID, distSQLMode, planMaybePhysical{planNode: source})
+		}
+	}
+	return getPlanDistribution(ctx, p, nodeID, distSQLMode, plan)
+}
+
+// mutationWriter returns the input and the table writer of the mutation
+// performed by the given plan, if the plan consists of a single INSERT, UPSERT
+// or UPDATE, possibly with a RETURNING clause. It returns nil otherwise.
+func mutationWriter(plan planNode) (source planNode, tw *tableWriterBase) {
+	switch n := plan.(type) {
+	case *rowCountNode:
+		plan = n.source
+	case *serializeNode:
+		plan = n.source
+	default:
+		return nil, nil
+	}
+	switch n := plan.(type) {
+	case *insertNode:
+		return n.source, &n.run.ti.tableWriterBase
+	case *upsertNode:
+		return n.source, &n.run.tw.tableWriterBase
+	case *updateNode:
+		return n.source, &n.run.tu.tableWriterBase
+	}
+	return nil, nil
+}
+
 // golangFillQueryArguments transforms Go values into datums.
 // Some of the args can be datums (in which case the transformation is a no-op).
 //
@@ -1318,6 +1462,13 @@ type SessionArgs struct {
 	// client.
 	RemoteAddr            net.Addr
 	ConnResultsBufferSize int64
+	// Compression is the algorithm used to compress the data sent to the
+	// client, as negotiated with the "crdb:compression" connection parameter.
+	// It is empty if the data is not compressed.
+	Compression string
+	// IgnoredParameters are the connection parameters provided by the client
+	// which do not correspond to any session variable.
+	IgnoredParameters []string
 }
 
 // SessionRegistry stores a set of all sessions on this node.
@@ -1325,29 +1476,48 @@ type SessionArgs struct {
 type SessionRegistry struct {
 	syncutil.Mutex
 	sessions map[ClusterWideID]registrySession
+	// sessionsByCancelKey indexes the sessions by the key that clients use to
+	// cancel their queries with a pgwire CancelRequest.
+	sessionsByCancelKey map[pgwirecancel.BackendKeyData]registrySession
 }
 
 // NewSessionRegistry creates a new SessionRegistry with an empty set
 // of sessions.
 func NewSessionRegistry() *SessionRegistry {
-	return &SessionRegistry{sessions: make(map[ClusterWideID]registrySession)}
+	return &SessionRegistry{
+		sessions:            make(map[ClusterWideID]registrySession),
+		sessionsByCancelKey: make(map[pgwirecancel.BackendKeyData]registrySession),
+	}
 }
 
-func (r *SessionRegistry) register(id ClusterWideID, s registrySession) {
+// register adds a session to the registry. A zero queryCancelKey indicates
+// that the session cannot be canceled with a pgwire CancelRequest.
+func (r *SessionRegistry) register(
+	id ClusterWideID, queryCancelKey pgwirecancel.BackendKeyData, s registrySession,
+) {
 	r.Lock()
 	r.sessions[id] = s
+	if queryCancelKey != 0 {
+		r.sessionsByCancelKey[queryCancelKey] = s
+	}
 	r.Unlock()
 }
 
-func (r *SessionRegistry) deregister(id ClusterWideID) {
+func (r *SessionRegistry) deregister(
+	id ClusterWideID, queryCancelKey pgwirecancel.BackendKeyData,
+) {
 	r.Lock()
 	delete(r.sessions, id)
+	if queryCancelKey != 0 {
+		delete(r.sessionsByCancelKey, queryCancelKey)
+	}
 	r.Unlock()
 }
 
 type registrySession interface {
 	user() security.SQLUsername
 	cancelQuery(queryID ClusterWideID) bool
+	cancelCurrentQueries() bool
 	cancelSession()
 	// serialize serializes a Session into a serverpb.Session
 	// that can be served over RPC.
@@ -1374,6 +1544,24 @@ func (r *SessionRegistry) CancelQuery(queryIDStr string) (bool, error) {
 	return false, fmt.Errorf("query ID %s not found", queryID)
 }
 
+// CancelQueryByKey looks up the session identified by the key of a pgwire
+// CancelRequest and cancels the queries it is currently running. The returned
+// bool is false if no session matches the key or if the session was not
+// running any query.
+//
+// The key acts as the authentication of the request, so no further
+// permission checks are required.
+func (r *SessionRegistry) CancelQueryByKey(queryCancelKey pgwirecancel.BackendKeyData) bool {
+	r.Lock()
+	defer r.Unlock()
+
+	session, ok := r.sessionsByCancelKey[queryCancelKey]
+	if !ok {
+		return false
+	}
+	return session.cancelCurrentQueries()
+}
+
 // CancelSession looks up the specified session in the session registry and
 // cancels it. The caller is responsible for all permission checks.
 func (r *SessionRegistry) CancelSession(
@@ -2192,6 +2380,10 @@ func (m *sessionDataMutator) SetStmtTimeout(timeout time.Duration) {
 	m.data.StmtTimeout = timeout
 }
 
+func (m *sessionDataMutator) SetLockTimeout(timeout time.Duration) {
+	m.data.LockTimeout = timeout
+}
+
 func (m *sessionDataMutator) SetIdleInSessionTimeout(timeout time.Duration) {
 	m.data.IdleInSessionTimeout = timeout
 }
@@ -2251,6 +2443,12 @@ func (m *sessionDataMutator) RecordLatestSequenceVal(seqID uint32, val int64) {
 	m.data.SequenceState.RecordValue(seqID, val)
 }
 
+// ResetSequenceState discards the sequence values cached by the session, as
+// used by currval and lastval.
+func (m *sessionDataMutator) ResetSequenceState() {
+	m.data.SequenceState = sessiondata.NewSequenceState()
+}
+
 // SetNoticeDisplaySeverity sets the NoticeDisplaySeverity for the given session.
 func (m *sessionDataMutator) SetNoticeDisplaySeverity(severity pgnotice.DisplaySeverity) {
 	m.data.NoticeDisplaySeverity = severity
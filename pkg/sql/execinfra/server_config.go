@@ -30,6 +30,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/hydratedtables"
+	"github.com/cockroachdb/cockroach/pkg/sql/contention"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlliveness"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
@@ -48,6 +49,15 @@ var SettingWorkMemBytes = settings.RegisterByteSizeSetting(
 	64*1024*1024, /* 64MB */
 )
 
+// SettingQueryMemBytes is the maximum amount of memory a single flow, i.e. a
+// single query on a single node, can use.
+var SettingQueryMemBytes = settings.RegisterByteSizeSetting(
+	"sql.mem.query.max",
+	"maximum amount of memory in bytes a single query can use on each node; 0 means no limit",
+	0,
+	settings.NonNegativeInt,
+)
+
 // ServerConfig encompasses the configuration required to create a
 // DistSQLServer.
 type ServerConfig struct {
@@ -110,6 +120,10 @@ type ServerConfig struct {
 
 	Metrics *DistSQLMetrics
 
+	// ContentionRegistry aggregates the contention events observed by the
+	// queries for which this node is the gateway.
+	ContentionRegistry *contention.Registry
+
 	// SQLLivenessReader provides access to reading the liveness of sessions.
 	SQLLivenessReader sqlliveness.Reader
 
@@ -133,6 +133,14 @@ type EngineMetrics struct {
 	SQLTxnLatency         *metric.Histogram
 	SQLTxnsOpen           *metric.Gauge
 
+	// The service latencies of the basic CRUD and DDL statements. The other
+	// statements are only included in SQLServiceLatency.
+	SQLSelectServiceLatency *metric.Histogram
+	SQLUpdateServiceLatency *metric.Histogram
+	SQLInsertServiceLatency *metric.Histogram
+	SQLDeleteServiceLatency *metric.Histogram
+	SQLDdlServiceLatency    *metric.Histogram
+
 	// TxnAbortCount counts transactions that were aborted, either due
 	// to non-retriable errors, or retriable errors when the client-side
 	// retry protocol is not in use.
@@ -140,6 +148,13 @@ type EngineMetrics struct {
 
 	// FailureCount counts non-retriable errors in open transactions.
 	FailureCount *metric.Counter
+
+	// MemBudgetExceededCount counts the failures of FailureCount which were
+	// caused by a memory budget being exceeded.
+	MemBudgetExceededCount *metric.Counter
+
+	// TxnAutoRetryCount counts the automatic retries of transactions.
+	TxnAutoRetryCount *metric.Counter
 }
 
 // EngineMetrics implements the metric.Struct interface
@@ -148,6 +163,25 @@ var _ metric.Struct = EngineMetrics{}
 // MetricStruct is part of the metric.Struct interface.
 func (EngineMetrics) MetricStruct() {}
 
+// serviceLatencyForStmt returns the histogram tracking the service latency of
+// the type of the given statement, or nil if there is none.
+func (m *EngineMetrics) serviceLatencyForStmt(stmt tree.Statement) *metric.Histogram {
+	switch stmt.(type) {
+	case *tree.Select:
+		return m.SQLSelectServiceLatency
+	case *tree.Update:
+		return m.SQLUpdateServiceLatency
+	case *tree.Insert:
+		return m.SQLInsertServiceLatency
+	case *tree.Delete:
+		return m.SQLDeleteServiceLatency
+	}
+	if tree.CanModifySchema(stmt) {
+		return m.SQLDdlServiceLatency
+	}
+	return nil
+}
+
 // recordStatementSummery gathers various details pertaining to the
 // last executed statement/query and performs the associated
 // accounting in the passed-in EngineMetrics.
@@ -194,6 +228,9 @@ func (ex *connExecutor) recordStatementSummary(
 		}
 		m.SQLExecLatency.RecordValue(runLatRaw.Nanoseconds())
 		m.SQLServiceLatency.RecordValue(svcLatRaw.Nanoseconds())
+		if h := m.serviceLatencyForStmt(stmt.AST); h != nil {
+			h.RecordValue(svcLatRaw.Nanoseconds())
+		}
 	}
 
 	stmtID := ex.statsCollector.recordStatement(
@@ -52,7 +52,7 @@ func (e *explainPlanNode) startExec(params runParams) error {
 	// Determine the "distribution" and "vectorized" values, which we will emit as
 	// special rows.
 
-	distribution := getPlanDistribution(
+	distribution := getStatementPlanDistribution(
 		params.ctx, params.p, params.extendedEvalCtx.ExecCfg.NodeID,
 		params.extendedEvalCtx.SessionData.DistSQLMode, plan.main,
 	)
@@ -12,11 +12,13 @@ package sql_test
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/tests"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 )
@@ -198,3 +200,50 @@ func TestStatementReuses(t *testing.T) {
 		}
 	})
 }
+
+func TestExplainOptMemo(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	params, _ := tests.CreateTestServerParams()
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.Background())
+	r := sqlutils.MakeSQLRunner(db)
+	r.Exec(t, `CREATE TABLE abc (a INT PRIMARY KEY, b INT, c INT, INDEX (b))`)
+
+	checkTrace := func(t *testing.T, rows [][]string) {
+		var out strings.Builder
+		for _, row := range rows {
+			out.WriteString(row[0])
+			out.WriteString("\n")
+		}
+		for _, expected := range []string{
+			"scan abc@abc_b_idx",
+			"rules applied (",
+			"GenerateConstrainedScans",
+			"memo (optimized",
+		} {
+			if !strings.Contains(out.String(), expected) {
+				t.Errorf("expected %q in output:\n%s", expected, out.String())
+			}
+		}
+	}
+
+	const query = `EXPLAIN (OPT, MEMO) SELECT a FROM abc WHERE b = 1`
+	t.Run("statement", func(t *testing.T) {
+		// Run the statement twice so that the second execution could find a
+		// cached memo.
+		checkTrace(t, r.QueryStr(t, query))
+		checkTrace(t, r.QueryStr(t, query))
+	})
+	t.Run("prepared", func(t *testing.T) {
+		r.Exec(t, `PREPARE p AS `+query)
+		defer r.Exec(t, `DEALLOCATE p`)
+		checkTrace(t, r.QueryStr(t, `EXECUTE p`))
+		checkTrace(t, r.QueryStr(t, `EXECUTE p`))
+	})
+	t.Run("data source", func(t *testing.T) {
+		r.ExpectErr(t, "the MEMO flag is only supported for top-level EXPLAIN statements",
+			`SELECT * FROM [`+query+`]`)
+	})
+}
@@ -464,6 +464,7 @@ func (f *FlowBase) cancel() {
 	}
 	f.flowRegistry.Lock()
 	timedOutReceivers := f.flowRegistry.cancelPendingStreamsLocked(f.ID)
+	f.flowRegistry.recordCanceledFlowLocked(f.ID)
 	f.flowRegistry.Unlock()
 
 	for _, receiver := range timedOutReceivers {
@@ -99,6 +99,9 @@ type flowEntry struct {
 	// streamTimer is a timer that fires after a timeout and verifies that all
 	// inbound streams have been connected.
 	streamTimer *time.Timer
+
+	// streamTimeout is the timeout with which the flow was registered.
+	streamTimeout time.Duration
 }
 
 // FlowRegistry allows clients to look up flows by ID and to wait for flows to
@@ -110,6 +113,14 @@ type FlowRegistry struct {
 	// except flow, whose methods can be called freely.
 	flows map[execinfrapb.FlowID]*flowEntry
 
+	// canceledFlows contains the IDs of the flows that were canceled while some
+	// of their inbound streams were not connected yet, mapped to the time until
+	// which producers might still attempt to connect these streams. Such
+	// attempts fail immediately instead of waiting for the flow to be
+	// registered, so that the remote flows feeding a canceled flow (e.g. a flow
+	// of a query canceled through CANCEL QUERY) are canceled promptly.
+	canceledFlows map[execinfrapb.FlowID]time.Time
+
 	// draining specifies whether the FlowRegistry is in drain mode. If it is,
 	// the FlowRegistry will not accept new flows.
 	draining bool
@@ -128,7 +139,10 @@ type FlowRegistry struct {
 // instID is the ID of the current node. Used for debugging; pass 0 if you don't
 // care.
 func NewFlowRegistry(instID base.SQLInstanceID) *FlowRegistry {
-	fr := &FlowRegistry{flows: make(map[execinfrapb.FlowID]*flowEntry)}
+	fr := &FlowRegistry{
+		flows:         make(map[execinfrapb.FlowID]*flowEntry),
+		canceledFlows: make(map[execinfrapb.FlowID]time.Time),
+	}
 	fr.flowDone = sync.NewCond(fr)
 	return fr
 }
@@ -229,6 +243,7 @@ func (fr *FlowRegistry) RegisterFlow(
 	entry.refCount++
 	entry.flow = f
 	entry.inboundStreams = inboundStreams
+	entry.streamTimeout = timeout
 	// If there are any waiters, wake them up by closing waitCh.
 	if entry.waitCh != nil {
 		close(entry.waitCh)
@@ -291,6 +306,30 @@ func (fr *FlowRegistry) cancelPendingStreamsLocked(id execinfrapb.FlowID) []Inbo
 	return pendingReceivers
 }
 
+// recordCanceledFlowLocked remembers that the flow with the given id was
+// canceled, if any of its inbound streams were canceled before being
+// connected. Subsequent ConnectInboundStream calls for these streams fail
+// immediately, even after the flow is unregistered. It should only be called
+// while holding the mutex.
+func (fr *FlowRegistry) recordCanceledFlowLocked(id execinfrapb.FlowID) {
+	now := timeutil.Now()
+	for fid, expiry := range fr.canceledFlows {
+		if now.After(expiry) {
+			delete(fr.canceledFlows, fid)
+		}
+	}
+	entry := fr.flows[id]
+	if entry == nil || entry.flow == nil {
+		return
+	}
+	for _, is := range entry.inboundStreams {
+		if is.canceled && !is.connected {
+			fr.canceledFlows[id] = now.Add(entry.streamTimeout)
+			return
+		}
+	}
+}
+
 // UnregisterFlow removes a flow from the registry. Any subsequent
 // ConnectInboundStream calls for the flow will fail to find it and time out.
 func (fr *FlowRegistry) UnregisterFlow(id execinfrapb.FlowID) {
@@ -476,6 +515,13 @@ func (fr *FlowRegistry) ConnectInboundStream(
 	fr.Lock()
 	defer fr.Unlock()
 
+	if _, ok := fr.flows[flowID]; !ok {
+		if expiry, ok := fr.canceledFlows[flowID]; ok && timeutil.Now().Before(expiry) {
+			// The consumer was canceled and unregistered; there is no point in
+			// waiting for it.
+			return nil, nil, nil, errors.Errorf("flow %s: consumer canceled", flowID)
+		}
+	}
 	entry := fr.getEntryLocked(flowID)
 	if entry.flow == nil {
 		// Send the handshake message informing the producer that the consumer has
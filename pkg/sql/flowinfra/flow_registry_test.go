@@ -664,3 +664,51 @@ func TestFlowCancelPartiallyBlocked(t *testing.T) {
 		t.Fatal("expected query canceled, found", meta.Err)
 	}
 }
+
+// TestConnectToCanceledFlow tests that a producer connecting to a flow that was
+// canceled and unregistered before the producer's stream was connected fails
+// immediately, instead of waiting for the flow to be registered.
+func TestConnectToCanceledFlow(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	fr := NewFlowRegistry(0)
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	consumer := &distsqlutils.RowBuffer{}
+	inboundStreams := map[execinfrapb.StreamID]*InboundStreamInfo{
+		0: {receiver: RowInboundStreamHandler{consumer}, waitGroup: wg},
+	}
+	flow := &FlowBase{
+		FlowCtx: execinfra.FlowCtx{
+			ID: execinfrapb.FlowID{UUID: uuid.FastMakeV4()},
+		},
+		inboundStreams: inboundStreams,
+		flowRegistry:   fr,
+	}
+	if err := fr.RegisterFlow(
+		ctx, flow.ID, flow, inboundStreams, 10*time.Second, /* timeout */
+	); err != nil {
+		t.Fatal(err)
+	}
+	flow.cancel()
+	fr.UnregisterFlow(flow.ID)
+
+	serverStream, _ /* clientStream */, cleanup, err := createDummyStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	// Use a timeout longer than the test timeout; the connection attempt must
+	// not wait for it.
+	_, _, _, err = fr.ConnectInboundStream(ctx, flow.ID, 0 /* streamID */, serverStream, time.Hour)
+	if !testutils.IsError(err, "consumer canceled") {
+		t.Fatalf("expected %q, got: %v", "consumer canceled", err)
+	}
+	fr.Lock()
+	defer fr.Unlock()
+	if _, ok := fr.flows[flow.ID]; ok {
+		t.Fatal("expected no flow entry to be left behind")
+	}
+}
@@ -33,6 +33,14 @@ var settingMaxRunningFlows = settings.RegisterIntSetting(
 	500,
 ).WithPublic()
 
+var settingMaxRunningFlowsPerUser = settings.RegisterIntSetting(
+	"sql.distsql.max_running_flows_per_user",
+	"maximum number of concurrent flows that can be run on a node on behalf of "+
+		"a single user; flows over the limit are queued. 0 means no limit",
+	0,
+	settings.NonNegativeInt,
+)
+
 // FlowScheduler manages running flows and decides when to queue and when to
 // start flows. The main interface it presents is ScheduleFlows, which passes a
 // flow to be run.
@@ -45,11 +53,14 @@ type FlowScheduler struct {
 	mu struct {
 		syncutil.Mutex
 		queue *list.List
+		// runningByUser counts the running flows of each user.
+		runningByUser map[string]int32
 	}
 
 	atomics struct {
-		numRunning      int32
-		maxRunningFlows int32
+		numRunning             int32
+		maxRunningFlows        int32
+		maxRunningFlowsPerUser int32
 	}
 }
 
@@ -59,6 +70,7 @@ type FlowScheduler struct {
 type flowWithCtx struct {
 	ctx         context.Context
 	flow        Flow
+	user        string
 	enqueueTime time.Time
 }
 
@@ -76,18 +88,59 @@ func NewFlowScheduler(
 		metrics:        metrics,
 	}
 	fs.mu.queue = list.New()
+	fs.mu.runningByUser = make(map[string]int32)
 	fs.atomics.maxRunningFlows = int32(settingMaxRunningFlows.Get(&settings.SV))
 	settingMaxRunningFlows.SetOnChange(&settings.SV, func() {
 		atomic.StoreInt32(&fs.atomics.maxRunningFlows, int32(settingMaxRunningFlows.Get(&settings.SV)))
 	})
+	fs.atomics.maxRunningFlowsPerUser = int32(settingMaxRunningFlowsPerUser.Get(&settings.SV))
+	settingMaxRunningFlowsPerUser.SetOnChange(&settings.SV, func() {
+		atomic.StoreInt32(
+			&fs.atomics.maxRunningFlowsPerUser, int32(settingMaxRunningFlowsPerUser.Get(&settings.SV)),
+		)
+	})
 	return fs
 }
 
-// canRunFlow returns whether the FlowScheduler can run the flow. If true is
-// returned, numRunning is also incremented.
+// flowUser returns the name of the user on behalf of whom the flow runs, or
+// the empty string if it is unknown.
+func flowUser(f Flow) string {
+	flowCtx := f.GetFlowCtx()
+	if flowCtx == nil || flowCtx.EvalCtx == nil || flowCtx.EvalCtx.SessionData == nil {
+		return ""
+	}
+	return flowCtx.EvalCtx.SessionData.User().Normalized()
+}
+
+// userCanRunFlowLocked returns whether the given user is below the limit on
+// the number of running flows per user.
+func (fs *FlowScheduler) userCanRunFlowLocked(user string) bool {
+	limit := atomic.LoadInt32(&fs.atomics.maxRunningFlowsPerUser)
+	return limit <= 0 || user == "" || fs.mu.runningByUser[user] < limit
+}
+
+// canRunFlow returns whether the FlowScheduler can run a flow of the given
+// user. If true is returned, numRunning is also incremented, as is the number
+// of running flows of the user if it is not empty. The mutex is only acquired
+// in the latter case.
 // TODO(radu): we will have more complex resource accounting (like memory).
 //  For now we just limit the number of concurrent flows.
-func (fs *FlowScheduler) canRunFlow(_ Flow) bool {
+func (fs *FlowScheduler) canRunFlow(user string) bool {
+	if user == "" {
+		return fs.reserveRunningSlot()
+	}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if !fs.userCanRunFlowLocked(user) || !fs.reserveRunningSlot() {
+		return false
+	}
+	fs.mu.runningByUser[user]++
+	return true
+}
+
+// reserveRunningSlot increments numRunning if the FlowScheduler is below the
+// limit on the number of running flows and returns whether it did so.
+func (fs *FlowScheduler) reserveRunningSlot() bool {
 	// Optimistically increase numRunning to account for this new flow.
 	newNumRunning := atomic.AddInt32(&fs.atomics.numRunning, 1)
 	if newNumRunning <= atomic.LoadInt32(&fs.atomics.maxRunningFlows) {
@@ -99,14 +152,20 @@ func (fs *FlowScheduler) canRunFlow(_ Flow) bool {
 	return false
 }
 
-// runFlowNow starts the given flow; does not wait for the flow to complete. The
-// caller is responsible for incrementing numRunning.
-func (fs *FlowScheduler) runFlowNow(ctx context.Context, f Flow) error {
+// runFlowNow starts the given flow; does not wait for the flow to complete.
+// The caller is responsible for incrementing numRunning and, if user is not
+// empty, the number of running flows of the user. The mutex must not be held,
+// since it is acquired once the flow is done.
+func (fs *FlowScheduler) runFlowNow(ctx context.Context, f Flow, user string) error {
 	log.VEventf(
 		ctx, 1, "flow scheduler running flow %s, currently running %d", f.GetID(), atomic.LoadInt32(&fs.atomics.numRunning)-1,
 	)
 	fs.metrics.FlowStart()
-	if err := f.Start(ctx, func() { fs.flowDoneCh <- f }); err != nil {
+	if err := f.Start(ctx, func() {
+		fs.userFlowDone(user)
+		fs.flowDoneCh <- f
+	}); err != nil {
+		fs.userFlowDone(user)
 		return err
 	}
 	// TODO(radu): we could replace the WaitGroup with a structure that keeps a
@@ -118,6 +177,19 @@ func (fs *FlowScheduler) runFlowNow(ctx context.Context, f Flow) error {
 	return nil
 }
 
+// userFlowDone decrements the number of running flows of the given user, if
+// it is not empty.
+func (fs *FlowScheduler) userFlowDone(user string) {
+	if user == "" {
+		return
+	}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if fs.mu.runningByUser[user]--; fs.mu.runningByUser[user] <= 0 {
+		delete(fs.mu.runningByUser, user)
+	}
+}
+
 // ScheduleFlow is the main interface of the flow scheduler: it runs or enqueues
 // the given flow.
 //
@@ -126,8 +198,14 @@ func (fs *FlowScheduler) runFlowNow(ctx context.Context, f Flow) error {
 func (fs *FlowScheduler) ScheduleFlow(ctx context.Context, f Flow) error {
 	return fs.stopper.RunTaskWithErr(
 		ctx, "flowinfra.FlowScheduler: scheduling flow", func(ctx context.Context) error {
-			if fs.canRunFlow(f) {
-				return fs.runFlowNow(ctx, f)
+			// The running flows of each user are only tracked when their number is
+			// limited.
+			var user string
+			if atomic.LoadInt32(&fs.atomics.maxRunningFlowsPerUser) > 0 {
+				user = flowUser(f)
+			}
+			if fs.canRunFlow(user) {
+				return fs.runFlowNow(ctx, f, user)
 			}
 			fs.mu.Lock()
 			defer fs.mu.Unlock()
@@ -136,6 +214,7 @@ func (fs *FlowScheduler) ScheduleFlow(ctx context.Context, f Flow) error {
 			fs.mu.queue.PushBack(&flowWithCtx{
 				ctx:         ctx,
 				flow:        f,
+				user:        user,
 				enqueueTime: timeutil.Now(),
 			})
 			return nil
@@ -167,7 +246,17 @@ func (fs *FlowScheduler) Start() {
 				decrementNumRunning := stopped
 				fs.metrics.FlowStop()
 				if !stopped {
-					if frElem := fs.mu.queue.Front(); frElem != nil {
+					// Run the first queued flow whose user is not over the per-user
+					// limit. Flows of other users can be run ahead of the flows of a
+					// user that has too many flows running.
+					var frElem *list.Element
+					for e := fs.mu.queue.Front(); e != nil; e = e.Next() {
+						if fs.userCanRunFlowLocked(e.Value.(*flowWithCtx).user) {
+							frElem = e
+							break
+						}
+					}
+					if frElem != nil {
 						n := frElem.Value.(*flowWithCtx)
 						fs.mu.queue.Remove(frElem)
 						wait := timeutil.Since(n.enqueueTime)
@@ -179,7 +268,15 @@ func (fs *FlowScheduler) Start() {
 						// Note: we use the flow's context instead of the worker
 						// context, to ensure that logging etc is relative to the
 						// specific flow.
-						if err := fs.runFlowNow(n.ctx, n.flow); err != nil {
+						if n.user != "" {
+							fs.mu.runningByUser[n.user]++
+						}
+						// The flow is started without holding the mutex, which is acquired
+						// once the flow is done.
+						fs.mu.Unlock()
+						err := fs.runFlowNow(n.ctx, n.flow, n.user)
+						fs.mu.Lock()
+						if err != nil {
 							log.Errorf(n.ctx, "error starting queued flow: %s", err)
 						}
 					} else {
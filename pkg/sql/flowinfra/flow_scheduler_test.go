@@ -17,9 +17,12 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -38,6 +41,8 @@ type mockFlow struct {
 	// doneCb is set when a caller calls Start and is executed at the end of the
 	// Wait method.
 	doneCb func()
+	// flowCtx is returned by GetFlowCtx.
+	flowCtx execinfra.FlowCtx
 }
 
 var _ Flow = &mockFlow{}
@@ -46,6 +51,15 @@ func newMockFlow() *mockFlow {
 	return &mockFlow{runCh: make(chan struct{}), doneCh: make(chan struct{})}
 }
 
+// newMockFlowForUser returns a mockFlow running on behalf of the given user.
+func newMockFlowForUser(user string) *mockFlow {
+	m := newMockFlow()
+	sd := &sessiondata.SessionData{}
+	sd.UserProto = security.MakeSQLUsernameFromPreNormalizedString(user).EncodeProto()
+	m.flowCtx.EvalCtx = &tree.EvalContext{SessionData: sd}
+	return m
+}
+
 func (m *mockFlow) Setup(
 	_ context.Context, _ *execinfrapb.FlowSpec, _ FuseOpt,
 ) (context.Context, error) {
@@ -83,7 +97,7 @@ func (m *mockFlow) IsVectorized() bool {
 }
 
 func (m *mockFlow) GetFlowCtx() *execinfra.FlowCtx {
-	panic("not implemented")
+	return &m.flowCtx
 }
 
 func (m *mockFlow) AddStartable(_ Startable) {
@@ -140,3 +154,57 @@ func TestFlowScheduler(t *testing.T) {
 		return nil
 	})
 }
+
+func TestFlowSchedulerPerUserLimit(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var (
+		ctx      = context.Background()
+		stopper  = stop.NewStopper()
+		settings = cluster.MakeTestingClusterSettings()
+		metrics  = execinfra.MakeDistSQLMetrics(base.DefaultHistogramWindowInterval())
+	)
+	defer stopper.Stop(ctx)
+
+	settingMaxRunningFlowsPerUser.Override(&settings.SV, 1)
+	scheduler := NewFlowScheduler(log.AmbientContext{}, stopper, settings, &metrics)
+	scheduler.Start()
+	scheduler.atomics.maxRunningFlows = 2
+	getNumRunning := func() int {
+		return int(atomic.LoadInt32(&scheduler.atomics.numRunning))
+	}
+
+	flowA1 := newMockFlowForUser("a")
+	require.NoError(t, scheduler.ScheduleFlow(ctx, flowA1))
+	<-flowA1.runCh
+	require.Equal(t, 1, getNumRunning())
+
+	// The second flow of user a is queued even though the node can run another
+	// flow.
+	flowA2 := newMockFlowForUser("a")
+	require.NoError(t, scheduler.ScheduleFlow(ctx, flowA2))
+	require.Equal(t, 1, getNumRunning())
+
+	// A flow of user b can run.
+	flowB1 := newMockFlowForUser("b")
+	require.NoError(t, scheduler.ScheduleFlow(ctx, flowB1))
+	<-flowB1.runCh
+	require.Equal(t, 2, getNumRunning())
+
+	// Once flowA1 finishes, flowA2 can run.
+	close(flowA1.doneCh)
+	<-flowA2.runCh
+	require.Equal(t, 2, getNumRunning())
+
+	close(flowA2.doneCh)
+	close(flowB1.doneCh)
+	testutils.SucceedsSoon(t, func() error {
+		if getNumRunning() != 0 {
+			return errors.New("expected numRunning to fall back to 0")
+		}
+		return nil
+	})
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+	require.Empty(t, scheduler.mu.runningByUser)
+}
@@ -17,6 +17,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 	"unicode/utf8"
 
 	"github.com/cockroachdb/cockroach/pkg/docs"
@@ -32,6 +33,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
@@ -813,7 +815,7 @@ CREATE TABLE information_schema.key_column_usage (
 // Postgres: https://www.postgresql.org/docs/9.6/static/infoschema-parameters.html
 // MySQL:    https://dev.mysql.com/doc/refman/5.7/en/parameters-table.html
 var informationSchemaParametersTable = virtualSchemaTable{
-	comment: `built-in function parameters (empty - introspection not yet supported)
+	comment: `built-in function parameters
 https://www.postgresql.org/docs/9.5/infoschema-parameters.html`,
 	schema: `
 CREATE TABLE information_schema.parameters (
@@ -851,7 +853,71 @@ CREATE TABLE information_schema.parameters (
 	PARAMETER_DEFAULT STRING
 )`,
 	populate: func(ctx context.Context, p *planner, dbContext *dbdesc.Immutable, addRow func(...tree.Datum) error) error {
-		return nil
+		h := makeOidHasher()
+		return forEachDatabaseDesc(ctx, p, dbContext, false, /* requiresPrivileges */
+			func(db *dbdesc.Immutable) error {
+				dbNameStr := tree.NewDString(db.GetName())
+				return forEachBuiltinOverload(func(name string, _ *tree.FunctionProperties, overload *tree.Overload) error {
+					scName, fnName := splitBuiltinName(name)
+					specificName := builtinSpecificName(h, name, fnName, overload)
+					addParam := func(pos int, paramName string, typ *types.T) error {
+						return addRow(
+							dbNameStr,                    // specific_catalog
+							tree.NewDString(scName),      // specific_schema
+							specificName,                 // specific_name
+							tree.NewDInt(tree.DInt(pos)), // ordinal_position
+							tree.NewDString("IN"),        // parameter_mode
+							noString,                     // is_result
+							noString,                     // as_locator
+							dNameOrNull(paramName),       // parameter_name
+							tree.NewDString(typ.InformationSchemaName()), // data_type
+							characterMaximumLength(typ),                  // character_maximum_length
+							characterOctetLength(typ),                    // character_octet_length
+							tree.DNull,                                   // character_set_catalog
+							tree.DNull,                                   // character_set_schema
+							tree.DNull,                                   // character_set_name
+							tree.DNull,                                   // collation_catalog
+							tree.DNull,                                   // collation_schema
+							tree.DNull,                                   // collation_name
+							numericPrecision(typ),                        // numeric_precision
+							numericPrecisionRadix(typ),                   // numeric_precision_radix
+							numericScale(typ),                            // numeric_scale
+							datetimePrecision(typ),                       // datetime_precision
+							tree.DNull,                                   // interval_type
+							tree.DNull,                                   // interval_precision
+							dbNameStr,                                    // udt_catalog
+							pgCatalogNameDString,                         // udt_schema
+							tree.NewDString(typ.PGName()),                // udt_name
+							tree.DNull,                                   // scope_catalog
+							tree.DNull,                                   // scope_schema
+							tree.DNull,                                   // scope_name
+							tree.DNull,                                   // maximum_cardinality
+							tree.NewDString(strconv.Itoa(pos)),           // dtd_identifier
+							tree.DNull,                                   // parameter_default
+						)
+					}
+					switch args := overload.Types.(type) {
+					case tree.ArgTypes:
+						for i, arg := range args {
+							if err := addParam(i+1, arg.Name, arg.Typ); err != nil {
+								return err
+							}
+						}
+					case tree.VariadicType:
+						for i, typ := range args.FixedTypes {
+							if err := addParam(i+1, "", typ); err != nil {
+								return err
+							}
+						}
+						return addParam(len(args.FixedTypes)+1, "", args.VarType)
+					case tree.HomogeneousType:
+						// Like in PostgreSQL, functions accepting any number of arguments
+						// of any type are described with a single parameter.
+						return addParam(1, "", types.Any)
+					}
+					return nil
+				})
+			})
 	},
 }
 
@@ -933,12 +999,17 @@ CREATE TABLE information_schema.referential_constraints (
 				if err != nil {
 					return err
 				}
+				// The referenced table is not necessarily in the same schema.
+				refTableName, err := getParentAsTableName(tableLookup, fk.ReferencedTableID, "" /* dbPrefix */)
+				if err != nil {
+					return err
+				}
 				return addRow(
 					dbNameStr,                                // constraint_catalog
 					scNameStr,                                // constraint_schema
 					tree.NewDString(fk.Name),                 // constraint_name
-					dbNameStr,                                // unique_constraint_catalog
-					scNameStr,                                // unique_constraint_schema
+					tree.NewDString(refTableName.Catalog()),  // unique_constraint_catalog
+					tree.NewDString(refTableName.Schema()),   // unique_constraint_schema
 					tree.NewDString(refConstraint.GetName()), // unique_constraint_name
 					matchType,                                // match_option
 					dStringForFKAction(fk.OnUpdate),          // update_rule
@@ -976,7 +1047,7 @@ CREATE TABLE information_schema.role_table_grants (
 
 // MySQL:    https://dev.mysql.com/doc/mysql-infoschema-excerpt/5.7/en/routines-table.html
 var informationSchemaRoutineTable = virtualSchemaTable{
-	comment: `built-in functions (empty - introspection not yet supported)
+	comment: `built-in functions
 https://www.postgresql.org/docs/9.5/infoschema-routines.html`,
 	schema: `
 CREATE TABLE information_schema.routines (
@@ -1063,10 +1134,155 @@ CREATE TABLE information_schema.routines (
 	RESULT_CAST_DTD_IDENTIFIER STRING
 )`,
 	populate: func(ctx context.Context, p *planner, dbContext *dbdesc.Immutable, addRow func(...tree.Datum) error) error {
-		return nil
+		h := makeOidHasher()
+		return forEachDatabaseDesc(ctx, p, dbContext, false, /* requiresPrivileges */
+			func(db *dbdesc.Immutable) error {
+				dbNameStr := tree.NewDString(db.GetName())
+				return forEachBuiltinOverload(func(name string, props *tree.FunctionProperties, overload *tree.Overload) error {
+					scName, fnName := splitBuiltinName(name)
+					scNameStr := tree.NewDString(scName)
+					fnNameStr := tree.NewDString(fnName)
+					retType := builtinReturnType(overload)
+					return addRow(
+						dbNameStr, // specific_catalog
+						scNameStr, // specific_schema
+						builtinSpecificName(h, name, fnName, overload), // specific_name
+						dbNameStr,                   // routine_catalog
+						scNameStr,                   // routine_schema
+						fnNameStr,                   // routine_name
+						tree.NewDString("FUNCTION"), // routine_type
+						tree.DNull,                  // module_catalog
+						tree.DNull,                  // module_schema
+						tree.DNull,                  // module_name
+						tree.DNull,                  // udt_catalog
+						tree.DNull,                  // udt_schema
+						tree.DNull,                  // udt_name
+						tree.NewDString(retType.InformationSchemaName()), // data_type
+						characterMaximumLength(retType),                  // character_maximum_length
+						characterOctetLength(retType),                    // character_octet_length
+						tree.DNull,                                       // character_set_catalog
+						tree.DNull,                                       // character_set_schema
+						tree.DNull,                                       // character_set_name
+						tree.DNull,                                       // collation_catalog
+						tree.DNull,                                       // collation_schema
+						tree.DNull,                                       // collation_name
+						numericPrecision(retType),                        // numeric_precision
+						numericPrecisionRadix(retType),                   // numeric_precision_radix
+						numericScale(retType),                            // numeric_scale
+						datetimePrecision(retType),                       // datetime_precision
+						tree.DNull,                                       // interval_type
+						tree.DNull,                                       // interval_precision
+						dbNameStr,                                        // type_udt_catalog
+						pgCatalogNameDString,                             // type_udt_schema
+						tree.NewDString(retType.PGName()),                // type_udt_name
+						tree.DNull,                                       // scope_catalog
+						tree.DNull,                                       // scope_name
+						tree.DNull,                                       // maximum_cardinality
+						tree.NewDString("0"),                             // dtd_identifier
+						tree.NewDString("EXTERNAL"),                      // routine_body
+						tree.DNull,                                       // routine_definition
+						fnNameStr,                                        // external_name
+						tree.NewDString("INTERNAL"),                      // external_language
+						tree.NewDString("GENERAL"),                       // parameter_style
+						yesOrNoDatum(overload.Volatility <= tree.VolatilityImmutable), // is_deterministic
+						tree.NewDString("MODIFIES"),                                   // sql_data_access
+						yesOrNoDatum(!props.NullableArgs),                             // is_null_call
+						tree.DNull,                                                    // sql_path
+						yesString,                                                     // schema_level_routine
+						tree.NewDInt(0),                                               // max_dynamic_result_sets
+						noString,                                                      // is_user_defined_cast
+						noString,                                                      // is_implicitly_invocable
+						tree.NewDString("INVOKER"),                                    // security_type
+						tree.DNull,                                                    // to_sql_specific_catalog
+						tree.DNull,                                                    // to_sql_specific_schema
+						tree.DNull,                                                    // to_sql_specific_name
+						noString,                                                      // as_locator
+						tree.DNull,                                                    // created
+						tree.DNull,                                                    // last_altered
+						tree.DNull,                                                    // new_savepoint_level
+						noString,                                                      // is_udt_dependent
+						tree.DNull,                                                    // result_cast_from_data_type
+						tree.DNull,                                                    // result_cast_as_locator
+						tree.DNull,                                                    // result_cast_char_max_length
+						tree.DNull,                                                    // result_cast_char_octet_length
+						tree.DNull,                                                    // result_cast_char_set_catalog
+						tree.DNull,                                                    // result_cast_char_set_schema
+						tree.DNull,                                                    // result_cast_char_set_name
+						tree.DNull,                                                    // result_cast_collation_catalog
+						tree.DNull,                                                    // result_cast_collation_schema
+						tree.DNull,                                                    // result_cast_collation_name
+						tree.DNull,                                                    // result_cast_numeric_precision
+						tree.DNull,                                                    // result_cast_numeric_precision_radix
+						tree.DNull,                                                    // result_cast_numeric_scale
+						tree.DNull,                                                    // result_cast_datetime_precision
+						tree.DNull,                                                    // result_cast_interval_type
+						tree.DNull,                                                    // result_cast_interval_precision
+						tree.DNull,                                                    // result_cast_type_udt_catalog
+						tree.DNull,                                                    // result_cast_type_udt_schema
+						tree.DNull,                                                    // result_cast_type_udt_name
+						tree.DNull,                                                    // result_cast_scope_catalog
+						tree.DNull,                                                    // result_cast_scope_schema
+						tree.DNull,                                                    // result_cast_scope_name
+						tree.DNull,                                                    // result_cast_maximum_cardinality
+						tree.DNull,                                                    // result_cast_dtd_identifier
+					)
+				})
+			})
 	},
 }
 
+// forEachBuiltinOverload calls fn for each overload of each built-in function.
+func forEachBuiltinOverload(
+	fn func(name string, props *tree.FunctionProperties, overload *tree.Overload) error,
+) error {
+	for _, name := range builtins.AllBuiltinNames {
+		// AllBuiltinNames contains duplicate uppercase and lowercase names.
+		// Only use the lowercase ones for compatibility with postgres.
+		if r, _ := utf8.DecodeRuneInString(name); unicode.IsUpper(r) {
+			continue
+		}
+		props, overloads := builtins.GetBuiltinProperties(name)
+		for i := range overloads {
+			if err := fn(name, props, &overloads[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// splitBuiltinName returns the schema and the name of a built-in function,
+// e.g. "crdb_internal" and "force_error" for "crdb_internal.force_error".
+// Functions whose name is not qualified are in pg_catalog.
+func splitBuiltinName(name string) (scName, fnName string) {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return pgCatalogName, name
+}
+
+// builtinSpecificName returns the name that identifies an overload of a
+// built-in function. Like in PostgreSQL, it is made of the name of the
+// function and the OID of the overload in pg_proc.
+func builtinSpecificName(h oidHasher, name, fnName string, overload *tree.Overload) tree.Datum {
+	return tree.NewDString(fmt.Sprintf("%s_%d", fnName, h.BuiltinOid(name, overload).DInt))
+}
+
+// builtinReturnType returns the type of the values returned by an overload of
+// a built-in function. The functions returning a set of rows with a single
+// column are described with the type of the column, like in pg_proc.
+func builtinReturnType(overload *tree.Overload) *types.T {
+	retType := overload.FixedReturnType()
+	if retType == nil {
+		return types.Any
+	}
+	if retType.Family() == types.TupleFamily && overload.Generator != nil &&
+		len(retType.TupleContents()) == 1 {
+		return retType.TupleContents()[0]
+	}
+	return retType
+}
+
 // MySQL:    https://dev.mysql.com/doc/refman/5.7/en/schemata-table.html
 var informationSchemaSchemataTable = virtualSchemaTable{
 	comment: `database schemas (may contain schemata without permission)
@@ -0,0 +1,264 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// interleavedJoinNode performs a join between the root table of an interleave
+// hierarchy and a table interleaved into it as a single scan of the
+// interleaved keyspace, which replaces the scans of both tables and the join
+// between them.
+//
+// The rows of the descendant that match a row of the ancestor are stored
+// right after that row, so the scan of the ancestor spans returns each
+// ancestor row followed by the descendant rows that can match it. The node
+// joins each descendant row with the last ancestor row, and checks the
+// equality columns since the descendant rows without a matching ancestor row
+// are interleaved in the same keyspace.
+//
+// It is only planned for inner joins and for outer joins which preserve the
+// rows of the ancestor (see canMergeInterleavedScans), which don't output the
+// descendant rows that lie outside of the ancestor spans.
+type interleavedJoinNode struct {
+	ancestor, descendant *scanNode
+	// ancestorIsLeft is true if the ancestor is the left input of the join.
+	ancestorIsLeft bool
+	// ancestorSpans are the spans of the ancestor scan, extended to cover the
+	// rows interleaved into the ancestor rows.
+	ancestorSpans roachpb.Spans
+
+	// ancestorEqCols and descendantEqCols are the equality columns of the join
+	// in the ancestor and descendant rows.
+	ancestorEqCols, descendantEqCols []int
+
+	pred    *joinPredicate
+	columns colinfo.ResultColumns
+
+	run interleavedJoinRun
+}
+
+type interleavedJoinRun struct {
+	fetcher row.Fetcher
+
+	// ancestorRow is the last ancestor row that was read, if haveAncestor is
+	// set, and matched is set once it was joined with a descendant row.
+	ancestorRow  tree.Datums
+	haveAncestor bool
+	matched      bool
+
+	output tree.Datums
+	done   bool
+}
+
+// newInterleavedJoinNode creates an interleavedJoinNode for the given join,
+// which must satisfy canMergeInterleavedScans.
+func newInterleavedJoinNode(n *joinNode, ij interleavedJoinInfo) *interleavedJoinNode {
+	ancestorEqCols, descendantEqCols := n.pred.leftEqualityIndices, n.pred.rightEqualityIndices
+	if !ij.ancestorIsLeft {
+		ancestorEqCols, descendantEqCols = descendantEqCols, ancestorEqCols
+	}
+	node := &interleavedJoinNode{
+		ancestor:         ij.ancestor,
+		descendant:       ij.descendant,
+		ancestorIsLeft:   ij.ancestorIsLeft,
+		ancestorSpans:    ij.ancestorSpans,
+		ancestorEqCols:   make([]int, len(ancestorEqCols)),
+		descendantEqCols: make([]int, len(descendantEqCols)),
+		pred:             n.pred,
+		columns:          n.columns,
+	}
+	for i := range ancestorEqCols {
+		node.ancestorEqCols[i] = int(ancestorEqCols[i])
+		node.descendantEqCols[i] = int(descendantEqCols[i])
+	}
+	return node
+}
+
+// canMergeInterleavedScans returns true if the given interleaved join can be
+// performed by an interleavedJoinNode.
+func canMergeInterleavedScans(n *joinNode, ij interleavedJoinInfo) bool {
+	switch n.pred.joinType {
+	case descpb.InnerJoin:
+	case descpb.LeftOuterJoin, descpb.RightOuterJoin:
+		// makeInterleavedJoinInfo only allows the outer joins which preserve the
+		// rows of the ancestor.
+	default:
+		return false
+	}
+	// The rows are produced in the order of the interleaved keyspace, which is
+	// not described by a ReqOrdering.
+	if len(n.reqOrdering) > 0 {
+		return false
+	}
+	for _, s := range []*scanNode{ij.ancestor, ij.descendant} {
+		if s.isCheck || s.containsSystemColumns || s.colCfg.virtualColumn != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// fetcherTableArgs returns the arguments of a row.Fetcher that produces the
+// rows of the given scan within the given spans.
+func fetcherTableArgs(s *scanNode, spans roachpb.Spans) row.FetcherTableArgs {
+	args := row.FetcherTableArgs{
+		Spans:            spans,
+		Desc:             s.desc,
+		Index:            s.index,
+		IsSecondaryIndex: s.index.ID != s.desc.GetPrimaryIndexID(),
+		Cols:             make([]descpb.ColumnDescriptor, len(s.cols)),
+	}
+	for i, col := range s.cols {
+		args.Cols[i] = *col
+		args.ColIdxMap.Set(col.ID, i)
+		args.ValNeededForCol.Add(i)
+	}
+	return args
+}
+
+func (n *interleavedJoinNode) startExec(params runParams) error {
+	if err := n.run.fetcher.Init(
+		params.ctx,
+		params.ExecCfg().Codec,
+		false, /* reverse */
+		descpb.ScanLockingStrength_FOR_NONE,
+		descpb.ScanLockingWaitPolicy_BLOCK,
+		false, /* isCheck */
+		params.p.alloc,
+		nil, /* memMonitor */
+		fetcherTableArgs(n.ancestor, n.ancestorSpans),
+		fetcherTableArgs(n.descendant, n.descendant.spans),
+	); err != nil {
+		return err
+	}
+	n.run.ancestorRow = make(tree.Datums, len(n.ancestor.cols))
+	n.run.output = make(tree.Datums, len(n.columns))
+	// The ancestor spans cover the interleaved descendant rows that can match
+	// the ancestor rows.
+	return n.run.fetcher.StartScan(
+		params.ctx,
+		params.p.txn,
+		n.ancestorSpans,
+		true, /* limitBatches */
+		0,    /* limitHint */
+		params.p.ExtendedEvalContext().Tracing.KVTracingEnabled(),
+		params.EvalContext().TestingKnobs.ForceProductionBatchSizes,
+	)
+}
+
+// Next is part of the planNode interface.
+func (n *interleavedJoinNode) Next(params runParams) (bool, error) {
+	for !n.run.done {
+		if err := params.p.cancelChecker.Check(); err != nil {
+			return false, err
+		}
+		datums, table, index, err := n.run.fetcher.NextRowDecoded(params.ctx)
+		if err != nil {
+			return false, err
+		}
+		if datums == nil {
+			n.run.done = true
+			return n.maybeOutputUnmatchedAncestor(), nil
+		}
+
+		if isScanOf(n.ancestor, table, index) {
+			// The previous ancestor row cannot be matched anymore.
+			unmatched := n.maybeOutputUnmatchedAncestor()
+			copy(n.run.ancestorRow, datums)
+			n.run.haveAncestor = true
+			n.run.matched = false
+			if unmatched {
+				return true, nil
+			}
+			continue
+		}
+
+		if !n.run.haveAncestor {
+			continue
+		}
+		ok, err := n.matches(params.EvalContext(), datums)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			continue
+		}
+		n.run.matched = true
+		left, right := n.run.ancestorRow, datums
+		if !n.ancestorIsLeft {
+			left, right = right, left
+		}
+		n.pred.prepareRow(n.run.output, left, right)
+		return true, nil
+	}
+	return false, nil
+}
+
+// matches returns true if the given descendant row matches the last ancestor
+// row.
+func (n *interleavedJoinNode) matches(
+	evalCtx *tree.EvalContext, descendantRow tree.Datums,
+) (bool, error) {
+	for i := range n.ancestorEqCols {
+		a, d := n.run.ancestorRow[n.ancestorEqCols[i]], descendantRow[n.descendantEqCols[i]]
+		if a == tree.DNull || d == tree.DNull || a.Compare(evalCtx, d) != 0 {
+			return false, nil
+		}
+	}
+	if n.ancestorIsLeft {
+		return n.pred.eval(evalCtx, n.run.ancestorRow, descendantRow)
+	}
+	return n.pred.eval(evalCtx, descendantRow, n.run.ancestorRow)
+}
+
+// maybeOutputUnmatchedAncestor prepares the output row for the last ancestor
+// row if it wasn't matched and the join is an outer join, in which case it
+// returns true.
+func (n *interleavedJoinNode) maybeOutputUnmatchedAncestor() bool {
+	if n.pred.joinType == descpb.InnerJoin || !n.run.haveAncestor || n.run.matched {
+		return false
+	}
+	ancestorStart := 0
+	if !n.ancestorIsLeft {
+		ancestorStart = n.pred.numLeftCols
+	}
+	for i := range n.run.output {
+		n.run.output[i] = tree.DNull
+	}
+	copy(n.run.output[ancestorStart:], n.run.ancestorRow)
+	n.run.matched = true
+	return true
+}
+
+// isScanOf returns true if the given table and index are read by the scan.
+func isScanOf(s *scanNode, table catalog.TableDescriptor, index *descpb.IndexDescriptor) bool {
+	return table.GetID() == s.desc.ID && index.ID == s.index.ID
+}
+
+// Values is part of the planNode interface.
+func (n *interleavedJoinNode) Values() tree.Datums {
+	return n.run.output
+}
+
+// Close is part of the planNode interface.
+func (n *interleavedJoinNode) Close(ctx context.Context) {
+	// The scans are closed with the joinNode which this node replaces.
+	n.run.fetcher.Close(ctx)
+}
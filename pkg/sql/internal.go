@@ -115,11 +115,13 @@ func (ie *InternalExecutor) initConnEx(
 	ctx context.Context,
 	txn *kv.Txn,
 	sd *sessiondata.SessionData,
+	rowFn func(context.Context, tree.Datums) error,
 	syncCallback func([]resWithPos),
 	errCallback func(error),
 ) (*StmtBuf, *sync.WaitGroup, error) {
 	clientComm := &internalClientComm{
-		sync: syncCallback,
+		rowFn: rowFn,
+		sync:  syncCallback,
 		// init lastDelivered below the position of the first result (0).
 		lastDelivered: -1,
 	}
@@ -232,6 +234,27 @@ func (ie *InternalExecutor) QueryWithCols(
 	return ie.queryInternal(ctx, opName, txn, session, stmt, qargs...)
 }
 
+// QueryForEachRowEx is like QueryWithCols, except that the rows are not
+// buffered: rowFn is called with each row as soon as it is produced, and
+// the row is only valid for the duration of the call. If rowFn returns
+// ErrLimitedResultClosed, the execution of the statement stops early and
+// succeeds. Any other error fails the statement.
+func (ie *InternalExecutor) QueryForEachRowEx(
+	ctx context.Context,
+	opName string,
+	txn *kv.Txn,
+	session sessiondata.InternalExecutorOverride,
+	rowFn func(context.Context, tree.Datums) error,
+	stmt string,
+	qargs ...interface{},
+) (colinfo.ResultColumns, error) {
+	res, err := ie.execInternal(ctx, opName, txn, session, rowFn, stmt, qargs...)
+	if err != nil {
+		return nil, err
+	}
+	return res.cols, res.err
+}
+
 func (ie *InternalExecutor) queryInternal(
 	ctx context.Context,
 	opName string,
@@ -240,7 +263,7 @@ func (ie *InternalExecutor) queryInternal(
 	stmt string,
 	qargs ...interface{},
 ) ([]tree.Datums, colinfo.ResultColumns, error) {
-	res, err := ie.execInternal(ctx, opName, txn, sessionDataOverride, stmt, qargs...)
+	res, err := ie.execInternal(ctx, opName, txn, sessionDataOverride, nil /* rowFn */, stmt, qargs...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -311,7 +334,7 @@ func (ie *InternalExecutor) ExecEx(
 	stmt string,
 	qargs ...interface{},
 ) (int, error) {
-	res, err := ie.execInternal(ctx, opName, txn, session, stmt, qargs...)
+	res, err := ie.execInternal(ctx, opName, txn, session, nil /* rowFn */, stmt, qargs...)
 	if err != nil {
 		return 0, err
 	}
@@ -368,11 +391,14 @@ func (ie *InternalExecutor) maybeRootSessionDataOverride(
 // sessionDataOverride can be used to control select fields in the executor's
 // session data. It overrides what has been previously set through
 // SetSessionData(), if anything.
+//
+// rowFn, if set, is called with the result rows instead of buffering them.
 func (ie *InternalExecutor) execInternal(
 	ctx context.Context,
 	opName string,
 	txn *kv.Txn,
 	sessionDataOverride sessiondata.InternalExecutorOverride,
+	rowFn func(context.Context, tree.Datums) error,
 	stmt string,
 	qargs ...interface{},
 ) (retRes result, retErr error) {
@@ -448,7 +474,7 @@ func (ie *InternalExecutor) execInternal(
 		}
 		resCh <- result{err: err}
 	}
-	stmtBuf, wg, err := ie.initConnEx(ctx, txn, sd, syncCallback, errCallback)
+	stmtBuf, wg, err := ie.initConnEx(ctx, txn, sd, rowFn, syncCallback, errCallback)
 	if err != nil {
 		return result{}, err
 	}
@@ -520,6 +546,10 @@ type internalClientComm struct {
 	// sync, if set, is called whenever a Sync is executed. It returns all the
 	// results since the previous Sync.
 	sync func([]resWithPos)
+
+	// rowFn, if set, is passed the rows of the results instead of buffering
+	// them.
+	rowFn func(context.Context, tree.Datums) error
 }
 
 var _ ClientComm = &internalClientComm{}
@@ -548,6 +578,7 @@ func (icc *internalClientComm) CreateStatementResult(
 // closed.
 func (icc *internalClientComm) createRes(pos CmdPos, onClose func(error)) *bufferedCommandResult {
 	res := &bufferedCommandResult{
+		rowFn: icc.rowFn,
 		closeCallback: func(res *bufferedCommandResult, typ resCloseType, err error) {
 			if typ == discarded {
 				return
@@ -590,7 +621,7 @@ func (icc *internalClientComm) LockCommunication() ClientLock {
 }
 
 // Flush is part of the ClientComm interface.
-func (icc *internalClientComm) Flush(pos CmdPos) error {
+func (icc *internalClientComm) Flush(ctx context.Context, pos CmdPos) error {
 	return nil
 }
 
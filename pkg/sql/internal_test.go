@@ -34,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -114,6 +115,53 @@ func TestInternalExecutor(t *testing.T) {
 	}
 }
 
+func TestInternalExecutorQueryForEachRow(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	params, _ := tests.CreateTestServerParams()
+	s, _, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(ctx)
+
+	ie := s.InternalExecutor().(*sql.InternalExecutor)
+	override := sessiondata.InternalExecutorOverride{User: security.RootUserName()}
+
+	// The rows are passed to the callback.
+	var rows []int64
+	cols, err := ie.QueryForEachRowEx(ctx, "test", nil /* txn */, override,
+		func(_ context.Context, row tree.Datums) error {
+			rows = append(rows, int64(tree.MustBeDInt(row[0])))
+			return nil
+		}, "SELECT generate_series(1, $1) AS x", 3)
+	require.NoError(t, err)
+	require.Len(t, cols, 1)
+	require.Equal(t, "x", cols[0].Name)
+	require.Equal(t, []int64{1, 2, 3}, rows)
+
+	// The execution of a statement which would not terminate stops early,
+	// without an error.
+	rows = nil
+	_, err = ie.QueryForEachRowEx(ctx, "test", nil /* txn */, override,
+		func(_ context.Context, row tree.Datums) error {
+			if len(rows) == 10 {
+				return sql.ErrLimitedResultClosed
+			}
+			rows = append(rows, int64(tree.MustBeDInt(row[0])))
+			return nil
+		}, "SELECT generate_series(1, 1 << 62)")
+	require.NoError(t, err)
+	require.Len(t, rows, 10)
+
+	// Other errors fail the statement.
+	_, err = ie.QueryForEachRowEx(ctx, "test", nil /* txn */, override,
+		func(_ context.Context, row tree.Datums) error {
+			return errors.New("boom")
+		}, "SELECT 1")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "boom")
+}
+
 func TestInternalFullTableScan(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -308,6 +308,67 @@ func TestAbortCountErrorDuringTransaction(t *testing.T) {
 	}
 }
 
+func TestMemBudgetExceededCount(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	params, _ := tests.CreateTestServerParams()
+	s, sqlDB, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.Background())
+
+	if _, err := sqlDB.Exec(`
+SET CLUSTER SETTING sql.distsql.temp_storage.workmem = '200KB';
+CREATE TABLE l (a INT PRIMARY KEY);
+INSERT INTO l SELECT g FROM generate_series(0, 10000) g(g);
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	failures := s.MustGetSQLCounter(sql.MetaFailure.Name)
+	exceeded := s.MustGetSQLCounter(sql.MetaMemBudgetExceeded.Name)
+
+	if _, err := sqlDB.Exec("SELECT array_agg(a) OVER () FROM l LIMIT 1"); !testutils.IsError(
+		err, "memory budget exceeded",
+	) {
+		t.Fatalf("expected memory budget exceeded error, got %v", err)
+	}
+	if _, err := checkCounterDelta(s, sql.MetaFailure, failures, 1); err != nil {
+		t.Error(err)
+	}
+	if _, err := checkCounterDelta(s, sql.MetaMemBudgetExceeded, exceeded, 1); err != nil {
+		t.Error(err)
+	}
+
+	// Other failures are not counted.
+	if _, err := sqlDB.Exec("SELECT 1/0"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if _, err := checkCounterDelta(s, sql.MetaFailure, failures, 2); err != nil {
+		t.Error(err)
+	}
+	if _, err := checkCounterDelta(s, sql.MetaMemBudgetExceeded, exceeded, 1); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTxnAutoRetryCount(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	params, _ := tests.CreateTestServerParams()
+	s, sqlDB, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.Background())
+
+	retries := s.MustGetSQLCounter(sql.MetaTxnAutoRetry.Name)
+	// force_retry returns a retriable error until the transaction is older than
+	// the given interval, which causes the implicit transaction to be retried
+	// automatically.
+	if _, err := sqlDB.Exec("SELECT crdb_internal.force_retry('50ms':::INTERVAL)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkCounterGE(s, sql.MetaTxnAutoRetry, retries+1); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestSavepointMetrics(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
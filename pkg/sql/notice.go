@@ -16,6 +16,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 )
 
@@ -38,13 +39,8 @@ func (p *planner) BufferClientNotice(ctx context.Context, notice pgnotice.Notice
 	if log.V(2) {
 		log.Infof(ctx, "buffered notice: %+v", notice)
 	}
-	noticeSeverity, ok := pgnotice.ParseDisplaySeverity(pgerror.GetSeverity(notice))
-	if !ok {
-		noticeSeverity = pgnotice.DisplaySeverityNotice
-	}
 	if p.noticeSender == nil ||
-		noticeSeverity > p.SessionData().NoticeDisplaySeverity ||
-		!NoticesEnabled.Get(&p.execCfg.Settings.SV) {
+		!shouldSendNotice(&p.execCfg.Settings.SV, p.SessionData(), notice) {
 		// Notice cannot flow to the client - because of one of these conditions:
 		// * there is no client
 		// * the session's NoticeDisplaySeverity is higher than the severity of the notice.
@@ -53,3 +49,16 @@ func (p *planner) BufferClientNotice(ctx context.Context, notice pgnotice.Notice
 	}
 	p.noticeSender.BufferNotice(notice)
 }
+
+// shouldSendNotice returns false if the notice protocol was disabled or if
+// the session's NoticeDisplaySeverity is higher than the severity of the
+// notice.
+func shouldSendNotice(
+	sv *settings.Values, sd *sessiondata.SessionData, notice pgnotice.Notice,
+) bool {
+	noticeSeverity, ok := pgnotice.ParseDisplaySeverity(pgerror.GetSeverity(notice))
+	if !ok {
+		noticeSeverity = pgnotice.DisplaySeverityNotice
+	}
+	return noticeSeverity <= sd.NoticeDisplaySeverity && NoticesEnabled.Get(sv)
+}
@@ -0,0 +1,503 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/span"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// This file implements LISTEN, UNLISTEN and NOTIFY.
+//
+// A session registers interest in a channel with LISTEN. NOTIFY inserts a row
+// into system.notifications within the notifying transaction, so that the
+// notification is only published if the transaction commits. Every node runs
+// a rangefeed on system.notifications and queues the notifications it sees
+// for the sessions connected to it which listen on the channel. Like in
+// PostgreSQL, LISTEN and UNLISTEN also only take effect at commit time, and
+// notifications are sent to the client in between transactions, right before
+// ReadyForQuery.
+//
+// The rows of system.notifications are deleted periodically, once the
+// notifications have had time to be delivered.
+
+// Notification is an asynchronous notification generated by NOTIFY.
+type Notification struct {
+	// Channel is the name of the channel the notification was sent on.
+	Channel string
+	// Payload is the payload of the notification; empty if none was given.
+	Payload string
+	// PID identifies the backend that generated the notification. It is
+	// always zero: the process ID sent in the BackendKeyData message is half of
+	// the key that allows cancelling the queries of the session, and must not
+	// be revealed to other sessions.
+	PID int32
+}
+
+// maxNotificationPayloadLength is the maximum length of the payload of a
+// notification. It is the same as PostgreSQL's default.
+const maxNotificationPayloadLength = 8000
+
+// maxPendingNotifications is the maximum number of notifications that can be
+// queued for a session which has not consumed them yet. Further notifications
+// are dropped.
+const maxPendingNotifications = 10000
+
+// notificationRetention is how long the rows of system.notifications are kept
+// before they are deleted. The rangefeeds deliver the notifications as soon as
+// they are committed, so this only needs to cover the lag of the rangefeeds.
+const notificationRetention = 10 * time.Minute
+
+// notificationGCInterval is the interval at which the expired rows of
+// system.notifications are deleted.
+const notificationGCInterval = time.Minute
+
+// The IDs of the columns of system.notifications which are decoded from the
+// rangefeed events.
+const (
+	notificationsChannelColID descpb.ColumnID = 3
+	notificationsPayloadColID descpb.ColumnID = 4
+)
+
+// notificationRegistry keeps track of the sessions listening on each channel
+// on this node.
+type notificationRegistry struct {
+	mu struct {
+		syncutil.Mutex
+		// channels maps each channel to the listeners registered on it.
+		channels map[string]map[*notificationListener]struct{}
+	}
+}
+
+func newNotificationRegistry() *notificationRegistry {
+	r := &notificationRegistry{}
+	r.mu.channels = make(map[string]map[*notificationListener]struct{})
+	return r
+}
+
+// listen registers the listener on the given channel.
+func (r *notificationRegistry) listen(channel string, l *notificationListener) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	listeners, ok := r.mu.channels[channel]
+	if !ok {
+		listeners = make(map[*notificationListener]struct{})
+		r.mu.channels[channel] = listeners
+	}
+	listeners[l] = struct{}{}
+	l.channels[channel] = struct{}{}
+}
+
+// unlisten removes the listener from the given channel.
+func (r *notificationRegistry) unlisten(channel string, l *notificationListener) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.unlistenLocked(channel, l)
+}
+
+// unlistenAll removes the listener from all the channels it is registered on.
+func (r *notificationRegistry) unlistenAll(l *notificationListener) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for channel := range l.channels {
+		r.unlistenLocked(channel, l)
+	}
+}
+
+func (r *notificationRegistry) unlistenLocked(channel string, l *notificationListener) {
+	delete(l.channels, channel)
+	listeners, ok := r.mu.channels[channel]
+	if !ok {
+		return
+	}
+	delete(listeners, l)
+	if len(listeners) == 0 {
+		delete(r.mu.channels, channel)
+	}
+}
+
+// publish queues the notification for all the listeners registered on its
+// channel.
+func (r *notificationRegistry) publish(ctx context.Context, n Notification) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for l := range r.mu.channels[n.Channel] {
+		l.enqueue(ctx, n)
+	}
+}
+
+// start launches the rangefeed which delivers the notifications inserted into
+// system.notifications to the listeners on this node, and the loop which
+// deletes the expired notifications.
+func (r *notificationRegistry) start(ctx context.Context, stopper *stop.Stopper, cfg *ExecutorConfig) {
+	if cfg.DistSender == nil {
+		// Some test servers don't have a DistSender.
+		return
+	}
+	tablePrefix := cfg.Codec.TablePrefix(keys.NotificationsTableID)
+	tableSpan := roachpb.Span{Key: tablePrefix, EndKey: tablePrefix.PrefixEnd()}
+	frontier := span.MakeFrontier(tableSpan)
+	frontier.Forward(tableSpan, cfg.Clock.Now())
+	// delivered contains the keys of the notifications delivered above the
+	// frontier, which are delivered again if the rangefeed restarts.
+	delivered := make(map[string]hlc.Timestamp)
+	var frontierMu syncutil.Mutex
+
+	eventCh := make(chan *roachpb.RangeFeedEvent)
+	ctx, _ = stopper.WithCancelOnQuiesce(ctx)
+	_ = stopper.RunAsyncTask(ctx, "notifications-rangefeed", func(ctx context.Context) {
+		// Run the rangefeed in a loop in case of failure, restarting from the
+		// frontier.
+		for rt := retry.StartWithCtx(ctx, retry.Options{
+			InitialBackoff: 100 * time.Millisecond,
+			MaxBackoff:     2 * time.Second,
+			Closer:         stopper.ShouldQuiesce(),
+		}); rt.Next(); {
+			frontierMu.Lock()
+			ts := frontier.Frontier()
+			frontierMu.Unlock()
+			err := cfg.DistSender.RangeFeed(ctx, tableSpan, ts, false /* withDiff */, eventCh)
+			if ctx.Err() != nil {
+				return
+			}
+			log.Warningf(ctx, "notifications rangefeed failed, restarting: %v", err)
+		}
+	})
+	_ = stopper.RunAsyncTask(ctx, "notifications-delivery", func(ctx context.Context) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e := <-eventCh:
+				switch {
+				case e.Checkpoint != nil:
+					frontierMu.Lock()
+					if frontier.Forward(e.Checkpoint.Span, e.Checkpoint.ResolvedTS) {
+						resolved := frontier.Frontier()
+						for k, ts := range delivered {
+							if ts.LessEq(resolved) {
+								delete(delivered, k)
+							}
+						}
+					}
+					frontierMu.Unlock()
+				case e.Val != nil:
+					// Deletions of expired notifications have an empty value.
+					if len(e.Val.Value.RawBytes) == 0 {
+						continue
+					}
+					key := string(e.Val.Key)
+					if _, ok := delivered[key]; ok {
+						continue
+					}
+					n, err := decodeNotification(e.Val.Value)
+					if err != nil {
+						log.Warningf(ctx, "unable to decode notification %s: %v", e.Val.Key, err)
+						continue
+					}
+					delivered[key] = e.Val.Value.Timestamp
+					r.publish(ctx, n)
+				}
+			}
+		}
+	})
+	_ = stopper.RunAsyncTask(ctx, "notifications-gc", func(ctx context.Context) {
+		ticker := time.NewTicker(notificationGCInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if !cfg.Settings.Version.IsActive(ctx, clusterversion.NotificationsTable) {
+				continue
+			}
+			if _, err := cfg.InternalExecutor.ExecEx(
+				ctx, "delete-expired-notifications", nil, /* txn */
+				sessiondata.InternalExecutorOverride{User: security.NodeUserName()},
+				`DELETE FROM system.notifications WHERE created < $1 LIMIT 1000`,
+				timeutil.Now().Add(-notificationRetention),
+			); err != nil {
+				log.Warningf(ctx, "unable to delete expired notifications: %v", err)
+			}
+		}
+	})
+}
+
+// decodeNotification decodes the channel and the payload of a notification
+// from the value of a row of system.notifications.
+func decodeNotification(value roachpb.Value) (Notification, error) {
+	var n Notification
+	b, err := value.GetTuple()
+	if err != nil {
+		return n, err
+	}
+	var a rowenc.DatumAlloc
+	var colID descpb.ColumnID
+	for len(b) > 0 {
+		_, _, colIDDiff, _, err := encoding.DecodeValueTag(b)
+		if err != nil {
+			return n, err
+		}
+		colID += descpb.ColumnID(colIDDiff)
+		switch colID {
+		case notificationsChannelColID, notificationsPayloadColID:
+			var d tree.Datum
+			d, b, err = rowenc.DecodeTableValue(&a, types.String, b)
+			if err != nil {
+				return n, err
+			}
+			if colID == notificationsChannelColID {
+				n.Channel = string(tree.MustBeDString(d))
+			} else {
+				n.Payload = string(tree.MustBeDString(d))
+			}
+		default:
+			_, l, err := encoding.PeekValueLength(b)
+			if err != nil {
+				return n, err
+			}
+			b = b[l:]
+		}
+	}
+	return n, nil
+}
+
+// notificationListener is the per-session state of a session which executed
+// LISTEN.
+type notificationListener struct {
+	// channels is the set of channels the session is listening on. It is
+	// protected by the notificationRegistry's mutex.
+	channels map[string]struct{}
+
+	mu struct {
+		syncutil.Mutex
+		// pending are the notifications which have not been sent to the client
+		// yet.
+		pending []Notification
+	}
+}
+
+func newNotificationListener() *notificationListener {
+	return &notificationListener{channels: make(map[string]struct{})}
+}
+
+func (l *notificationListener) enqueue(ctx context.Context, n Notification) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.mu.pending) >= maxPendingNotifications {
+		log.Warningf(ctx, "dropping notification on channel %q: too many pending notifications", n.Channel)
+		return
+	}
+	l.mu.pending = append(l.mu.pending, n)
+}
+
+// drain returns and clears the pending notifications.
+func (l *notificationListener) drain() []Notification {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ret := l.mu.pending
+	l.mu.pending = nil
+	return ret
+}
+
+// txnNotifications accumulates the effects of the LISTEN and UNLISTEN
+// statements executed in a transaction until it commits, and the notifications
+// sent by the transaction.
+type txnNotifications struct {
+	// ops are the LISTEN and UNLISTEN statements, in order.
+	ops []listenOp
+	// seen is used to send only once the notifications that are sent multiple
+	// times in the transaction.
+	seen map[Notification]struct{}
+}
+
+// listenOp is a LISTEN or UNLISTEN statement. An UNLISTEN with an empty channel
+// stands for UNLISTEN *.
+type listenOp struct {
+	channel string
+	listen  bool
+}
+
+func (tn *txnNotifications) reset() {
+	tn.ops = nil
+	tn.seen = nil
+}
+
+// rollbackToSavepoint forgets the notifications sent by the transaction, since
+// the ones sent after the savepoint were rolled back and may be sent again.
+// This can cause a notification sent both before and after the savepoint to be
+// delivered twice.
+func (tn *txnNotifications) rollbackToSavepoint() {
+	tn.seen = nil
+}
+
+// notificationsAccessor gives a planner access to the LISTEN and NOTIFY state
+// of a session.
+type notificationsAccessor interface {
+	// Listen registers the session on the given channel when the current
+	// transaction commits.
+	Listen(channel string)
+	// Unlisten unregisters the session from the given channel, or from all
+	// channels if the channel is empty, when the current transaction commits.
+	Unlisten(channel string)
+	// Notify records that the current transaction sends a notification on the
+	// given channel. It returns false if the transaction already sent the same
+	// notification, which must then not be sent again.
+	Notify(channel, payload string) bool
+}
+
+// connExNotificationsAccessor is an implementation of notificationsAccessor
+// that gives access to a connExecutor's notifications.
+type connExNotificationsAccessor struct {
+	ex *connExecutor
+}
+
+var _ notificationsAccessor = connExNotificationsAccessor{}
+
+// Listen is part of the notificationsAccessor interface.
+func (na connExNotificationsAccessor) Listen(channel string) {
+	tn := &na.ex.extraTxnState.notifications
+	tn.ops = append(tn.ops, listenOp{channel: channel, listen: true})
+}
+
+// Unlisten is part of the notificationsAccessor interface.
+func (na connExNotificationsAccessor) Unlisten(channel string) {
+	tn := &na.ex.extraTxnState.notifications
+	tn.ops = append(tn.ops, listenOp{channel: channel})
+}
+
+// Notify is part of the notificationsAccessor interface.
+func (na connExNotificationsAccessor) Notify(channel, payload string) bool {
+	tn := &na.ex.extraTxnState.notifications
+	n := Notification{Channel: channel, Payload: payload}
+	if _, ok := tn.seen[n]; ok {
+		return false
+	}
+	if tn.seen == nil {
+		tn.seen = make(map[Notification]struct{})
+	}
+	tn.seen[n] = struct{}{}
+	return true
+}
+
+// commitNotifications applies the LISTEN and UNLISTEN statements of the
+// transaction which just committed. Its notifications are published by the
+// commit of the rows inserted into system.notifications.
+func (ex *connExecutor) commitNotifications() {
+	tn := &ex.extraTxnState.notifications
+	registry := ex.server.notifications
+	for _, op := range tn.ops {
+		switch {
+		case op.listen:
+			if ex.notificationListener == nil {
+				ex.notificationListener = newNotificationListener()
+			}
+			registry.listen(op.channel, ex.notificationListener)
+		case ex.notificationListener == nil:
+			// Nothing to unlisten from.
+		case op.channel == "":
+			registry.unlistenAll(ex.notificationListener)
+		default:
+			registry.unlisten(op.channel, ex.notificationListener)
+		}
+	}
+	tn.reset()
+}
+
+func checkNotificationsAccessor(p *planner, stmt tree.Statement) error {
+	if p.notifications == nil {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"%s is not supported in this context", stmt.StatementTag())
+	}
+	return nil
+}
+
+// Listen implements the LISTEN statement.
+// See https://www.postgresql.org/docs/current/sql-listen.html for details.
+func (p *planner) Listen(ctx context.Context, n *tree.Listen) (planNode, error) {
+	if err := checkNotificationsAccessor(p, n); err != nil {
+		return nil, err
+	}
+	if n.ChannelName == "" {
+		return nil, pgerror.New(pgcode.InvalidParameterValue, "channel name cannot be empty")
+	}
+	p.notifications.Listen(string(n.ChannelName))
+	return newZeroNode(nil /* columns */), nil
+}
+
+// Unlisten implements the UNLISTEN statement.
+// See https://www.postgresql.org/docs/current/sql-unlisten.html for details.
+func (p *planner) Unlisten(ctx context.Context, n *tree.Unlisten) (planNode, error) {
+	if err := checkNotificationsAccessor(p, n); err != nil {
+		return nil, err
+	}
+	p.notifications.Unlisten(string(n.ChannelName))
+	return newZeroNode(nil /* columns */), nil
+}
+
+// Notify implements the NOTIFY statement.
+// See https://www.postgresql.org/docs/current/sql-notify.html for details.
+func (p *planner) Notify(ctx context.Context, n *tree.Notify) (planNode, error) {
+	if err := checkNotificationsAccessor(p, n); err != nil {
+		return nil, err
+	}
+	if n.ChannelName == "" {
+		return nil, pgerror.New(pgcode.InvalidParameterValue, "channel name cannot be empty")
+	}
+	var payload string
+	if n.Payload != nil {
+		payload = n.Payload.RawString()
+	}
+	if len(payload) >= maxNotificationPayloadLength {
+		return nil, pgerror.New(pgcode.InvalidParameterValue, "payload string too long")
+	}
+	if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.NotificationsTable) {
+		return nil, pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			`NOTIFY requires all nodes to be upgraded to %s`,
+			clusterversion.ByKey(clusterversion.NotificationsTable))
+	}
+	if !p.notifications.Notify(string(n.ChannelName), payload) {
+		return newZeroNode(nil /* columns */), nil
+	}
+	// The notification is published to the listeners of all the nodes when the
+	// row commits, and discarded if the transaction rolls back.
+	if _, err := p.ExecCfg().InternalExecutor.ExecEx(
+		ctx, "notify", p.txn,
+		sessiondata.InternalExecutorOverride{User: security.NodeUserName()},
+		`INSERT INTO system.notifications (channel, payload) VALUES ($1, $2)`,
+		string(n.ChannelName), payload,
+	); err != nil {
+		return nil, err
+	}
+	return newZeroNode(nil /* columns */), nil
+}
@@ -79,6 +79,11 @@ type Builder struct {
 	// by scans. See forUpdateLocking.
 	forceForUpdateLocking bool
 
+	// OptimizerTrace is the log of optimizer rules that were applied while
+	// planning the statement, followed by the formatted memo. It is only set
+	// for EXPLAIN (OPT, MEMO), in which case it is appended to the output.
+	OptimizerTrace string
+
 	// -- output --
 
 	// IsDDL is set to true if the statement contains DDL.
@@ -882,6 +882,10 @@ func (b *Builder) presentationToResultColumns(pres physical.Presentation) colinf
 	return result
 }
 
+// ErrUnsatisfiableJoinHint marks the error returned when no plan conforms to
+// the join hint of a join.
+var ErrUnsatisfiableJoinHint = errors.New("unsatisfiable join hint")
+
 func (b *Builder) buildHashJoin(join memo.RelExpr) (execPlan, error) {
 	if f := join.Private().(*memo.JoinPrivate).Flags; f.Has(memo.DisallowHashJoinStoreRight) {
 		// We need to do a bit of reverse engineering here to determine what the
@@ -893,9 +897,9 @@ func (b *Builder) buildHashJoin(join memo.RelExpr) (execPlan, error) {
 			hint = tree.AstInverted
 		}
 
-		return execPlan{}, errors.Errorf(
+		return execPlan{}, errors.Mark(errors.Errorf(
 			"could not produce a query plan conforming to the %s JOIN hint", hint,
-		)
+		), ErrUnsatisfiableJoinHint)
 	}
 
 	joinType := joinOpToJoinType(join.Op())
@@ -19,6 +19,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/util/treeprinter"
@@ -97,6 +99,19 @@ func (b *Builder) buildExplainOpt(explain *memo.ExplainExpr) (execPlan, error) {
 	f.FormatExpr(explain.Input)
 	planText.WriteString(f.Buffer.String())
 
+	// If the memo option was passed, show the rules that fired and the explored
+	// alternatives (with their costs) that the optimizer considered.
+	if explain.Options.Flags[tree.ExplainFlagMemo] {
+		if b.OptimizerTrace == "" {
+			// The trace is only recorded for top-level EXPLAIN statements, not
+			// for ones used as a data source.
+			return execPlan{}, pgerror.Newf(pgcode.FeatureNotSupported,
+				"the MEMO flag is only supported for top-level EXPLAIN statements")
+		}
+		planText.WriteString("\n")
+		planText.WriteString(b.OptimizerTrace)
+	}
+
 	// If we're going to display the environment, there's a bunch of queries we
 	// need to run to get that information, and we can't run them from here, so
 	// tell the exec factory what information it needs to fetch.
@@ -407,6 +407,10 @@ const (
 	// PreferLookupJoinIntoRight reduces the cost of a lookup join where the
 	// lookup table is on the right side.
 	PreferLookupJoinIntoRight
+
+	// PreserveJoinOrder prevents the join from being reordered with other joins,
+	// without restricting the type of join that can be used.
+	PreserveJoinOrder
 )
 
 const (
@@ -464,7 +468,8 @@ func (jf JoinFlags) String() string {
 	}
 
 	prefer := jf & (PreferLookupJoinIntoLeft | PreferLookupJoinIntoRight)
-	disallow := jf ^ prefer
+	preserve := jf & PreserveJoinOrder
+	disallow := jf ^ prefer ^ preserve
 
 	// Special cases with prettier results for common cases.
 	var b strings.Builder
@@ -500,6 +505,13 @@ func (jf JoinFlags) String() string {
 		b.WriteString(joinFlagStr[flag])
 		prefer ^= flag
 	}
+
+	if preserve != 0 {
+		if b.Len() > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString("preserve join order")
+	}
 	return b.String()
 }
 
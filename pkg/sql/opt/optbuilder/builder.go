@@ -66,6 +66,11 @@ type Builder struct {
 	// This is used when re-preparing invalidated queries.
 	KeepPlaceholders bool
 
+	// Hints is a control knob: if set, it contains the plan hints specified in
+	// the statement, which are applied to the corresponding table scans and
+	// joins (unless those already have explicit index flags or join hints).
+	Hints *tree.PlanHints
+
 	// -- Results --
 	//
 	// These fields are set during the building process and can be used after
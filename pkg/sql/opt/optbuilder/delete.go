@@ -79,6 +79,10 @@ func (mb *mutationBuilder) buildDelete(returning tree.ReturningExprs) {
 	// Project partial index DEL boolean columns.
 	mb.projectPartialIndexDelCols(mb.fetchScope)
 
+	// The RETURNING clause can refer to the values of the deleted rows as "old".
+	// These are the values returned by the Delete.
+	mb.imageCols = mb.makeImageCols(returning, oldImageTableName, mb.tableColIDs())
+
 	private := mb.makeMutationPrivate(returning != nil)
 	mb.outScope.expr = mb.b.factory.ConstructDelete(
 		mb.outScope.expr, mb.uniqueChecks, mb.fkChecks, private,
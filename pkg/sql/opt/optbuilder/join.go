@@ -54,9 +54,20 @@ func (b *Builder) buildJoin(
 	b.validateJoinTableNames(leftScope, rightScope)
 
 	joinType := descpb.JoinTypeFromAstString(join.JoinType)
+	hint := join.Hint
+	if hint == "" && !b.Hints.Empty() {
+		hint = b.planHintForJoin(join, joinType)
+	}
 	var flags memo.JoinFlags
-	switch join.Hint {
+	switch hint {
 	case "":
+		if !b.Hints.Empty() && b.Hints.Ordered {
+			// The ORDERED plan hint prevents the join from being reordered,
+			// without restricting the join algorithm.
+			telemetry.Inc(sqltelemetry.PlanHintUseCounter)
+			flags = memo.PreserveJoinOrder
+		}
+
 	case tree.AstHash:
 		telemetry.Inc(sqltelemetry.HashJoinHintUseCounter)
 		flags = memo.AllowOnlyHashJoinStoreRight
@@ -89,7 +100,7 @@ func (b *Builder) buildJoin(
 
 	default:
 		panic(pgerror.Newf(
-			pgcode.FeatureNotSupported, "join hint %s not supported", join.Hint,
+			pgcode.FeatureNotSupported, "join hint %s not supported", hint,
 		))
 	}
 
@@ -532,3 +543,69 @@ func (jb *usingJoinBuilder) raiseUndefinedColError(name tree.Name, context strin
 	panic(pgerror.Newf(pgcode.UndefinedColumn,
 		"column \"%s\" specified in USING clause does not exist in %s table", name, context))
 }
+
+// planHintForJoin returns the join hint that the plan hints of the statement
+// specify for the given join, or the empty string if there is none. Unlike
+// join hints specified inline, plan hints that cannot be applied to the join
+// are ignored rather than causing an error.
+func (b *Builder) planHintForJoin(join *tree.JoinTableExpr, joinType descpb.JoinType) string {
+	name := planHintTableName(join.Right)
+	hint := b.Hints.JoinHintForTable(name)
+	if hint == tree.AstLookup {
+		// Lookup joins are only possible into a table, and only for inner and
+		// left joins.
+		if name == "" || (joinType != descpb.InnerJoin && joinType != descpb.LeftOuterJoin) {
+			return ""
+		}
+	}
+	if hint != "" {
+		telemetry.Inc(sqltelemetry.PlanHintUseCounter)
+	}
+	return hint
+}
+
+// commaJoinPrivate returns the private of the inner joins that are built
+// between the tables of a FROM clause. With the ORDERED plan hint, these joins
+// are not reordered, like explicit joins.
+func (b *Builder) commaJoinPrivate() *memo.JoinPrivate {
+	if b.Hints.Empty() || !b.Hints.Ordered {
+		return memo.EmptyJoinPrivate
+	}
+	telemetry.Inc(sqltelemetry.PlanHintUseCounter)
+	return &memo.JoinPrivate{Flags: memo.PreserveJoinOrder}
+}
+
+// planHintTableName returns the name by which the given table expression can
+// be referenced in a plan hint: its alias if it has one, or the unqualified
+// table name otherwise. It returns the empty name if the expression is not a
+// (possibly aliased) table.
+func planHintTableName(texpr tree.TableExpr) tree.Name {
+	switch t := tree.StripTableParens(texpr).(type) {
+	case *tree.AliasedTableExpr:
+		if t.As.Alias != "" {
+			return t.As.Alias
+		}
+		return planHintTableName(t.Expr)
+	case *tree.TableName:
+		return t.ObjectName
+	}
+	return ""
+}
+
+// ignoreUnknownPlanHintIndex returns the given index flags, unless they come
+// from an INDEX plan hint naming an index that the given table doesn't have,
+// in which case the forced index is dropped from the flags. Unlike index hints
+// specified inline, such plan hints are ignored rather than causing an error.
+func (b *Builder) ignoreUnknownPlanHintIndex(
+	tab cat.Table, indexFlags *tree.IndexFlags,
+) *tree.IndexFlags {
+	if indexFlags == nil || indexFlags.Index == "" || !indexFlags.FromPlanHint {
+		return indexFlags
+	}
+	for i := 0; i < tab.IndexCount(); i++ {
+		if tab.Index(i).Name() == tree.Name(indexFlags.Index) {
+			return indexFlags
+		}
+	}
+	return &tree.IndexFlags{NoIndexJoin: indexFlags.NoIndexJoin, FromPlanHint: true}
+}
@@ -177,6 +177,11 @@ type mutationBuilder struct {
 	// made accessible to the RETURNING clause.
 	extraAccessibleCols []scopeColumn
 
+	// imageCols stores the columns that make the values of the mutated rows
+	// before and after the mutation accessible to the RETURNING clause, under
+	// the "old" and "new" table names. See makeImageCols.
+	imageCols []scopeColumn
+
 	// fkCheckHelper is used to prevent allocating the helper separately.
 	fkCheckHelper fkCheckHelper
 
@@ -1097,6 +1102,8 @@ func (mb *mutationBuilder) buildReturning(returning tree.ReturningExprs) {
 	// UPDATE ... FROM statements, where all columns from tables in the FROM clause
 	// are in scope for the RETURNING clause.
 	inScope.appendColumns(mb.extraAccessibleCols)
+	inScope.appendColumns(mb.imageCols)
+	returning = mb.expandImageStars(returning)
 
 	// Construct the Project operator that projects the RETURNING expressions.
 	outScope := inScope.replace()
@@ -1106,6 +1113,130 @@ func (mb *mutationBuilder) buildReturning(returning tree.ReturningExprs) {
 	mb.outScope = outScope
 }
 
+// Table names that the RETURNING clause of an UPDATE or DELETE can use to
+// refer to the values of the mutated rows before and after the mutation.
+const (
+	oldImageTableName tree.Name = "old"
+	newImageTableName tree.Name = "new"
+)
+
+// makeImageCols returns columns that make the values of the mutated rows
+// accessible to the RETURNING clause under the given table name. colIDs maps
+// the ordinals of the table columns to the columns holding the values. No
+// columns are returned if the RETURNING clause does not reference the table
+// name, or if the name already refers to a table of the statement.
+//
+// Only the visible columns of the table are made accessible. The columns are
+// hidden so that they are not part of the expansion of an unqualified star;
+// see expandImageStars for the expansion of a star qualified with the table
+// name. Hidden table columns like rowid are left out, since their hidden image
+// would make unqualified references to them ambiguous.
+func (mb *mutationBuilder) makeImageCols(
+	returning tree.ReturningExprs, tabName tree.Name, colIDs opt.OptionalColList,
+) []scopeColumn {
+	if !returningReferencesTable(returning, tabName) || mb.tableNameInUse(tabName) {
+		return nil
+	}
+	alias := tree.MakeUnqualifiedTableName(tabName)
+	var cols []scopeColumn
+	for i, n := 0, mb.tab.ColumnCount(); i < n; i++ {
+		tabCol := mb.tab.Column(i)
+		if tabCol.Kind() != cat.Ordinary || tabCol.Visibility() != cat.Visible || colIDs[i] == 0 {
+			continue
+		}
+		cols = append(cols, scopeColumn{
+			name:       tabCol.ColName(),
+			table:      alias,
+			typ:        tabCol.DatumType(),
+			id:         colIDs[i],
+			visibility: cat.Hidden,
+		})
+	}
+	return cols
+}
+
+// tableColIDs returns the IDs of the target table columns, which hold the
+// values returned by the mutation.
+func (mb *mutationBuilder) tableColIDs() opt.OptionalColList {
+	colIDs := make(opt.OptionalColList, mb.tab.ColumnCount())
+	for i := range colIDs {
+		colIDs[i] = mb.tabID.ColumnID(i)
+	}
+	return colIDs
+}
+
+// tableNameInUse returns true if the given name refers to the target table or
+// to one of the tables in the FROM clause of the statement.
+func (mb *mutationBuilder) tableNameInUse(tabName tree.Name) bool {
+	if mb.alias.ObjectName == tabName {
+		return true
+	}
+	for i := range mb.extraAccessibleCols {
+		if mb.extraAccessibleCols[i].table.ObjectName == tabName {
+			return true
+		}
+	}
+	return false
+}
+
+// expandImageStars replaces the stars qualified with the name of a table
+// defined by makeImageCols (e.g. "old.*") with references to each visible
+// column of that table.
+func (mb *mutationBuilder) expandImageStars(returning tree.ReturningExprs) tree.ReturningExprs {
+	if len(mb.imageCols) == 0 {
+		return returning
+	}
+	hasImageCols := func(tabName string) bool {
+		for i := range mb.imageCols {
+			if string(mb.imageCols[i].table.ObjectName) == tabName {
+				return true
+			}
+		}
+		return false
+	}
+	var res tree.ReturningExprs
+	for i := range returning {
+		name, ok := returning[i].Expr.(*tree.UnresolvedName)
+		if !ok || !name.Star || name.NumParts != 2 || !hasImageCols(name.Parts[1]) {
+			if res != nil {
+				res = append(res, returning[i])
+			}
+			continue
+		}
+		if res == nil {
+			res = append(make(tree.ReturningExprs, 0, len(returning)), returning[:i]...)
+		}
+		for j, n := 0, mb.tab.ColumnCount(); j < n; j++ {
+			tabCol := mb.tab.Column(j)
+			if tabCol.Kind() == cat.Ordinary && tabCol.Visibility() == cat.Visible {
+				res = append(res, tree.SelectExpr{
+					Expr: tree.NewUnresolvedName(name.Parts[1], string(tabCol.ColName())),
+				})
+			}
+		}
+	}
+	if res == nil {
+		return returning
+	}
+	return res
+}
+
+// returningReferencesTable returns true if the RETURNING clause contains a
+// column reference qualified with the given table name.
+func returningReferencesTable(returning tree.ReturningExprs, tabName tree.Name) bool {
+	found := false
+	for i := range returning {
+		_, _ = tree.SimpleVisit(returning[i].Expr, func(expr tree.Expr) (bool, tree.Expr, error) {
+			if name, ok := expr.(*tree.UnresolvedName); ok && name.NumParts == 2 &&
+				name.Parts[1] == string(tabName) {
+				found = true
+			}
+			return !found, expr, nil
+		})
+	}
+	return found
+}
+
 // checkNumCols raises an error if the expected number of columns does not match
 // the actual number of columns.
 func (mb *mutationBuilder) checkNumCols(expected, actual int) {
@@ -51,6 +51,9 @@ func (b *Builder) buildDataSource(
 			telemetry.Inc(sqltelemetry.IndexHintUseCounter)
 			telemetry.Inc(sqltelemetry.IndexHintSelectUseCounter)
 			indexFlags = source.IndexFlags
+		} else if flags := b.Hints.IndexFlagsForTable(planHintTableName(source)); flags != nil {
+			telemetry.Inc(sqltelemetry.PlanHintUseCounter)
+			indexFlags = flags
 		}
 		if source.As.Alias != "" {
 			locking = locking.filter(source.As.Alias)
@@ -110,6 +113,7 @@ func (b *Builder) buildDataSource(
 
 		switch t := ds.(type) {
 		case cat.Table:
+			indexFlags = b.ignoreUnknownPlanHintIndex(t, indexFlags)
 			tabMeta := b.addTable(t, &resName)
 			return b.buildScan(
 				tabMeta,
@@ -1196,7 +1200,7 @@ func (b *Builder) buildFromTablesRightDeep(
 
 	left := outScope.expr.(memo.RelExpr)
 	right := tableScope.expr.(memo.RelExpr)
-	outScope.expr = b.factory.ConstructInnerJoin(left, right, memo.TrueFilter, memo.EmptyJoinPrivate)
+	outScope.expr = b.factory.ConstructInnerJoin(left, right, memo.TrueFilter, b.commaJoinPrivate())
 	return outScope
 }
 
@@ -1247,7 +1251,7 @@ func (b *Builder) buildFromWithLateral(
 
 		left := outScope.expr.(memo.RelExpr)
 		right := tableScope.expr.(memo.RelExpr)
-		outScope.expr = b.factory.ConstructInnerJoinApply(left, right, memo.TrueFilter, memo.EmptyJoinPrivate)
+		outScope.expr = b.factory.ConstructInnerJoinApply(left, right, memo.TrueFilter, b.commaJoinPrivate())
 	}
 
 	return outScope
@@ -1324,8 +1328,7 @@ func (b *Builder) validateLockingInFrom(
 		case tree.LockWaitBlock:
 			// Default. Block on conflicting locks.
 		case tree.LockWaitSkip:
-			panic(unimplementedWithIssueDetailf(40476, "",
-				"SKIP LOCKED lock wait policy is not supported"))
+			// Skip rows with conflicting locks.
 		case tree.LockWaitError:
 			// Raise an error on conflicting locks.
 		default:
@@ -348,12 +348,22 @@ func (mb *mutationBuilder) buildUpdate(returning tree.ReturningExprs) {
 
 	mb.buildFKChecksForUpdate()
 
+	// The RETURNING clause can refer to the values of the rows before and after
+	// the update as "old" and "new". The values before the update are held by
+	// the fetch columns, which must be passed through the Update.
+	oldCols := mb.makeImageCols(returning, oldImageTableName, mb.fetchColIDs)
+	newCols := mb.makeImageCols(returning, newImageTableName, mb.tableColIDs())
+	mb.imageCols = append(oldCols, newCols...)
+
 	private := mb.makeMutationPrivate(returning != nil)
 	for _, col := range mb.extraAccessibleCols {
 		if col.id != 0 {
 			private.PassthroughCols = append(private.PassthroughCols, col.id)
 		}
 	}
+	for _, col := range oldCols {
+		private.PassthroughCols = append(private.PassthroughCols, col.id)
+	}
 	mb.outScope.expr = mb.b.factory.ConstructUpdate(
 		mb.outScope.expr, mb.uniqueChecks, mb.fkChecks, private,
 	)
@@ -54,6 +54,10 @@ type Statement struct {
 	// NumAnnotations indicates the number of annotations in the tree. It is equal
 	// to the maximum annotation index.
 	NumAnnotations tree.AnnotationIdx
+
+	// Hints contains the plan hints specified in /*+ ... */ comments inside the
+	// statement, or nil if there are none.
+	Hints *tree.PlanHints
 }
 
 // Statements is a list of parsed statements.
@@ -129,6 +133,7 @@ func (p *Parser) parseOneWithInt(sql string, nakedIntType *types.T) (Statement,
 func (p *Parser) scanOneStmt() (sql string, tokens []sqlSymType, done bool) {
 	var lval sqlSymType
 	tokens = p.tokBuf[:0]
+	p.scanner.resetHints()
 
 	// Scan the first token.
 	for {
@@ -169,6 +174,7 @@ func (p *Parser) parseWithDepth(depth int, sql string, nakedIntType *types.T) (S
 		if err != nil {
 			return nil, err
 		}
+		stmt.Hints = p.scanner.planHints()
 		if stmt.AST != nil {
 			stmts = append(stmts, stmt)
 		}
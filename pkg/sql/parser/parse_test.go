@@ -464,6 +464,12 @@ func TestParse(t *testing.T) {
 
 		{`DISCARD ALL`},
 
+		{`LISTEN a`},
+		{`UNLISTEN a`},
+		{`UNLISTEN *`},
+		{`NOTIFY a`},
+		{`NOTIFY a, 'b'`},
+
 		{`DROP DATABASE a`},
 		{`EXPLAIN DROP DATABASE a`},
 		{`DROP DATABASE IF EXISTS a`},
@@ -561,6 +567,7 @@ func TestParse(t *testing.T) {
 		{`EXPLAIN (DISTSQL) SELECT 1`},
 		{`EXPLAIN (DISTSQL, JSON) SELECT 1`},
 		{`EXPLAIN (OPT, VERBOSE) SELECT 1`},
+		{`EXPLAIN (OPT, MEMO) SELECT 1`},
 		{`EXPLAIN ANALYZE (DISTSQL) SELECT 1`},
 		{`EXPLAIN ANALYZE (DEBUG) SELECT 1`},
 		{`EXPLAIN ANALYZE SELECT 1`},
@@ -2386,6 +2393,8 @@ $function$`,
 		{`RESET CLUSTER SETTING a`, `SET CLUSTER SETTING a = DEFAULT`},
 
 		{`RESET NAMES`, `SET client_encoding = DEFAULT`},
+		{`RESET ALL`, `SET "all" = DEFAULT`},
+		{`RESET SESSION ALL`, `SET "all" = DEFAULT`},
 
 		{`CREATE USER foo`,
 			`CREATE USER 'foo'`},
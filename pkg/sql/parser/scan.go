@@ -36,6 +36,10 @@ type scanner struct {
 	in            string
 	pos           int
 	bytesPrealloc []byte
+
+	// hints accumulates the contents of the plan hint comments (/*+ ... */)
+	// encountered since the last call to resetHints.
+	hints []string
 }
 
 func makeScanner(str string) scanner {
@@ -47,6 +51,7 @@ func makeScanner(str string) scanner {
 func (s *scanner) init(str string) {
 	s.in = str
 	s.pos = 0
+	s.hints = nil
 	// Preallocate some buffer space for identifiers etc.
 	s.bytesPrealloc = make([]byte, len(str))
 }
@@ -55,6 +60,28 @@ func (s *scanner) init(str string) {
 // where we reuse a scanner).
 func (s *scanner) cleanup() {
 	s.bytesPrealloc = nil
+	s.hints = nil
+}
+
+// resetHints clears the plan hint comments accumulated so far.
+func (s *scanner) resetHints() {
+	s.hints = s.hints[:0]
+}
+
+// planHints returns the plan hints specified in the hint comments accumulated
+// since the last call to resetHints, or nil if there are none.
+func (s *scanner) planHints() *tree.PlanHints {
+	if len(s.hints) == 0 {
+		return nil
+	}
+	h := &tree.PlanHints{}
+	for _, c := range s.hints {
+		h.Parse(c)
+	}
+	if h.Empty() {
+		return nil
+	}
+	return h
 }
 
 func (s *scanner) allocBytes(length int) []byte {
@@ -467,6 +494,10 @@ func (s *scanner) scanComment(lval *sqlSymType) (present, ok bool) {
 					s.pos++
 					depth--
 					if depth == 0 {
+						if s.in[start+2] == '+' {
+							// This is a plan hint comment; remember its contents.
+							s.hints = append(s.hints, s.in[start+3:s.pos-2])
+						}
 						return true, true
 					}
 					continue
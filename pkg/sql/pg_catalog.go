@@ -1166,14 +1166,16 @@ https://www.postgresql.org/docs/9.5/catalog-pg-depend.html`,
 				refObjID := tableOid(table.GetSequenceOpts().SequenceOwner.OwnerTableID)
 				refObjSubID := tree.NewDInt(tree.DInt(table.GetSequenceOpts().SequenceOwner.OwnerColumnID))
 				objID := tableOid(table.GetID())
+				// A sequence owned by a column is a pg_class object that depends
+				// on the column, like in PostgreSQL.
 				return addRow(
-					pgConstraintTableOid, // classid
-					objID,                // objid
-					zeroVal,              // objsubid
-					pgClassTableOid,      // refclassid
-					refObjID,             // refobjid
-					refObjSubID,          // refobjsubid
-					depTypeAuto,          // deptype
+					pgClassTableOid, // classid
+					objID,           // objid
+					zeroVal,         // objsubid
+					pgClassTableOid, // refclassid
+					refObjID,        // refobjid
+					refObjSubID,     // refobjsubid
+					depTypeAuto,     // deptype
 				)
 			}
 
@@ -1450,9 +1452,10 @@ https://www.postgresql.org/docs/9.5/catalog-pg-index.html`,
 			func(db *dbdesc.Immutable, scName string, table catalog.TableDescriptor) error {
 				tableOid := tableOid(table.GetID())
 				return catalog.ForEachIndex(table, catalog.IndexOpts{}, func(index catalog.Index) error {
+					// An index is ready for inserts once it is public or write-only.
 					isMutation, isWriteOnly :=
 						table.GetIndexMutationCapabilities(index.GetID())
-					isReady := isMutation && isWriteOnly
+					isReady := !isMutation || isWriteOnly
 
 					// Get the collations for all of the columns. To do this we require
 					// the type of the column.
@@ -1496,10 +1499,20 @@ https://www.postgresql.org/docs/9.5/catalog-pg-index.html`,
 					if err != nil {
 						return err
 					}
+					indpred := tree.DNull
+					if index.IsPartial() {
+						pred, err := schemaexpr.FormatExprForDisplay(
+							ctx, table, index.GetPredicate(), p.SemaCtx(), tree.FmtPGCatalog,
+						)
+						if err != nil {
+							return err
+						}
+						indpred = tree.NewDString(pred)
+					}
 					return addRow(
 						h.IndexOid(table.GetID(), index.GetID()),     // indexrelid
 						tableOid,                                     // indrelid
-						tree.NewDInt(tree.DInt(index.NumColumns())),  // indnatts
+						tree.NewDInt(tree.DInt(len(colIDs))),         // indnatts
 						tree.MakeDBool(tree.DBool(index.IsUnique())), // indisunique
 						tree.MakeDBool(tree.DBool(index.Primary())),  // indisprimary
 						tree.DBoolFalse,                              // indisexclusion
@@ -1515,7 +1528,7 @@ https://www.postgresql.org/docs/9.5/catalog-pg-index.html`,
 						indclass,                                     // indclass
 						indoptionIntVector,                           // indoption
 						tree.DNull,                                   // indexprs
-						tree.DNull,                                   // indpred
+						indpred,                                      // indpred
 					)
 				})
 			})
@@ -19,9 +19,11 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/hba"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/identmap"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
 )
 
@@ -32,6 +34,16 @@ const (
 	// authCleartextPassword is the pgwire auth response code to request
 	// a plaintext password during the connection handshake.
 	authCleartextPassword int32 = 3
+	// authSASL is the pgwire auth response code to request the start of a
+	// SASL authentication exchange, listing the supported mechanisms.
+	authSASL int32 = 10
+	// authSASLContinue is the pgwire auth response code carrying SASL
+	// challenge data during the exchange.
+	authSASLContinue int32 = 11
+	// authSASLFinal is the pgwire auth response code carrying the
+	// additional data sent by the server when SASL authentication
+	// completes.
+	authSASLFinal int32 = 12
 )
 
 type authOptions struct {
@@ -48,9 +60,18 @@ type authOptions struct {
 	// auth is the current HBA configuration as returned by
 	// (*Server).GetAuthenticationConfiguration().
 	auth *hba.Conf
+	// identMap is the current identity map configuration as returned by
+	// (*Server).GetIdentityMapConfiguration().
+	identMap *identmap.Conf
 	// ie is the server-wide internal executor, used to
 	// retrieve entries from system.users.
 	ie *sql.InternalExecutor
+	// connLimiter enforces the limits on the number of connections once the
+	// connection is authenticated. No limits are enforced if it is nil.
+	connLimiter *connLimiter
+	// loginLockout locks users out after too many failed authentication
+	// attempts. No lockout is enforced if it is nil.
+	loginLockout *LoginLockout
 
 	// The following fields are only used by tests.
 
@@ -108,6 +129,13 @@ func (c *conn) handleAuthentication(
 			"%s does not have login privilege", c.sessionArgs.User))
 	}
 
+	if authOpt.loginLockout != nil {
+		if err := authOpt.loginLockout.Check(c.sessionArgs.User, timeutil.Now()); err != nil {
+			ac.LogAuthFailed(ctx, eventpb.AuthFailReason_LOGIN_DISABLED, err)
+			return nil, sendError(err)
+		}
+	}
+
 	// Retrieve the authentication method.
 	tlsState, hbaEntry, methodFn, err := c.findAuthenticationMethod(authOpt)
 	if err != nil {
@@ -120,14 +148,18 @@ func (c *conn) handleAuthentication(
 
 	// Ask the method to authenticate.
 	authenticationHook, err := methodFn(ctx, ac, tlsState, pwRetrievalFn,
-		validUntilFn, execCfg, hbaEntry)
+		validUntilFn, execCfg, hbaEntry, authOpt.identMap)
 
 	if err != nil {
 		ac.LogAuthFailed(ctx, eventpb.AuthFailReason_METHOD_NOT_FOUND, err)
 		return nil, sendError(err)
 	}
 
-	if connClose, err = authenticationHook(c.sessionArgs.User, true /* public */); err != nil {
+	connClose, err = authenticationHook(c.sessionArgs.User, true /* public */)
+	if authOpt.loginLockout != nil {
+		authOpt.loginLockout.ReportAttempt(c.sessionArgs.User, err == nil, timeutil.Now())
+	}
+	if err != nil {
 		ac.LogAuthFailed(ctx, eventpb.AuthFailReason_CREDENTIALS_INVALID, err)
 		return connClose, sendError(err)
 	}
@@ -167,7 +199,11 @@ func (c *conn) findAuthenticationMethod(
 	// If the client is using SSL, retrieve the TLS state to provide as
 	// input to the method.
 	if authOpt.connType == hba.ConnHostSSL {
-		tlsConn, ok := c.conn.(*readTimeoutConn).Conn.(*tls.Conn)
+		netConn := c.conn.(*readTimeoutConn).Conn
+		if cc, ok := netConn.(*compressedConn); ok {
+			netConn = cc.Conn
+		}
+		tlsConn, ok := netConn.(*tls.Conn)
 		if !ok {
 			err = errors.AssertionFailedf("server reports hostssl conn without TLS state")
 			return
@@ -262,6 +298,8 @@ type AuthConn interface {
 	// to the client connection.
 	AuthFail(err error)
 
+	// User returns the user name requested by the client.
+	User() security.SQLUsername
 	// SetAuthMethod sets the authentication method for subsequent
 	// logging messages.
 	SetAuthMethod(method string)
@@ -284,6 +322,7 @@ type authPipe struct {
 	connDetails eventpb.CommonConnectionDetails
 	authDetails eventpb.CommonSessionDetails
 	authMethod  string
+	user        security.SQLUsername
 
 	ch chan []byte
 	// writerDone is a channel closed by noMorePwdData().
@@ -306,6 +345,7 @@ func newAuthPipe(c *conn, logAuthn bool, authOpt authOptions, user security.SQLU
 			Transport: authOpt.connType.String(),
 			User:      user.Normalized(),
 		},
+		user:       user,
 		ch:         make(chan []byte),
 		writerDone: make(chan struct{}),
 		readerDone: make(chan authRes, 1),
@@ -353,6 +393,11 @@ func (p *authPipe) AuthFail(err error) {
 	p.readerDone <- authRes{err: err}
 }
 
+// User is part of the AuthConn interface.
+func (p *authPipe) User() security.SQLUsername {
+	return p.user
+}
+
 func (p *authPipe) SetAuthMethod(method string) {
 	p.authMethod = method
 }
@@ -14,11 +14,14 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/binary"
 	"fmt"
 
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/hba"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/identmap"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
@@ -43,16 +46,27 @@ func loadDefaultMethods() {
 	// method over secure connections, e.g. those encrypted using SSL.
 	RegisterAuthMethod("password", authPassword, hba.ConnAny, nil)
 
+	// The "scram-sha-256" method performs a SCRAM-SHA-256 exchange,
+	// which does not require the client to send its password.
+	//
+	// This method is only usable for users whose password is stored as
+	// a SCRAM verifier; see the cluster setting
+	// server.user_login.password_encryption.
+	RegisterAuthMethod("scram-sha-256", authScram, hba.ConnAny, nil)
+
 	// The "cert" method requires a valid client certificate for the
 	// user attempting to connect.
 	//
 	// This method is only usable over SSL connections.
-	RegisterAuthMethod("cert", authCert, hba.ConnHostSSL, nil)
+	//
+	// The option map=<name> selects the identity map used to translate
+	// the principals of the certificate into SQL users.
+	RegisterAuthMethod("cert", authCert, hba.ConnHostSSL, checkCertMapEntry)
 
 	// The "cert-password" method requires either a valid client
 	// certificate for the connecting user, or, if no cert is provided,
 	// a cleartext password.
-	RegisterAuthMethod("cert-password", authCertPassword, hba.ConnAny, nil)
+	RegisterAuthMethod("cert-password", authCertPassword, hba.ConnAny, checkCertMapEntry)
 
 	// The "reject" method rejects any connection attempt that matches
 	// the current rule.
@@ -72,6 +86,7 @@ type AuthMethod func(
 	pwValidUntilFn PasswordValidUntilFn,
 	execCfg *sql.ExecutorConfig,
 	entry *hba.Entry,
+	identMap *identmap.Conf,
 ) (security.UserAuthHook, error)
 
 // PasswordRetrievalFn defines a method to retrieve the hashed
@@ -90,6 +105,7 @@ func authPassword(
 	pwValidUntilFn PasswordValidUntilFn,
 	_ *sql.ExecutorConfig,
 	_ *hba.Entry,
+	_ *identmap.Conf,
 ) (security.UserAuthHook, error) {
 	if err := c.SendAuthRequest(authCleartextPassword, nil /* data */); err != nil {
 		return nil, err
@@ -110,20 +126,156 @@ func authPassword(
 		c.LogAuthInfof(ctx, "user has no password defined")
 	}
 
+	if err := checkPasswordNotExpired(ctx, c, pwValidUntilFn); err != nil {
+		return nil, err
+	}
+
+	return security.UserAuthPasswordHook(
+		false /*insecure*/, password, hashedPassword,
+	), nil
+}
+
+// checkPasswordNotExpired returns an error if the user's password has
+// expired.
+func checkPasswordNotExpired(
+	ctx context.Context, c AuthConn, pwValidUntilFn PasswordValidUntilFn,
+) error {
 	validUntil, err := pwValidUntilFn(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if validUntil != nil {
 		if validUntil.Sub(timeutil.Now()) < 0 {
 			c.LogAuthFailed(ctx, eventpb.AuthFailReason_CREDENTIALS_EXPIRED, nil)
-			return nil, errors.New("password is expired")
+			return errors.New("password is expired")
 		}
 	}
+	return nil
+}
 
-	return security.UserAuthPasswordHook(
-		false /*insecure*/, password, hashedPassword,
-	), nil
+func authScram(
+	ctx context.Context,
+	c AuthConn,
+	_ tls.ConnectionState,
+	pwRetrieveFn PasswordRetrievalFn,
+	pwValidUntilFn PasswordValidUntilFn,
+	execCfg *sql.ExecutorConfig,
+	_ *hba.Entry,
+	_ *identmap.Conf,
+) (security.UserAuthHook, error) {
+	hashedPassword, err := pwRetrieveFn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var verifier security.ScramVerifier
+	mock := true
+	switch {
+	case len(hashedPassword) == 0:
+		c.LogAuthInfof(ctx, "user has no password defined")
+	case !security.IsScramHash(hashedPassword):
+		// The password was stored before the cluster was configured to
+		// store SCRAM verifiers. The user needs to reset their password
+		// before they can use this method.
+		c.LogAuthInfof(ctx, "user password is not stored as a SCRAM verifier")
+	default:
+		if verifier, err = security.ParseScramVerifier(hashedPassword); err != nil {
+			return nil, err
+		}
+		if err := checkPasswordNotExpired(ctx, c, pwValidUntilFn); err != nil {
+			return nil, err
+		}
+		mock = false
+	}
+	if mock {
+		// Run the exchange with a verifier that no password matches, so that
+		// the client cannot tell users that can't authenticate with SCRAM from
+		// users that provide a wrong password.
+		verifier = security.MockScramVerifier(execCfg.ClusterID().GetBytes(), c.User().Normalized())
+	}
+
+	// Advertise the supported mechanisms: a list of null-terminated
+	// names, itself terminated by an empty name.
+	if err := c.SendAuthRequest(
+		authSASL, []byte(security.ScramMechanismName+"\x00\x00"),
+	); err != nil {
+		return nil, err
+	}
+	initialResponse, err := c.GetPwdData()
+	if err != nil {
+		return nil, err
+	}
+	mechanism, clientFirst, err := parseSASLInitialResponse(initialResponse)
+	if err != nil {
+		return nil, err
+	}
+	if mechanism != security.ScramMechanismName {
+		return nil, pgwirebase.NewProtocolViolationErrorf(
+			"client selected an invalid SASL authentication mechanism: %q", mechanism)
+	}
+
+	exchange := security.NewScramServerExchange(verifier)
+	serverFirst, err := exchange.HandleClientFirst(clientFirst)
+	if err != nil {
+		return nil, pgwirebase.NewProtocolViolationErrorf("%v", err)
+	}
+	if err := c.SendAuthRequest(authSASLContinue, serverFirst); err != nil {
+		return nil, err
+	}
+	clientFinal, err := c.GetPwdData()
+	if err != nil {
+		return nil, err
+	}
+	serverFinal, err := exchange.HandleClientFinal(clientFinal)
+	if err != nil {
+		if errors.Is(err, security.ErrScramProofMismatch) {
+			return authFailedHook(), nil
+		}
+		return nil, pgwirebase.NewProtocolViolationErrorf("%v", err)
+	}
+	if mock {
+		return authFailedHook(), nil
+	}
+	if err := c.SendAuthRequest(authSASLFinal, serverFinal); err != nil {
+		return nil, err
+	}
+
+	return func(requestedUser security.SQLUsername, clientConnection bool) (func(), error) {
+		if !clientConnection {
+			return nil, errors.New("password authentication is only available for client connections")
+		}
+		return nil, nil
+	}, nil
+}
+
+// authFailedHook returns an authentication hook which reports that the
+// password authentication failed. It is used to avoid revealing to the
+// client why the authentication failed.
+func authFailedHook() security.UserAuthHook {
+	return func(requestedUser security.SQLUsername, _ bool) (func(), error) {
+		return nil, errors.Errorf(security.ErrPasswordUserAuthFailed, requestedUser)
+	}
+}
+
+// parseSASLInitialResponse decodes the payload of a SASLInitialResponse
+// message: the name of the selected mechanism as a null-terminated
+// string, followed by the length-prefixed mechanism-specific initial
+// response.
+func parseSASLInitialResponse(data []byte) (mechanism string, response []byte, _ error) {
+	idx := bytes.IndexByte(data, 0)
+	if idx < 0 {
+		return "", nil, pgwirebase.NewProtocolViolationErrorf("malformed SASLInitialResponse message")
+	}
+	mechanism = string(data[:idx])
+	data = data[idx+1:]
+	if len(data) < 4 {
+		return "", nil, pgwirebase.NewProtocolViolationErrorf("malformed SASLInitialResponse message")
+	}
+	n := int32(binary.BigEndian.Uint32(data))
+	data = data[4:]
+	if n < 0 || int(n) != len(data) {
+		return "", nil, pgwirebase.NewProtocolViolationErrorf("malformed SASLInitialResponse message")
+	}
+	return mechanism, data, nil
 }
 
 func passwordString(pwdData []byte) (string, error) {
@@ -141,7 +293,8 @@ func authCert(
 	_ PasswordRetrievalFn,
 	_ PasswordValidUntilFn,
 	_ *sql.ExecutorConfig,
-	_ *hba.Entry,
+	entry *hba.Entry,
+	identMap *identmap.Conf,
 ) (security.UserAuthHook, error) {
 	if len(tlsState.PeerCertificates) == 0 {
 		return nil, errors.New("no TLS peer certificates, but required for auth")
@@ -150,7 +303,16 @@ func authCert(
 	tlsState.PeerCertificates[0].Subject.CommonName = tree.Name(
 		tlsState.PeerCertificates[0].Subject.CommonName,
 	).Normalize()
-	return security.UserAuthCertHook(false /*insecure*/, &tlsState)
+
+	// If the HBA rule specifies an identity map, the principals in the
+	// certificate are mapped to the SQL users they can log in as.
+	var certUserMapper security.CertUserMapper
+	if mapName := entry.GetOption("map"); mapName != "" {
+		certUserMapper = func(certUser string) ([]security.SQLUsername, error) {
+			return identMap.Map(mapName, certUser)
+		}
+	}
+	return security.UserAuthCertHook(false /*insecure*/, &tlsState, certUserMapper)
 }
 
 func authCertPassword(
@@ -161,6 +323,7 @@ func authCertPassword(
 	pwValidUntilFn PasswordValidUntilFn,
 	execCfg *sql.ExecutorConfig,
 	entry *hba.Entry,
+	identMap *identmap.Conf,
 ) (security.UserAuthHook, error) {
 	var fn AuthMethod
 	if len(tlsState.PeerCertificates) == 0 {
@@ -170,7 +333,7 @@ func authCertPassword(
 		c.LogAuthInfof(ctx, "client presented certificate, proceeding with certificate validation")
 		fn = authCert
 	}
-	return fn(ctx, c, tlsState, pwRetrieveFn, pwValidUntilFn, execCfg, entry)
+	return fn(ctx, c, tlsState, pwRetrieveFn, pwValidUntilFn, execCfg, entry, identMap)
 }
 
 func authTrust(
@@ -181,6 +344,7 @@ func authTrust(
 	_ PasswordValidUntilFn,
 	_ *sql.ExecutorConfig,
 	_ *hba.Entry,
+	_ *identmap.Conf,
 ) (security.UserAuthHook, error) {
 	return func(_ security.SQLUsername, _ bool) (func(), error) { return nil, nil }, nil
 }
@@ -193,6 +357,7 @@ func authReject(
 	_ PasswordValidUntilFn,
 	_ *sql.ExecutorConfig,
 	_ *hba.Entry,
+	_ *identmap.Conf,
 ) (security.UserAuthHook, error) {
 	return func(_ security.SQLUsername, _ bool) (func(), error) {
 		return nil, errors.New("authentication rejected by configuration")
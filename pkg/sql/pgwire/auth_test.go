@@ -32,6 +32,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/identmap"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
@@ -69,6 +70,13 @@ import (
 //       The expected output is the configuration after parsing
 //       and reloading in the server.
 //
+// set_identity_map
+// <identity map config>
+//       Load the provided identity map configuration via the cluster
+//       setting server.identity_map.configuration.
+//       The expected output is the configuration after parsing
+//       and reloading in the server.
+//
 // sql
 // <sql input>
 //       Execute the specified SQL statement using the default root
@@ -107,6 +115,10 @@ import (
 //       sslmode also gets a default of "verify-full". For other
 //       users, sslmode is initialized by default to "verify-ca".
 //
+//       The special key cert_name=<user> selects the client
+//       certificate of another user than the one requested, for
+//       example to test identity maps.
+//
 // For the directives "sql" and "connect", the expected output can be
 // either "ok" (no error) or "ERROR:" followed by the expected error
 // string.
@@ -268,6 +280,31 @@ func hbaRunTest(t *testing.T, insecure bool) {
 					}
 					return string(body), nil
 
+				case "set_identity_map":
+					_, err := conn.ExecContext(context.Background(),
+						`SET CLUSTER SETTING server.identity_map.configuration = $1`, td.Input)
+					if err != nil {
+						return "", err
+					}
+
+					// Wait until the configuration has propagated back to the
+					// test client, like for set_hba above.
+					expConf, err := identmap.From(strings.NewReader(td.Input))
+					if err != nil {
+						// The SET above succeeded so we don't expect a problem here.
+						t.Fatal(err)
+					}
+					testutils.SucceedsSoon(t, func() error {
+						curConf := pgServer.GetIdentityMapConfiguration()
+						if expConf.String() != curConf.String() {
+							return errors.Newf(
+								"identity map not yet loaded\ngot:\n%s\nexpected:\n%s",
+								curConf, expConf)
+						}
+						return nil
+					})
+					return expConf.String(), nil
+
 				case "sql":
 					_, err := conn.ExecContext(context.Background(), td.Input)
 					return "ok", err
@@ -362,11 +399,17 @@ func hbaRunTest(t *testing.T, insecure bool) {
 						td.ScanArgs(t, "user", &user)
 					}
 
+					// Which user's certificate should be presented?
+					certName := user
+					if td.HasArg("cert_name") {
+						td.ScanArgs(t, "cert_name", &certName)
+					}
+
 					// We want the certs to be present in the filesystem for this test.
 					// However, certs are only generated for users "root" and "testuser" specifically.
 					sqlURL, cleanupFn := sqlutils.PGUrlWithOptionalClientCerts(
-						t, s.ServingSQLAddr(), t.Name(), url.User(user),
-						user == security.RootUser || user == security.TestUser /* withClientCerts */)
+						t, s.ServingSQLAddr(), t.Name(), url.User(certName),
+						certName == security.RootUser || certName == security.TestUser /* withClientCerts */)
 					defer cleanupFn()
 
 					var host, port string
@@ -408,7 +451,7 @@ func hbaRunTest(t *testing.T, insecure bool) {
 					sp := ""
 					seenKeys := map[string]struct{}{}
 					for _, a := range args {
-						if _, ok := seenKeys[a.Key]; ok {
+						if _, ok := seenKeys[a.Key]; ok || a.Key == "cert_name" {
 							continue
 						}
 						seenKeys[a.Key] = struct{}{}
@@ -57,6 +57,7 @@ type commandResult struct {
 	buffer struct {
 		notices            []pgnotice.Notice
 		paramStatusUpdates []paramStatusUpdate
+		notifications      []sql.Notification
 	}
 
 	err error
@@ -137,6 +138,12 @@ func (r *commandResult) Close(ctx context.Context, t sql.TransactionStatusIndica
 		}
 	}
 
+	for _, notification := range r.buffer.notifications {
+		if err := r.conn.bufferNotification(notification); err != nil {
+			panic(errors.AssertionFailedf("unexpected err when sending notification: %s", err))
+		}
+	}
+
 	// Send a completion message, specific to the type of result.
 	switch r.typ {
 	case commandComplete:
@@ -153,12 +160,12 @@ func (r *commandResult) Close(ctx context.Context, t sql.TransactionStatusIndica
 	case readyForQuery:
 		r.conn.bufferReadyForQuery(byte(t))
 		// The error is saved on conn.err.
-		_ /* err */ = r.conn.Flush(r.pos)
+		_ /* err */ = r.conn.Flush(ctx, r.pos)
 	case emptyQueryResponse:
 		r.conn.bufferEmptyQueryResponse()
 	case flush:
 		// The error is saved on conn.err.
-		_ /* err */ = r.conn.Flush(r.pos)
+		_ /* err */ = r.conn.Flush(ctx, r.pos)
 	case noCompletionMsg:
 		// nothing to do
 	default:
@@ -206,9 +213,9 @@ func (r *commandResult) AddRow(ctx context.Context, row tree.Datums) error {
 	r.conn.bufferRow(ctx, row, r.formatCodes, r.conv, r.location, r.types)
 	var err error
 	if r.bufferingDisabled {
-		err = r.conn.Flush(r.pos)
+		err = r.conn.Flush(ctx, r.pos)
 	} else {
-		_ /* flushed */, err = r.conn.maybeFlush(r.pos)
+		_ /* flushed */, err = r.conn.maybeFlush(ctx, r.pos)
 	}
 	return err
 }
@@ -232,6 +239,11 @@ func (r *commandResult) BufferNotice(notice pgnotice.Notice) {
 	r.buffer.notices = append(r.buffer.notices, notice)
 }
 
+// BufferNotification is part of the SyncResult interface.
+func (r *commandResult) BufferNotification(notification sql.Notification) {
+	r.buffer.notifications = append(r.buffer.notifications, notification)
+}
+
 // SetColumns is part of the CommandResult interface.
 func (r *commandResult) SetColumns(ctx context.Context, cols colinfo.ResultColumns) {
 	r.assertNotReleased()
@@ -353,6 +365,7 @@ func (c *conn) newCommandResult(
 		limit:         limit,
 		portalName:    portalName,
 		implicitTxn:   implicitTxn,
+		isMutation:    isMutation(stmt),
 		commandResult: r,
 	}
 }
@@ -395,6 +408,20 @@ type limitedCommandResult struct {
 	// If set, an error will be sent to the client if more rows are produced than
 	// this limit.
 	limit int
+	// isMutation is set if the statement is an INSERT, UPSERT, UPDATE or
+	// DELETE (with a RETURNING clause). Like in Postgres, the command tag of a
+	// mutation reports all the rows affected by the statement, rather than
+	// only the rows returned by the last execution of the portal.
+	isMutation bool
+}
+
+// isMutation returns whether the statement modifies rows of a table.
+func isMutation(stmt tree.Statement) bool {
+	switch stmt.(type) {
+	case *tree.Insert, *tree.Update, *tree.Delete:
+		return true
+	}
+	return false
 }
 
 // AddRow is part of the CommandResult interface.
@@ -408,14 +435,14 @@ func (r *limitedCommandResult) AddRow(ctx context.Context, row tree.Datums) erro
 		// If we've seen up to the limit of rows, send a "portal suspended" message
 		// and wait for another exec portal message.
 		r.conn.bufferPortalSuspended()
-		if err := r.conn.Flush(r.pos); err != nil {
+		if err := r.conn.Flush(ctx, r.pos); err != nil {
 			return err
 		}
 		r.seenTuples = 0
 
 		return r.moreResultsNeeded(ctx)
 	}
-	if _ /* flushed */, err := r.conn.maybeFlush(r.pos); err != nil {
+	if _ /* flushed */, err := r.conn.maybeFlush(ctx, r.pos); err != nil {
 		return err
 	}
 	return nil
@@ -467,8 +494,11 @@ func (r *limitedCommandResult) moreResultsNeeded(ctx context.Context) error {
 					"cannot execute a portal while a different one is open")
 			}
 			r.limit = c.Limit
-			// In order to get the correct command tag, we need to reset the seen rows.
-			r.rowsAffected = 0
+			// In order to get the correct command tag, we need to reset the seen
+			// rows, except for mutations which report all the affected rows.
+			if !r.isMutation {
+				r.rowsAffected = 0
+			}
 			return nil
 		case sql.Sync:
 			// The client wants to see a ready for query message
@@ -478,7 +508,7 @@ func (r *limitedCommandResult) moreResultsNeeded(ctx context.Context) error {
 			// support implicit transactions, so we know we're in
 			// a transaction.
 			r.conn.bufferReadyForQuery(byte(sql.InTxnBlock))
-			if err := r.conn.Flush(r.pos); err != nil {
+			if err := r.conn.Flush(ctx, r.pos); err != nil {
 				return err
 			}
 		default:
@@ -0,0 +1,102 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgwire
+
+import (
+	"compress/gzip"
+	"net"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+// This file implements the compression of the data sent to the client.
+//
+// Compression is negotiated with the "crdb:compression" connection
+// parameter, which lists the compression algorithms supported by the client
+// in order of preference, e.g. "gzip". If the server supports one of them, it
+// reports the algorithm that it picked with a ParameterStatus message named
+// "crdb:compression" during the connection handshake, and everything that it
+// sends after the initial ReadyForQuery message is compressed. The data sent
+// by the client is never compressed.
+//
+// Servers which do not support compression, or on which it is disabled by the
+// server.pgwire.compression.enabled cluster setting, ignore the connection
+// parameter and thus do not send the ParameterStatus message, in which case
+// nothing is compressed.
+//
+// Compression is disabled by default because compressing data before it is
+// encrypted by TLS exposes it to side-channel attacks like CRIME and BREACH:
+// an attacker who can influence part of the results, e.g. a value stored in a
+// table, and observe the size of the encrypted traffic can recover other
+// parts of the results that are sent along with it.
+
+// compressionEnabled allows clients to request the compression of the data
+// sent to them.
+var compressionEnabled = settings.RegisterBoolSetting(
+	"server.pgwire.compression.enabled",
+	"if set, clients can request the compression of the results sent to them with "+
+		"the 'crdb:compression' connection parameter (note: compressing results that "+
+		"mix secret and attacker-controlled data before they are encrypted can leak "+
+		"the secret data through the size of the traffic)",
+	false,
+)
+
+// compressionParam is the name of the connection parameter and of the status
+// parameter used to negotiate compression.
+const compressionParam = "crdb:compression"
+
+// compressionGzip is the name of the gzip compression algorithm.
+const compressionGzip = "gzip"
+
+// negotiateCompression returns the compression algorithm to use given the
+// value of the compressionParam connection parameter, or an empty string if
+// none of the algorithms requested by the client are supported.
+func negotiateCompression(value string) string {
+	for _, algorithm := range strings.Split(value, ",") {
+		if algorithm = strings.ToLower(strings.TrimSpace(algorithm)); algorithm == compressionGzip {
+			return algorithm
+		}
+	}
+	return ""
+}
+
+// compressedConn is a net.Conn which compresses the data written to it once
+// compression is enabled.
+type compressedConn struct {
+	net.Conn
+	// zw compresses the data written to the connection. It is nil until
+	// compression is enabled.
+	zw *gzip.Writer
+}
+
+// enableCompression causes the data written after this call to be
+// compressed.
+func (c *compressedConn) enableCompression() {
+	// BestSpeed is used because the results are generally flushed as soon as
+	// they are produced; a better ratio is not worth the latency.
+	c.zw, _ = gzip.NewWriterLevel(c.Conn, gzip.BestSpeed)
+}
+
+// Write is part of the net.Conn interface.
+func (c *compressedConn) Write(b []byte) (int, error) {
+	if c.zw == nil {
+		return c.Conn.Write(b)
+	}
+	n, err := c.zw.Write(b)
+	if err != nil {
+		return n, err
+	}
+	// Flush the compressor so that the client can decompress everything
+	// written so far, e.g. the results of a query, without waiting for more
+	// data.
+	return n, c.zw.Flush()
+}
@@ -82,7 +82,10 @@ type conn struct {
 		fi flushInfo
 		// buf contains command results (rows, etc.) until they're flushed to the
 		// network connection.
-		buf    bytes.Buffer
+		buf bytes.Buffer
+		// bufAcc, if set, accounts for the memory used by buf. The buffer is
+		// flushed early if it can't be grown.
+		bufAcc *mon.BoundAccount
 		tagBuf [64]byte
 	}
 
@@ -93,6 +96,10 @@ type conn struct {
 
 	// alwaysLogAuthActivity is used force-enables logging of authn events.
 	alwaysLogAuthActivity bool
+
+	// compressedConn, if set, wraps the network connection to compress the data sent to the
+	// client once the connection is established. See compression.go.
+	compressedConn *compressedConn
 }
 
 // serveConn creates a conn that will serve the netConn. It returns once the
@@ -147,6 +154,13 @@ func (s *Server) serveConn(
 	c := newConn(netConn, sArgs, &s.metrics, &s.execCfg.Settings.SV)
 	c.alwaysLogAuthActivity = alwaysLogAuthActivity || atomic.LoadInt32(&s.testingAuthLogEnabled) > 0
 
+	// Account for the results buffered before being flushed to the network.
+	// serveImpl only returns after the command processor is done, so the
+	// account can be closed here.
+	bufAcc := s.connMonitor.MakeBoundAccount()
+	defer bufAcc.Close(ctx)
+	c.writerState.bufAcc = &bufAcc
+
 	// Do the reading of commands from the network.
 	c.serveImpl(ctx, s.IsDraining, s.SQLServer, reserved, authOpt)
 }
@@ -175,6 +189,10 @@ func newConn(
 	c.writerState.fi.lastFlushed = -1
 	c.writerState.fi.cmdStarts = make(map[sql.CmdPos]int)
 	c.msgBuilder.init(metrics.BytesOutCount)
+	if sArgs.Compression != "" {
+		c.compressedConn = &compressedConn{Conn: netConn}
+		c.conn = c.compressedConn
+	}
 
 	return c
 }
@@ -485,15 +503,23 @@ Loop:
 	// was completed already.
 	authenticator.noMorePwdData()
 
-	// Wait for the processor goroutine to finish, if it hasn't already. We're
-	// ignoring the error we get from it, as we have no use for it. It might be a
-	// connection error, or a context cancelation error case this goroutine is the
-	// one that triggered the execution to stop.
-	<-procCh
+	// Wait for the processor goroutine to finish, if it hasn't already. The
+	// error we get from it is generally ignored, as we have no use for it. It
+	// might be a connection error, or a context cancelation error case this
+	// goroutine is the one that triggered the execution to stop.
+	procErr := <-procCh
 
 	if terminateSeen {
 		return
 	}
+	// If the session was terminated because of a timeout, let the client know
+	// why the connection is being closed.
+	if isSessionTimeoutErr(procErr) {
+		_ /* err */ = writeErr(ctx, &sqlServer.GetExecutorConfig().Settings.SV,
+			procErr, &c.msgBuilder, &c.writerState.buf)
+		_ /* n */, _ /* err */ = c.writerState.buf.WriteTo(c.conn)
+		return
+	}
 	// If we're draining, let the client know by piling on an AdminShutdownError
 	// and flushing the buffer.
 	if draining() {
@@ -507,6 +533,17 @@ Loop:
 	}
 }
 
+// isSessionTimeoutErr returns true if err is the error with which a session
+// is terminated because of idle_in_session_timeout or
+// idle_in_transaction_session_timeout.
+func isSessionTimeoutErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	code := pgerror.GetPGCode(err)
+	return code == pgcode.IdleSessionTimeout || code == pgcode.IdleInTransactionSessionTimeout
+}
+
 // unqualifiedIntSizer is used by a conn to get the SQL session's current int size
 // setting.
 //
@@ -614,6 +651,17 @@ func (c *conn) processCommandsAsync(
 			return
 		}
 
+		// Now that the user is known, enforce the connection limits.
+		if authOpt.connLimiter != nil {
+			var releaseConn func()
+			if releaseConn, retErr = authOpt.connLimiter.acquire(c.sessionArgs.User); retErr != nil {
+				_ = writeErr(ctx, &sqlServer.GetExecutorConfig().Settings.SV, retErr,
+					&c.msgBuilder, c.conn)
+				return
+			}
+			defer releaseConn()
+		}
+
 		// Inform the client of the default session settings.
 		connHandler, retErr = c.sendInitialConnData(ctx, sqlServer)
 		if retErr != nil {
@@ -652,6 +700,14 @@ func (c *conn) bufferNotice(ctx context.Context, noticeErr pgnotice.Notice) erro
 	return writeErrFields(ctx, c.sv, noticeErr, &c.msgBuilder, &c.writerState.buf)
 }
 
+func (c *conn) bufferNotification(notification sql.Notification) error {
+	c.msgBuilder.initMsg(pgwirebase.ServerMsgNotificationResponse)
+	c.msgBuilder.putInt32(notification.PID)
+	c.msgBuilder.writeTerminatedString(notification.Channel)
+	c.msgBuilder.writeTerminatedString(notification.Payload)
+	return c.msgBuilder.finishMsg(&c.writerState.buf)
+}
+
 func (c *conn) sendInitialConnData(
 	ctx context.Context, sqlServer *sql.Server,
 ) (sql.ConnectionHandler, error) {
@@ -693,12 +749,46 @@ func (c *conn) sendInitialConnData(
 		return sql.ConnectionHandler{}, err
 	}
 
+	// Warn the client about the connection parameters that were ignored.
+	for _, param := range c.sessionArgs.IgnoredParameters {
+		notice := pgnotice.NewWithSeverityf("WARNING",
+			"unrecognized configuration parameter %q ignored", param)
+		if !connHandler.ShouldSendNotice(notice) {
+			continue
+		}
+		c.msgBuilder.initMsg(pgwirebase.ServerMsgNoticeResponse)
+		if err := writeErrFields(ctx, c.sv, notice, &c.msgBuilder, c.conn); err != nil {
+			return sql.ConnectionHandler{}, err
+		}
+	}
+
+	// Send the key that the client can use to cancel the queries of the
+	// session.
+	pid, secret := connHandler.GetQueryCancelKey().GetPGWireCancelInfo()
+	c.msgBuilder.initMsg(pgwirebase.ServerMsgBackendKeyData)
+	c.msgBuilder.putInt32(pid)
+	c.msgBuilder.putInt32(secret)
+	if err := c.msgBuilder.finishMsg(c.conn); err != nil {
+		return sql.ConnectionHandler{}, err
+	}
+
+	// Let the client know that the data sent after the handshake is
+	// compressed.
+	if c.compressedConn != nil {
+		if err := c.sendParamStatus(compressionParam, c.sessionArgs.Compression); err != nil {
+			return sql.ConnectionHandler{}, err
+		}
+	}
+
 	// An initial readyForQuery message is part of the handshake.
 	c.msgBuilder.initMsg(pgwirebase.ServerMsgReady)
 	c.msgBuilder.writeByte(byte(sql.IdleTxnBlock))
 	if err := c.msgBuilder.finishMsg(c.conn); err != nil {
 		return sql.ConnectionHandler{}, err
 	}
+	if c.compressedConn != nil {
+		c.compressedConn.enableCompression()
+	}
 	return connHandler, nil
 }
 
@@ -1353,7 +1443,7 @@ func (c *conn) writeRowDescription(
 // Flush is part of the ClientComm interface.
 //
 // In case conn.err is set, this is a no-op - the previous err is returned.
-func (c *conn) Flush(pos sql.CmdPos) error {
+func (c *conn) Flush(ctx context.Context, pos sql.CmdPos) error {
 	// Check that there were no previous network errors. If there were, we'd
 	// probably also fail the write below, but this check is here to make
 	// absolutely sure that we don't send some results after we previously had
@@ -1366,6 +1456,11 @@ func (c *conn) Flush(pos sql.CmdPos) error {
 	c.writerState.fi.cmdStarts = make(map[sql.CmdPos]int)
 
 	_ /* n */, err := c.writerState.buf.WriteTo(c.conn)
+	if c.writerState.bufAcc != nil {
+		// Release the memory of the results that left the buffer. Shrinking an
+		// account can't fail.
+		_ = c.writerState.bufAcc.ResizeTo(ctx, int64(c.writerState.buf.Len()))
+	}
 	if err != nil {
 		c.setErr(err)
 		return err
@@ -1374,12 +1469,23 @@ func (c *conn) Flush(pos sql.CmdPos) error {
 }
 
 // maybeFlush flushes the buffer to the network connection if it exceeded
-// sessionArgs.ConnResultsBufferSize.
-func (c *conn) maybeFlush(pos sql.CmdPos) (bool, error) {
-	if int64(c.writerState.buf.Len()) <= c.sessionArgs.ConnResultsBufferSize {
-		return false, nil
+// sessionArgs.ConnResultsBufferSize, or if the memory it uses can't be
+// reserved. In the latter case, results are streamed to the client before the
+// buffer limit is reached, which prevents the statement from being
+// automatically retried but keeps the node from running out of memory.
+func (c *conn) maybeFlush(ctx context.Context, pos sql.CmdPos) (bool, error) {
+	bufLen := int64(c.writerState.buf.Len())
+	if bufLen <= c.sessionArgs.ConnResultsBufferSize {
+		if c.writerState.bufAcc == nil {
+			return false, nil
+		}
+		err := c.writerState.bufAcc.ResizeTo(ctx, bufLen)
+		if err == nil {
+			return false, nil
+		}
+		log.VEventf(ctx, 2, "flushing %d bytes of buffered results early: %v", bufLen, err)
 	}
-	return true, c.Flush(pos)
+	return true, c.Flush(ctx, pos)
 }
 
 // LockCommunication is part of the ClientComm interface.
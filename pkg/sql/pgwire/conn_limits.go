@@ -0,0 +1,122 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgwire
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+)
+
+// The names of the cluster settings that limit the number of connections.
+const (
+	maxConnectionsPerNodeSetting = "server.max_connections_per_gateway"
+	maxConnectionsPerUserSetting = "server.max_connections_per_user"
+)
+
+// maxConnectionsPerNode is the maximum number of SQL connections that a node
+// accepts at a given time.
+var maxConnectionsPerNode = settings.RegisterIntSetting(
+	maxConnectionsPerNodeSetting,
+	"the maximum number of SQL connections per node allowed at a given time "+
+		"(note: this will only limit future connection attempts and will not affect already "+
+		"established connections). Negative values result in unlimited number of connections. "+
+		"Connections of the root user are not affected by this limit.",
+	-1,
+).WithPublic()
+
+// maxConnectionsPerUser is the maximum number of SQL connections that a node
+// accepts for a single user at a given time.
+var maxConnectionsPerUser = settings.RegisterIntSetting(
+	maxConnectionsPerUserSetting,
+	"the maximum number of SQL connections per node allowed for a single user at a given time "+
+		"(note: this will only limit future connection attempts and will not affect already "+
+		"established connections). Negative values result in unlimited number of connections. "+
+		"Connections of the root user are not affected by this limit.",
+	-1,
+).WithPublic()
+
+// MetaConnsRejected is the metadata of the counter of the connections
+// rejected because of the connection limits.
+var MetaConnsRejected = metric.Metadata{
+	Name:        "sql.conns_rejected",
+	Help:        "Counter of the number of sql connections rejected because of the connection limits",
+	Measurement: "Connections",
+	Unit:        metric.Unit_COUNT,
+}
+
+// connLimiter enforces the limits on the number of authenticated SQL
+// connections of a node.
+type connLimiter struct {
+	sv       *settings.Values
+	rejected *metric.Counter
+
+	mu struct {
+		syncutil.Mutex
+		// numConns is the number of authenticated connections.
+		numConns int64
+		// connsByUser is the number of authenticated connections of each user.
+		connsByUser map[security.SQLUsername]int64
+	}
+}
+
+func newConnLimiter(sv *settings.Values, rejected *metric.Counter) *connLimiter {
+	l := &connLimiter{sv: sv, rejected: rejected}
+	l.mu.connsByUser = make(map[security.SQLUsername]int64)
+	return l
+}
+
+// acquire accounts for a new connection of the given user. If the
+// connection would exceed the limits, a TooManyConnections error is
+// returned. Otherwise, the returned function must be called when the
+// connection is closed.
+func (l *connLimiter) acquire(user security.SQLUsername) (release func(), _ error) {
+	// The root and node users are not subject to the limits, so that an
+	// administrator can always connect to the node.
+	exempt := user.IsRootUser() || user.IsNodeUser()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !exempt {
+		if limit := maxConnectionsPerNode.Get(l.sv); limit >= 0 && l.mu.numConns >= limit {
+			l.rejected.Inc(1)
+			return nil, errors.WithHintf(
+				pgerror.New(pgcode.TooManyConnections, "sorry, too many clients already"),
+				"the maximum number of connections per node is %d and can be modified using the %s cluster setting",
+				limit, maxConnectionsPerNodeSetting,
+			)
+		}
+		if limit := maxConnectionsPerUser.Get(l.sv); limit >= 0 && l.mu.connsByUser[user] >= limit {
+			l.rejected.Inc(1)
+			return nil, errors.WithHintf(
+				pgerror.Newf(pgcode.TooManyConnections, "too many connections for user %s", user),
+				"the maximum number of connections per user is %d and can be modified using the %s cluster setting",
+				limit, maxConnectionsPerUserSetting,
+			)
+		}
+	}
+	l.mu.numConns++
+	l.mu.connsByUser[user]++
+
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.mu.numConns--
+		l.mu.connsByUser[user]--
+		if l.mu.connsByUser[user] == 0 {
+			delete(l.mu.connsByUser, user)
+		}
+	}, nil
+}
@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"net"
 	"net/url"
 	"strconv"
@@ -27,6 +28,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/hba"
@@ -1066,6 +1068,47 @@ func TestMaliciousInputs(t *testing.T) {
 	}
 }
 
+// TestConnFlushReleasesBufferMemory verifies that the memory accounted for
+// the results buffer is released whenever the buffer is flushed, and not only
+// when it is flushed because it grew too large.
+func TestConnFlushReleasesBufferMemory(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	go func() { _, _ = io.Copy(ioutil.Discard, client) }()
+
+	sqlMetrics := sql.MakeMemMetrics("test" /* endpoint */, time.Second /* histogramWindow */)
+	metrics := makeServerMetrics(sqlMetrics, time.Second /* histogramWindow */)
+	c := newConn(server, sql.SessionArgs{ConnResultsBufferSize: 16 << 10}, &metrics, nil /* sv */)
+
+	st := cluster.MakeTestingClusterSettings()
+	monitor := mon.NewMonitor(
+		"test", mon.MemoryResource, nil /* curCount */, nil /* maxHist */, 1 /* increment */, math.MaxInt64, st,
+	)
+	monitor.Start(ctx, nil /* pool */, mon.MakeStandaloneBudget(math.MaxInt64))
+	defer monitor.Stop(ctx)
+	acc := monitor.MakeBoundAccount()
+	defer acc.Close(ctx)
+	c.writerState.bufAcc = &acc
+
+	// The buffer is below the limit, so it is only accounted for.
+	c.writerState.buf.WriteString("buffered results")
+	flushed, err := c.maybeFlush(ctx, 0 /* pos */)
+	require.NoError(t, err)
+	require.False(t, flushed)
+	require.Equal(t, int64(c.writerState.buf.Len()), acc.Used())
+
+	// Flushing the buffer directly, as is done at the end of a batch of
+	// commands, releases its memory as well.
+	require.NoError(t, c.Flush(ctx, 0 /* pos */))
+	require.Zero(t, c.writerState.buf.Len())
+	require.Zero(t, acc.Used())
+}
+
 // TestReadTimeoutConn asserts that a readTimeoutConn performs reads normally
 // and exits with an appropriate error when exit conditions are satisfied.
 func TestReadTimeoutConnExits(t *testing.T) {
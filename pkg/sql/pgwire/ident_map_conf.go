@@ -0,0 +1,105 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgwire
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/hba"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/identmap"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// This file contains the logic for the configuration of identity
+// maps, which map the identities established by the authentication
+// methods to SQL users.
+//
+// Administrators customize the cluster setting
+// `server.identity_map.configuration`, using a syntax derived from
+// PostgreSQL's pg_ident.conf (see package identmap). An identity map
+// is then applied to the connections matching an HBA rule using the
+// `map` option, for example:
+//
+//     host all all all cert map=corp
+//
+// For the cert-based methods, the principals of the client certificate
+// are mapped to the SQL users that they are allowed to log in as.
+
+// serverIdentityMapSetting is the name of the cluster setting that
+// holds the identity map configuration.
+const serverIdentityMapSetting = "server.identity_map.configuration"
+
+// connIdentityMapConf is the cluster setting that holds the identity
+// map configuration.
+var connIdentityMapConf = func() *settings.StringSetting {
+	s := settings.RegisterValidatedStringSetting(
+		serverIdentityMapSetting,
+		"system-identity to database-username mappings",
+		"",
+		func(values *settings.Values, s string) error {
+			_, err := identmap.From(strings.NewReader(s))
+			return err
+		},
+	)
+	s.SetVisibility(settings.Public)
+	return s
+}()
+
+// loadLocalIdentityMapUponRemoteSettingChange initializes the local
+// node's cache of the identity map configuration each time the cluster
+// setting is updated.
+func loadLocalIdentityMapUponRemoteSettingChange(
+	ctx context.Context, server *Server, st *cluster.Settings,
+) {
+	val := connIdentityMapConf.Get(&st.SV)
+	idMap, err := identmap.From(strings.NewReader(val))
+	if err != nil {
+		// An empty map is used if the node is unable to load the config
+		// from the cluster setting.
+		log.Warningf(ctx, "invalid %s: %v", serverIdentityMapSetting, err)
+		idMap = identmap.Empty()
+	}
+	server.auth.Lock()
+	defer server.auth.Unlock()
+	server.auth.identityMap = idMap
+}
+
+// GetIdentityMapConfiguration retrieves the current applicable identity
+// map configuration. This is guaranteed to return a valid configuration.
+func (s *Server) GetIdentityMapConfiguration() *identmap.Conf {
+	s.auth.RLock()
+	idMap := s.auth.identityMap
+	s.auth.RUnlock()
+
+	if idMap == nil {
+		// This can happen when using the value for the first time before
+		// the cluster setting has ever been set.
+		idMap = identmap.Empty()
+	}
+	return idMap
+}
+
+// checkCertMapEntry validates the map option of the HBA entries which
+// use certificate authentication.
+func checkCertMapEntry(entry hba.Entry) error {
+	maps := entry.GetOptions("map")
+	if len(maps) > 1 {
+		return errors.New(`the "map" option can only be specified once`)
+	}
+	if len(maps) == 1 && maps[0] == "" {
+		return errors.New(`the "map" option requires a map name`)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package identmap contains the configuration of the identity maps,
+// which translate the identity of a client established by an
+// authentication method (e.g. the principals of a TLS client
+// certificate) into SQL users.
+//
+// The syntax is inspired/derived from that of PostgreSQL's
+// pg_ident.conf:
+// https://www.postgresql.org/docs/12/auth-username-maps.html
+//
+// Each non-empty, non-comment line has the form:
+//
+//     <map-name> <system-identity> <sql-user>
+//
+// If the system identity starts with a slash, the remainder is a
+// regular expression matched against the identity of the client; the
+// SQL user can then refer to the first capture group using \1.
+// Otherwise, the system identity must match exactly.
+package identmap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/errors"
+)
+
+// Conf is a parsed identity map configuration.
+type Conf struct {
+	// data maps the name of an identity map to its rules, in the
+	// order in which they appear in the configuration.
+	data map[string][]element
+	// originalLines is retained for String().
+	originalLines []string
+	// sortedKeys is retained for String().
+	sortedKeys []string
+}
+
+// element is a single rule of an identity map.
+type element struct {
+	// pattern matches the system identity. Exact matches are
+	// represented as anchored, quoted patterns.
+	pattern *regexp.Regexp
+	// substitution is the SQL user; it may contain \1 if the pattern
+	// is a regular expression.
+	substitution string
+}
+
+// linePattern matches the lines of the configuration, ignoring leading
+// and trailing whitespace and comments.
+var linePattern = regexp.MustCompile(`^(\S+)\s+(\S+)\s+(\S+)$`)
+
+// Empty returns an empty configuration.
+func Empty() *Conf {
+	return &Conf{}
+}
+
+// From parses a reader containing an identity map configuration.
+func From(r io.Reader) (*Conf, error) {
+	ret := &Conf{data: make(map[string][]element)}
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		ret.originalLines = append(ret.originalLines, line)
+
+		if idx := strings.IndexByte(line, '#'); idx >= 0 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		parts := linePattern.FindStringSubmatch(line)
+		if parts == nil {
+			return nil, errors.Errorf(
+				"unable to parse line %d: expected <map-name> <system-identity> <sql-user>", lineNo)
+		}
+		mapName, systemIdentity, sqlUser := parts[1], parts[2], parts[3]
+
+		var pattern *regexp.Regexp
+		var err error
+		if strings.HasPrefix(systemIdentity, "/") {
+			pattern, err = regexp.Compile(systemIdentity[1:])
+		} else {
+			if strings.Contains(sqlUser, `\1`) {
+				return nil, errors.Errorf(
+					`line %d: \1 can only be used with a regular expression system identity`, lineNo)
+			}
+			pattern, err = regexp.Compile("^" + regexp.QuoteMeta(systemIdentity) + "$")
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse line %d", lineNo)
+		}
+		if strings.Contains(sqlUser, `\1`) && pattern.NumSubexp() < 1 {
+			return nil, errors.Errorf(
+				`line %d: \1 requires a capture group in the regular expression`, lineNo)
+		}
+
+		if _, ok := ret.data[mapName]; !ok {
+			ret.sortedKeys = append(ret.sortedKeys, mapName)
+		}
+		ret.data[mapName] = append(ret.data[mapName], element{
+			pattern:      pattern,
+			substitution: sqlUser,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.Strings(ret.sortedKeys)
+	return ret, nil
+}
+
+// Empty returns true if the configuration contains no rules.
+func (c *Conf) Empty() bool {
+	return c == nil || len(c.data) == 0
+}
+
+// HasMap returns true if the configuration contains rules for the
+// given map name.
+func (c *Conf) HasMap(mapName string) bool {
+	if c == nil {
+		return false
+	}
+	_, ok := c.data[mapName]
+	return ok
+}
+
+// Map returns the SQL users that the given system identity maps to
+// using the named map, in the order of the rules of the map. An empty
+// result indicates that no rule matched.
+func (c *Conf) Map(mapName, systemIdentity string) ([]security.SQLUsername, error) {
+	if c == nil {
+		return nil, nil
+	}
+	var ret []security.SQLUsername
+	seen := make(map[security.SQLUsername]bool)
+	for _, elt := range c.data[mapName] {
+		matches := elt.pattern.FindStringSubmatch(systemIdentity)
+		if matches == nil {
+			continue
+		}
+		sqlUser := elt.substitution
+		if len(matches) > 1 {
+			sqlUser = strings.ReplaceAll(sqlUser, `\1`, matches[1])
+		}
+		u, _ := security.MakeSQLUsernameFromUserInput(sqlUser, security.UsernameValidation)
+		if u.Undefined() {
+			return nil, errors.Errorf(
+				"identity %q maps to an empty SQL user in map %q", systemIdentity, mapName)
+		}
+		if !seen[u] {
+			seen[u] = true
+			ret = append(ret, u)
+		}
+	}
+	return ret, nil
+}
+
+// String returns a representation of the configuration suitable for
+// display to the user.
+func (c *Conf) String() string {
+	if c.Empty() {
+		return "# (empty configuration)\n"
+	}
+	var sb strings.Builder
+	sb.WriteString("# Original configuration:\n")
+	for _, l := range c.originalLines {
+		fmt.Fprintf(&sb, "# %s\n", l)
+	}
+	sb.WriteString("# Active configuration:\n")
+	for _, mapName := range c.sortedKeys {
+		for _, elt := range c.data[mapName] {
+			fmt.Fprintf(&sb, "%-8s %-20s %s\n", mapName, elt.pattern.String(), elt.substitution)
+		}
+	}
+	return sb.String()
+}
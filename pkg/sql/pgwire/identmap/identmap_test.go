@@ -0,0 +1,81 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package identmap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentityMap(t *testing.T) {
+	conf, err := From(strings.NewReader(`
+# Map the corporate PKI principals to SQL users.
+corp    /^(.*)@example\.com$   \1
+corp    carl@corp.example.com  carl   # carl has a legacy principal
+corp    carl@corp.example.com  admins
+other   alice                  bob
+`))
+	require.NoError(t, err)
+	require.False(t, conf.Empty())
+	require.True(t, conf.HasMap("corp"))
+	require.False(t, conf.HasMap("unknown"))
+
+	testCases := []struct {
+		mapName  string
+		identity string
+		expected []string
+	}{
+		{"corp", "dave@example.com", []string{"dave"}},
+		{"corp", "Dave@example.com", []string{"dave"}},
+		{"corp", "carl@corp.example.com", []string{"carl", "admins"}},
+		{"corp", "carl@corp.example.org", nil},
+		{"corp", "alice", nil},
+		{"other", "alice", []string{"bob"}},
+		{"other", "xalice", nil},
+		{"unknown", "alice", nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.mapName+"/"+tc.identity, func(t *testing.T) {
+			users, err := conf.Map(tc.mapName, tc.identity)
+			require.NoError(t, err)
+			var expected []security.SQLUsername
+			for _, u := range tc.expected {
+				expected = append(expected, security.MakeSQLUsernameFromPreNormalizedString(u))
+			}
+			require.Equal(t, expected, users)
+		})
+	}
+
+	// A capture that produces an invalid username is an error.
+	users, err := conf.Map("corp", "@example.com")
+	require.Error(t, err)
+	require.Nil(t, users)
+}
+
+func TestIdentityMapParseErrors(t *testing.T) {
+	for _, input := range []string{
+		"corp only-two",
+		"corp a b c",
+		`corp alice \1`,
+		`corp /alice \1`,
+		`corp /(alice bob`,
+	} {
+		_, err := From(strings.NewReader(input))
+		require.Error(t, err, input)
+	}
+
+	conf, err := From(strings.NewReader("# only comments\n\n"))
+	require.NoError(t, err)
+	require.True(t, conf.Empty())
+}
@@ -0,0 +1,154 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgwire
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+)
+
+// The names of the cluster settings that configure the account lockout.
+const (
+	lockoutMaxFailedAttemptsSetting = "server.user_login.lockout.max_failed_attempts"
+	lockoutDurationSetting          = "server.user_login.lockout.duration"
+)
+
+// lockoutMaxFailedAttempts is the number of consecutive failed
+// authentication attempts after which a user is locked out.
+var lockoutMaxFailedAttempts = settings.RegisterIntSetting(
+	lockoutMaxFailedAttemptsSetting,
+	"the number of consecutive failed authentication attempts after which a user "+
+		"cannot log into a node until server.user_login.lockout.duration has elapsed "+
+		"(0 disables the lockout). The attempts are counted separately by each node. "+
+		"The root user is not affected by the lockout.",
+	0,
+	settings.NonNegativeInt,
+).WithPublic()
+
+// lockoutDuration is the time during which a locked out user cannot log in.
+var lockoutDuration = settings.RegisterDurationSetting(
+	lockoutDurationSetting,
+	"the duration during which a user cannot log into a node after reaching "+
+		"server.user_login.lockout.max_failed_attempts consecutive failed "+
+		"authentication attempts",
+	5*time.Minute,
+	settings.NonNegativeDuration,
+).WithPublic()
+
+// MetaLoginsLockedOut is the metadata of the counter of the authentication
+// attempts rejected because the user is locked out.
+var MetaLoginsLockedOut = metric.Metadata{
+	Name:        "sql.conns_locked_out",
+	Help:        "Counter of the number of sql connections rejected because the user is locked out after failed authentication attempts",
+	Measurement: "Connections",
+	Unit:        metric.Unit_COUNT,
+}
+
+// LoginLockout locks users out of the node after too many consecutive failed
+// authentication attempts, to slow down brute-force attacks on passwords. A
+// single LoginLockout is shared by the SQL and HTTP logins of a node.
+type LoginLockout struct {
+	sv       *settings.Values
+	rejected *metric.Counter
+
+	mu struct {
+		syncutil.Mutex
+		// users maps the users whose last authentication attempt failed to
+		// their state. Only existing users are tracked.
+		users map[security.SQLUsername]*lockoutState
+	}
+}
+
+type lockoutState struct {
+	// failures is the number of consecutive failed attempts.
+	failures int64
+	// lockedUntil is the time before which the user cannot log in.
+	lockedUntil time.Time
+}
+
+func newLoginLockout(sv *settings.Values, rejected *metric.Counter) *LoginLockout {
+	l := &LoginLockout{sv: sv, rejected: rejected}
+	l.mu.users = make(map[security.SQLUsername]*lockoutState)
+	return l
+}
+
+// exempt returns true if the given user is never locked out, so that an
+// administrator can always connect to the node.
+func (l *LoginLockout) exempt(user security.SQLUsername) bool {
+	return user.IsRootUser() || user.IsNodeUser()
+}
+
+// Check returns an error if the given user is locked out at the given time.
+// The error can be recognized with IsLockedOutError.
+func (l *LoginLockout) Check(user security.SQLUsername, now time.Time) error {
+	if l.exempt(user) {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s, ok := l.mu.users[user]
+	if !ok || !now.Before(s.lockedUntil) {
+		return nil
+	}
+	l.rejected.Inc(1)
+	return errors.Mark(errors.WithHintf(
+		pgerror.Newf(pgcode.InvalidAuthorizationSpecification,
+			"too many failed authentication attempts for user %s", user),
+		"try again in %s; the lockout is configured using the %s and %s cluster settings",
+		s.lockedUntil.Sub(now).Round(time.Second), lockoutMaxFailedAttemptsSetting, lockoutDurationSetting,
+	), errLockedOut)
+}
+
+// errLockedOut marks the errors returned by LoginLockout.Check.
+var errLockedOut = errors.New("user locked out")
+
+// IsLockedOutError returns true if the error was returned by
+// LoginLockout.Check because the user is locked out.
+func IsLockedOutError(err error) bool {
+	return errors.Is(err, errLockedOut)
+}
+
+// ReportAttempt records the outcome of an authentication attempt of the
+// given user.
+func (l *LoginLockout) ReportAttempt(user security.SQLUsername, success bool, now time.Time) {
+	if l.exempt(user) {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if success {
+		delete(l.mu.users, user)
+		return
+	}
+	maxFailures := lockoutMaxFailedAttempts.Get(l.sv)
+	if maxFailures == 0 {
+		return
+	}
+	s, ok := l.mu.users[user]
+	if !ok {
+		s = &lockoutState{}
+		l.mu.users[user] = s
+	}
+	s.failures++
+	if s.failures >= maxFailures {
+		// Lock the user out and start counting again once the lockout
+		// expires.
+		s.failures = 0
+		s.lockedUntil = now.Add(lockoutDuration.Get(l.sv))
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgwire
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginLockout(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettings()
+	rejected := metric.NewCounter(MetaLoginsLockedOut)
+	l := newLoginLockout(&st.SV, rejected)
+	now := time.Unix(0, 0)
+	user := security.MakeSQLUsernameFromPreNormalizedString("testuser")
+	otherUser := security.MakeSQLUsernameFromPreNormalizedString("otheruser")
+
+	// The lockout is disabled by default.
+	for i := 0; i < 10; i++ {
+		l.ReportAttempt(user, false /* success */, now)
+	}
+	require.NoError(t, l.Check(user, now))
+
+	lockoutMaxFailedAttempts.Override(&st.SV, 3)
+	lockoutDuration.Override(&st.SV, time.Minute)
+
+	// The user is locked out after the third consecutive failure.
+	l.ReportAttempt(user, false /* success */, now)
+	l.ReportAttempt(user, false /* success */, now)
+	require.NoError(t, l.Check(user, now))
+	l.ReportAttempt(user, false /* success */, now)
+	err := l.Check(user, now.Add(59*time.Second))
+	require.True(t, IsLockedOutError(err))
+	require.Equal(t, pgcode.InvalidAuthorizationSpecification, pgerror.GetPGCode(err))
+	require.Equal(t, int64(1), rejected.Count())
+	require.NoError(t, l.Check(otherUser, now))
+	now = now.Add(time.Minute)
+	require.NoError(t, l.Check(user, now))
+
+	// A success resets the count of failures.
+	l.ReportAttempt(user, false /* success */, now)
+	l.ReportAttempt(user, false /* success */, now)
+	l.ReportAttempt(user, true /* success */, now)
+	l.ReportAttempt(user, false /* success */, now)
+	require.NoError(t, l.Check(user, now))
+
+	// The root user is never locked out.
+	for i := 0; i < 3; i++ {
+		l.ReportAttempt(security.RootUserName(), false /* success */, now)
+	}
+	require.NoError(t, l.Check(security.RootUserName(), now))
+}
@@ -149,6 +149,7 @@ var (
 	SchemaAndDataStatementMixingNotSupported        = MakeCode("25007")
 	NoActiveSQLTransaction                          = MakeCode("25P01")
 	InFailedSQLTransaction                          = MakeCode("25P02")
+	IdleInTransactionSessionTimeout                 = MakeCode("25P03")
 	// Section: Class 26 - Invalid SQL Statement Name
 	InvalidSQLStatementName = MakeCode("26000")
 	// Section: Class 27 - Triggered Data Change Violation
@@ -267,6 +268,7 @@ var (
 	CrashShutdown        = MakeCode("57P02")
 	CannotConnectNow     = MakeCode("57P03")
 	DatabaseDropped      = MakeCode("57P04")
+	IdleSessionTimeout   = MakeCode("57P05")
 	// Section: Class 58 - System Error
 	System        = MakeCode("58000")
 	Io            = MakeCode("58030")
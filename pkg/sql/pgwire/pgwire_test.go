@@ -11,6 +11,7 @@
 package pgwire_test
 
 import (
+	"compress/gzip"
 	"context"
 	gosql "database/sql"
 	"database/sql/driver"
@@ -24,6 +25,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"testing"
@@ -34,6 +36,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server"
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirecancel"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/skip"
@@ -1877,6 +1881,256 @@ func TestSessionParameters(t *testing.T) {
 	}
 }
 
+// TestSessionParametersIgnoredNotice checks that the client is warned about
+// the connection parameters that are ignored, unless client_min_messages
+// filters out warnings.
+func TestSessionParametersIgnoredNotice(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	params := base.TestServerArgs{Insecure: true}
+	s, _, _ := serverutils.StartServer(t, params)
+
+	ctx := context.Background()
+	defer s.Stopper().Stop(ctx)
+
+	host, ports, _ := net.SplitHostPort(s.ServingSQLAddr())
+	port, _ := strconv.Atoi(ports)
+
+	for _, tc := range []struct {
+		runtimeParams   map[string]string
+		expectedNotices []string
+	}{
+		{
+			runtimeParams:   map[string]string{"foo": "bar"},
+			expectedNotices: []string{`unrecognized configuration parameter "foo" ignored`},
+		},
+		{
+			runtimeParams:   map[string]string{"foo": "bar", "client_min_messages": "error"},
+			expectedNotices: nil,
+		},
+		{
+			runtimeParams:   map[string]string{"application_name": "test"},
+			expectedNotices: nil,
+		},
+	} {
+		var notices []string
+		connCfg := pgx.ConnConfig{
+			Host:          host,
+			Port:          uint16(port),
+			User:          security.RootUser,
+			TLSConfig:     nil, // insecure
+			Logger:        pgxTestLogger{},
+			RuntimeParams: tc.runtimeParams,
+			OnNotice: func(_ *pgx.Conn, n *pgx.Notice) {
+				notices = append(notices, n.Message)
+			},
+		}
+		conn, err := pgx.Connect(connCfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = conn.Close()
+		if !reflect.DeepEqual(tc.expectedNotices, notices) {
+			t.Fatalf("runtime params %v: expected notices %q, got %q",
+				tc.runtimeParams, tc.expectedNotices, notices)
+		}
+	}
+}
+
+// TestListenNotify checks that the notifications generated by NOTIFY are
+// delivered to the sessions which executed LISTEN, on any node.
+func TestListenNotify(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	tc := serverutils.StartNewTestCluster(t, 2, base.TestClusterArgs{
+		ServerArgs: base.TestServerArgs{Insecure: true},
+	})
+	defer tc.Stopper().Stop(ctx)
+
+	connect := func(s serverutils.TestServerInterface) *pgx.Conn {
+		host, ports, _ := net.SplitHostPort(s.ServingSQLAddr())
+		port, _ := strconv.Atoi(ports)
+		conn, err := pgx.Connect(pgx.ConnConfig{
+			Host:      host,
+			Port:      uint16(port),
+			User:      security.RootUser,
+			TLSConfig: nil, // insecure
+			Logger:    pgxTestLogger{},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return conn
+	}
+	// The listener and the notifier are connected to different nodes.
+	listener := connect(tc.Server(0))
+	defer func() { _ = listener.Close() }()
+	notifier := connect(tc.Server(1))
+	defer func() { _ = notifier.Close() }()
+
+	// receive returns the next notification received by the listener.
+	// Notifications are delivered asynchronously, in between transactions, so
+	// it runs statements until one is received.
+	receive := func() *pgx.Notification {
+		var n *pgx.Notification
+		testutils.SucceedsSoon(t, func() error {
+			if _, err := listener.Exec("SELECT 1"); err != nil {
+				return err
+			}
+			// The canceled context makes WaitForNotification return right away
+			// if no notification was received.
+			doneCtx, cancel := context.WithCancel(ctx)
+			cancel()
+			var err error
+			n, err = listener.WaitForNotification(doneCtx)
+			return err
+		})
+		return n
+	}
+
+	if err := listener.Listen("foo"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Notifications of rolled back transactions are not delivered, and
+	// duplicate notifications within a transaction are only delivered once.
+	for _, stmt := range []string{
+		"BEGIN; NOTIFY foo, 'rolled back'; ROLLBACK",
+		"NOTIFY bar, 'other channel'",
+		"BEGIN; NOTIFY foo, 'a'; NOTIFY foo, 'a'; NOTIFY foo, 'b'; COMMIT",
+	} {
+		if _, err := notifier.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var payloads []string
+	for i := 0; i < 2; i++ {
+		n := receive()
+		if n.Channel != "foo" {
+			t.Fatalf("unexpected notification %q on channel %s", n.Payload, n.Channel)
+		}
+		payloads = append(payloads, n.Payload)
+	}
+	sort.Strings(payloads)
+	if expected := []string{"a", "b"}; !reflect.DeepEqual(payloads, expected) {
+		t.Fatalf("expected notifications %q, got %q", expected, payloads)
+	}
+
+	// After UNLISTEN, no more notifications are delivered on the channel. The
+	// notification on the sentinel channel is delivered after the one on foo,
+	// which would be received first if it were delivered.
+	if err := listener.Listen("sentinel"); err != nil {
+		t.Fatal(err)
+	}
+	if err := listener.Unlisten("foo"); err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{"NOTIFY foo, 'c'", "NOTIFY sentinel"} {
+		if _, err := notifier.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := receive(); n.Channel != "sentinel" {
+		t.Fatalf("unexpected notification %q on channel %s", n.Payload, n.Channel)
+	}
+}
+
+// TestConnectionLimits checks that the connections in excess of the
+// limits configured with cluster settings are rejected.
+func TestConnectionLimits(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	params := base.TestServerArgs{Insecure: true}
+	s, db, _ := serverutils.StartServer(t, params)
+
+	ctx := context.Background()
+	defer s.Stopper().Stop(ctx)
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `CREATE USER testuser`)
+
+	openDB := func(user string) *gosql.DB {
+		pgURL := url.URL{
+			Scheme:   "postgres",
+			User:     url.User(user),
+			Host:     s.ServingSQLAddr(),
+			RawQuery: "sslmode=disable",
+		}
+		db, err := gosql.Open("postgres", pgURL.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return db
+	}
+
+	// Hold a connection for testuser.
+	db1 := openDB("testuser")
+	defer db1.Close()
+	conn1, err := db1.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn1.ExecContext(ctx, `SELECT 1`); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		setting     string
+		limit       int
+		expectedErr string
+	}{
+		// testuser already has one connection.
+		{"server.max_connections_per_user", 1, "too many connections for user testuser"},
+		// Both root and testuser already have at least one connection.
+		{"server.max_connections_per_gateway", 2, "sorry, too many clients already"},
+	} {
+		t.Run(tc.setting, func(t *testing.T) {
+			sqlDB.Exec(t, fmt.Sprintf(`SET CLUSTER SETTING %s = %d`, tc.setting, tc.limit))
+			defer sqlDB.Exec(t, fmt.Sprintf(`RESET CLUSTER SETTING %s`, tc.setting))
+
+			testutils.SucceedsSoon(t, func() error {
+				db2 := openDB("testuser")
+				defer db2.Close()
+				err := db2.PingContext(ctx)
+				if !testutils.IsError(err, tc.expectedErr) {
+					return errors.Errorf("expected error %q, got %v", tc.expectedErr, err)
+				}
+				return nil
+			})
+
+			// The root user is not subject to the limits.
+			rootDB := openDB(security.RootUser)
+			defer rootDB.Close()
+			if err := rootDB.PingContext(ctx); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	// The rejected connections are counted.
+	var rejected int
+	sqlDB.QueryRow(t,
+		`SELECT value FROM crdb_internal.node_metrics WHERE name = 'sql.conns_rejected'`,
+	).Scan(&rejected)
+	if rejected < 2 {
+		t.Fatalf("expected at least 2 rejected connections, got %d", rejected)
+	}
+
+	// Once the limit is lifted, new connections are accepted.
+	db2 := openDB("testuser")
+	defer db2.Close()
+	if err := db2.PingContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn1.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
 type pgxTestLogger struct{}
 
 func (l pgxTestLogger) Log(level pgx.LogLevel, msg string, data map[string]interface{}) {
@@ -1908,22 +2162,141 @@ func TestCancelRequest(t *testing.T) {
 		_ = telemetry.GetFeatureCounts(telemetry.Raw, telemetry.ResetCounts)
 
 		fe := pgproto3.NewFrontend(pgproto3.NewChunkReader(conn), conn)
-		// versionCancel is the special code sent as header for cancel requests.
-		// See: https://www.postgresql.org/docs/current/protocol-message-formats.html
-		// and the explanation in server.go.
-		const versionCancel = 80877102
-		if err := fe.Send(&pgproto3.StartupMessage{ProtocolVersion: versionCancel}); err != nil {
+		// A cancel request which does not match any session is ignored, and
+		// the server closes the connection without responding.
+		if err := fe.Send(&pgproto3.CancelRequest{ProcessID: 1, SecretKey: 2}); err != nil {
 			t.Fatal(err)
 		}
 		if _, err := fe.Receive(); !errors.Is(err, io.ErrUnexpectedEOF) {
 			t.Fatalf("unexpected: %v", err)
 		}
-		if count := telemetry.GetRawFeatureCounts()["pgwire.unimplemented.cancel_request"]; count != 1 {
+		if count := telemetry.GetRawFeatureCounts()["pgwire.cancel_request"]; count != 1 {
 			t.Fatalf("expected 1 cancel request, got %d", count)
 		}
 	})
 }
 
+// TestCancelRequestForOtherInstance checks that a cancel request for a session
+// served by another SQL instance is dropped and counted.
+func TestCancelRequestForOtherInstance(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	params := base.TestServerArgs{Insecure: true}
+	s, db, _ := serverutils.StartServer(t, params)
+
+	ctx := context.Background()
+	defer s.Stopper().Stop(ctx)
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", s.ServingSQLAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	key := pgwirecancel.MakeBackendKeyData(base.SQLInstanceID(s.NodeID()) + 1)
+	pid, secret := key.GetPGWireCancelInfo()
+	fe := pgproto3.NewFrontend(pgproto3.NewChunkReader(conn), conn)
+	if err := fe.Send(&pgproto3.CancelRequest{
+		ProcessID: uint32(pid), SecretKey: uint32(secret),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fe.Receive(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("unexpected: %v", err)
+	}
+
+	var dropped int
+	sqlutils.MakeSQLRunner(db).QueryRow(t,
+		`SELECT value FROM crdb_internal.node_metrics WHERE name = 'sql.cancel_requests_dropped'`,
+	).Scan(&dropped)
+	if dropped != 1 {
+		t.Fatalf("expected 1 dropped cancel request, got %d", dropped)
+	}
+}
+
+// TestCancelRequestCancelsQuery checks that a CancelRequest carrying the
+// BackendKeyData of a session cancels the query running in that session.
+func TestCancelRequestCancelsQuery(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	params := base.TestServerArgs{Insecure: true}
+	s, db, _ := serverutils.StartServer(t, params)
+
+	ctx := context.Background()
+	defer s.Stopper().Stop(ctx)
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", s.ServingSQLAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	fe := pgproto3.NewFrontend(pgproto3.NewChunkReader(conn), conn)
+	if err := fe.Send(&pgproto3.StartupMessage{
+		ProtocolVersion: pgproto3.ProtocolVersionNumber,
+		Parameters:      map[string]string{"user": security.RootUser},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var keyData *pgproto3.BackendKeyData
+	for {
+		msg, err := fe.Receive()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k, ok := msg.(*pgproto3.BackendKeyData); ok {
+			// Copy the message, as the frontend reuses it.
+			keyDataCopy := *k
+			keyData = &keyDataCopy
+		}
+		if _, ok := msg.(*pgproto3.ReadyForQuery); ok {
+			break
+		}
+	}
+	if keyData == nil {
+		t.Fatal("expected a BackendKeyData message")
+	}
+
+	if err := fe.Send(&pgproto3.Query{String: "SELECT pg_sleep(300)"}); err != nil {
+		t.Fatal(err)
+	}
+	// Wait for the query to start running before canceling it.
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.CheckQueryResultsRetry(t,
+		`SELECT count(*) FROM [SHOW QUERIES] WHERE query LIKE 'SELECT pg_sleep%'`,
+		[][]string{{"1"}})
+
+	cancelConn, err := d.DialContext(ctx, "tcp", s.ServingSQLAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancelConn.Close()
+	cancelFe := pgproto3.NewFrontend(pgproto3.NewChunkReader(cancelConn), cancelConn)
+	if err := cancelFe.Send(&pgproto3.CancelRequest{
+		ProcessID: keyData.ProcessID,
+		SecretKey: keyData.SecretKey,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for {
+		msg, err := fe.Receive()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if errMsg, ok := msg.(*pgproto3.ErrorResponse); ok {
+			if errMsg.Code != pgcode.QueryCanceled.String() {
+				t.Fatalf("expected query canceled error, got %+v", errMsg)
+			}
+			break
+		}
+	}
+}
+
 func TestFailPrepareFailsTxn(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -1956,3 +2329,101 @@ func TestFailPrepareFailsTxn(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// TestCompression checks that the results are compressed when the client
+// requests it with the crdb:compression connection parameter, and only if
+// compression is enabled by the server.pgwire.compression.enabled cluster
+// setting.
+func TestCompression(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	params := base.TestServerArgs{Insecure: true}
+	s, db, _ := serverutils.StartServer(t, params)
+
+	ctx := context.Background()
+	defer s.Stopper().Stop(ctx)
+
+	for _, tc := range []struct {
+		enabled   bool
+		requested string
+		expected  string
+	}{
+		{enabled: false, requested: "gzip", expected: ""},
+		{enabled: true, requested: "gzip", expected: "gzip"},
+		{enabled: true, requested: "foo, GZIP", expected: "gzip"},
+		{enabled: true, requested: "foo", expected: ""},
+	} {
+		t.Run(fmt.Sprintf("enabled=%t/%s", tc.enabled, tc.requested), func(t *testing.T) {
+			if _, err := db.Exec(
+				"SET CLUSTER SETTING server.pgwire.compression.enabled = $1", tc.enabled,
+			); err != nil {
+				t.Fatal(err)
+			}
+
+			var d net.Dialer
+			conn, err := d.DialContext(ctx, "tcp", s.ServingSQLAddr())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			fe := pgproto3.NewFrontend(pgproto3.NewChunkReader(conn), conn)
+			if err := fe.Send(&pgproto3.StartupMessage{
+				ProtocolVersion: pgproto3.ProtocolVersionNumber,
+				Parameters: map[string]string{
+					"user":             security.RootUser,
+					"crdb:compression": tc.requested,
+				},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			var compression string
+			for {
+				msg, err := fe.Receive()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if p, ok := msg.(*pgproto3.ParameterStatus); ok && p.Name == "crdb:compression" {
+					compression = p.Value
+				}
+				if _, ok := msg.(*pgproto3.ReadyForQuery); ok {
+					break
+				}
+			}
+			if compression != tc.expected {
+				t.Fatalf("expected compression %q, got %q", tc.expected, compression)
+			}
+
+			const query = "SELECT repeat('a', 100000)"
+			if err := fe.Send(&pgproto3.Query{String: query}); err != nil {
+				t.Fatal(err)
+			}
+			if compression != "" {
+				// Everything sent by the server after the handshake is compressed.
+				zr, err := gzip.NewReader(conn)
+				if err != nil {
+					t.Fatal(err)
+				}
+				fe = pgproto3.NewFrontend(pgproto3.NewChunkReader(zr), conn)
+			}
+			for {
+				msg, err := fe.Receive()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if row, ok := msg.(*pgproto3.DataRow); ok {
+					if expected := strings.Repeat("a", 100000); string(row.Values[0]) != expected {
+						t.Fatalf("unexpected result of size %d", len(row.Values[0]))
+					}
+				}
+				if errMsg, ok := msg.(*pgproto3.ErrorResponse); ok {
+					t.Fatalf("unexpected error: %+v", errMsg)
+				}
+				if _, ok := msg.(*pgproto3.ReadyForQuery); ok {
+					break
+				}
+			}
+		})
+	}
+}
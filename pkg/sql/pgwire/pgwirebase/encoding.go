@@ -22,6 +22,8 @@ import (
 	"unicode/utf8"
 	"unsafe"
 
+	"github.com/cockroachdb/cockroach/pkg/geo"
+	"github.com/cockroachdb/cockroach/pkg/geo/geopb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/lex"
 	"github.com/cockroachdb/cockroach/pkg/sql/oidext"
@@ -527,6 +529,23 @@ func DecodeDatum(
 			}
 			u := binary.BigEndian.Uint32(b)
 			return tree.NewDOid(tree.DInt(u)), nil
+		case oid.T_regoper,
+			oid.T_regproc,
+			oid.T_regrole,
+			oid.T_regclass,
+			oid.T_regtype,
+			oid.T_regconfig,
+			oid.T_regoperator,
+			oid.T_regnamespace,
+			oid.T_regprocedure,
+			oid.T_regdictionary:
+			// The binary format of the OID alias types is the same as the one of
+			// OID. The OID is resolved the same way as in the text format.
+			if len(b) < 4 {
+				return nil, pgerror.Newf(pgcode.Syntax, "%s requires 4 bytes for binary format", t.SQLString())
+			}
+			u := binary.BigEndian.Uint32(b)
+			return tree.ParseDOid(evalCtx, strconv.FormatUint(uint64(u), 10), t)
 		case oid.T_float4:
 			if len(b) < 4 {
 				return nil, pgerror.Newf(pgcode.Syntax, "float4 requires 4 bytes for binary format")
@@ -720,6 +739,30 @@ func DecodeDatum(
 				return nil, err
 			}
 			return tree.ParseDJSON(string(b))
+		case oidext.T_box2d:
+			if len(b) < 32 {
+				return nil, pgerror.Newf(pgcode.Syntax, "box2d requires 32 bytes for binary format")
+			}
+			return tree.NewDBox2D(geo.CartesianBoundingBox{
+				BoundingBox: geopb.BoundingBox{
+					LoX: math.Float64frombits(binary.BigEndian.Uint64(b[0:8])),
+					HiX: math.Float64frombits(binary.BigEndian.Uint64(b[8:16])),
+					LoY: math.Float64frombits(binary.BigEndian.Uint64(b[16:24])),
+					HiY: math.Float64frombits(binary.BigEndian.Uint64(b[24:32])),
+				},
+			}), nil
+		case oidext.T_geography:
+			g, err := geo.ParseGeographyFromEWKB(geopb.EWKB(b))
+			if err != nil {
+				return nil, err
+			}
+			return tree.NewDGeography(g), nil
+		case oidext.T_geometry:
+			g, err := geo.ParseGeometryFromEWKB(geopb.EWKB(b))
+			if err != nil {
+				return nil, err
+			}
+			return tree.NewDGeometry(g), nil
 		case oid.T_varbit, oid.T_bit:
 			if len(b) < 4 {
 				return nil, NewProtocolViolationErrorf("insufficient data: %d", len(b))
@@ -12,11 +12,13 @@ package pgwirebase
 
 import "math"
 
-//ClientMessageType represents a client pgwire message.
+// ClientMessageType represents a client pgwire message.
+//
 //go:generate stringer -type=ClientMessageType
 type ClientMessageType byte
 
-//ServerMessageType represents a server pgwire message.
+// ServerMessageType represents a server pgwire message.
+//
 //go:generate stringer -type=ServerMessageType
 type ServerMessageType byte
 
@@ -37,6 +39,7 @@ const (
 	ClientMsgTerminate   ClientMessageType = 'X'
 
 	ServerMsgAuth                 ServerMessageType = 'R'
+	ServerMsgBackendKeyData       ServerMessageType = 'K'
 	ServerMsgBindComplete         ServerMessageType = '2'
 	ServerMsgCommandComplete      ServerMessageType = 'C'
 	ServerMsgCloseComplete        ServerMessageType = '3'
@@ -45,6 +48,7 @@ const (
 	ServerMsgEmptyQuery           ServerMessageType = 'I'
 	ServerMsgErrorResponse        ServerMessageType = 'E'
 	ServerMsgNoticeResponse       ServerMessageType = 'N'
+	ServerMsgNotificationResponse ServerMessageType = 'A'
 	ServerMsgNoData               ServerMessageType = 'n'
 	ServerMsgParameterDescription ServerMessageType = 't'
 	ServerMsgParameterStatus      ServerMessageType = 'S'
@@ -55,6 +59,7 @@ const (
 )
 
 // ServerErrFieldType represents the error fields.
+//
 //go:generate stringer -type=ServerErrFieldType
 type ServerErrFieldType byte
 
@@ -72,6 +77,7 @@ const (
 )
 
 // PrepareType represents a subtype for prepare messages.
+//
 //go:generate stringer -type=PrepareType
 type PrepareType byte
 
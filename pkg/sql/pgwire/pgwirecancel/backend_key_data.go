@@ -0,0 +1,95 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package pgwirecancel contains the helpers used to implement the pgwire
+// query cancellation protocol.
+//
+// When a session is established, the server sends a BackendKeyData message
+// containing a "process ID" and a "secret key" to the client. To cancel the
+// query currently running in that session, the client opens a new
+// connection and sends a CancelRequest message containing both values.
+// See https://www.postgresql.org/docs/current/protocol-flow.html#id-1.10.5.7.9
+package pgwirecancel
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+)
+
+// BackendKeyData is a 64-bit identifier used by the pgwire protocol to
+// cancel queries. The upper 32 bits are sent to the client as the process ID
+// and the lower 32 bits as the secret key.
+//
+// The ID of the SQL instance serving the session is encoded in the key, so
+// that the server receiving a cancel request can tell which instance the
+// session belongs to:
+//
+// - If the leading bit is 0, the next 11 bits are the SQL instance ID and the
+//   remaining 52 bits are random.
+// - If the leading bit is 1, the next 31 bits are the SQL instance ID and the
+//   remaining 32 bits are random.
+type BackendKeyData uint64
+
+const (
+	leadingBitMask = 1 << 63
+	// smallInstanceIDBits is the number of bits used for the SQL instance ID
+	// when the leading bit is 0.
+	smallInstanceIDBits = 11
+	// smallRandomBits is the number of random bits when the leading bit is 0.
+	smallRandomBits = 63 - smallInstanceIDBits
+	// largeRandomBits is the number of random bits when the leading bit is 1.
+	largeRandomBits = 32
+)
+
+// MakeBackendKeyData generates a new, random, BackendKeyData for a session
+// served by the given SQL instance.
+func MakeBackendKeyData(sqlInstanceID base.SQLInstanceID) BackendKeyData {
+	var buf [8]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		panic(fmt.Sprintf("unable to generate random bytes: %v", err))
+	}
+	random := binary.BigEndian.Uint64(buf[:])
+	if sqlInstanceID < 1<<smallInstanceIDBits {
+		return BackendKeyData(uint64(sqlInstanceID)<<smallRandomBits |
+			random&(1<<smallRandomBits-1))
+	}
+	return BackendKeyData(leadingBitMask |
+		uint64(sqlInstanceID)<<largeRandomBits |
+		random&(1<<largeRandomBits-1))
+}
+
+// MakeBackendKeyDataFromPGWire reassembles the BackendKeyData from the
+// process ID and the secret key of a CancelRequest message.
+func MakeBackendKeyDataFromPGWire(pid, secret int32) BackendKeyData {
+	return BackendKeyData(uint64(uint32(pid))<<32 | uint64(uint32(secret)))
+}
+
+// GetPGWireCancelInfo returns the process ID and the secret key to send to the
+// client in the BackendKeyData message.
+func (b BackendKeyData) GetPGWireCancelInfo() (pid int32, secret int32) {
+	return int32(uint32(b >> 32)), int32(uint32(b))
+}
+
+// GetSQLInstanceID returns the ID of the SQL instance serving the session
+// identified by the BackendKeyData.
+func (b BackendKeyData) GetSQLInstanceID() base.SQLInstanceID {
+	if b&leadingBitMask == 0 {
+		return base.SQLInstanceID(b >> smallRandomBits)
+	}
+	return base.SQLInstanceID((b &^ leadingBitMask) >> largeRandomBits)
+}
+
+// String implements the fmt.Stringer interface.
+func (b BackendKeyData) String() string {
+	return fmt.Sprintf("%016x", uint64(b))
+}
@@ -0,0 +1,32 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgwirecancel
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackendKeyData(t *testing.T) {
+	for _, id := range []base.SQLInstanceID{
+		0, 1, 2, 1<<smallInstanceIDBits - 1, 1 << smallInstanceIDBits, 123456, math.MaxInt32,
+	} {
+		b := MakeBackendKeyData(id)
+		require.Equal(t, id, b.GetSQLInstanceID(), "%s", b)
+		require.Equal(t, id >= 1<<smallInstanceIDBits, b&leadingBitMask != 0, "%s", b)
+
+		pid, secret := b.GetPGWireCancelInfo()
+		require.Equal(t, b, MakeBackendKeyDataFromPGWire(pid, secret))
+	}
+}
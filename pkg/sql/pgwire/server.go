@@ -28,9 +28,11 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkeys"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/hba"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/identmap"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirecancel"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
@@ -39,6 +41,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/quotapool"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
@@ -116,6 +119,12 @@ var (
 		Measurement: "SQL Bytes",
 		Unit:        metric.Unit_BYTES,
 	}
+	MetaCancelRequestsDropped = metric.Metadata{
+		Name:        "sql.cancel_requests_dropped",
+		Help:        "Counter of the number of pgwire cancel requests dropped because they target a session served by another SQL instance",
+		Measurement: "Requests",
+		Unit:        metric.Unit_COUNT,
+	}
 )
 
 const (
@@ -177,12 +186,24 @@ type Server struct {
 
 	auth struct {
 		syncutil.RWMutex
-		conf *hba.Conf
+		conf        *hba.Conf
+		identityMap *identmap.Conf
 	}
 
 	sqlMemoryPool *mon.BytesMonitor
 	connMonitor   *mon.BytesMonitor
 
+	// connLimiter enforces the limits on the number of connections.
+	connLimiter *connLimiter
+	// loginLockout locks users out after failed authentication attempts.
+	loginLockout *LoginLockout
+	// cancelSem limits the number of cancel requests that are processed
+	// concurrently.
+	cancelSem *quotapool.IntPool
+	// cancelDroppedLogEvery limits the logging of the cancel requests dropped
+	// because they target a session served by another SQL instance.
+	cancelDroppedLogEvery log.EveryN
+
 	// testing{Conn,Auth}LogEnabled is used in unit tests in this
 	// package to force-enable conn/auth logging without dancing around
 	// the asynchronicity of cluster settings.
@@ -211,24 +232,30 @@ type Server struct {
 
 // ServerMetrics is the set of metrics for the pgwire server.
 type ServerMetrics struct {
-	BytesInCount   *metric.Counter
-	BytesOutCount  *metric.Counter
-	Conns          *metric.Gauge
-	NewConns       *metric.Counter
-	ConnMemMetrics sql.BaseMemoryMetrics
-	SQLMemMetrics  sql.MemoryMetrics
+	BytesInCount          *metric.Counter
+	BytesOutCount         *metric.Counter
+	Conns                 *metric.Gauge
+	NewConns              *metric.Counter
+	ConnsRejected         *metric.Counter
+	LockedOut             *metric.Counter
+	CancelRequestsDropped *metric.Counter
+	ConnMemMetrics        sql.BaseMemoryMetrics
+	SQLMemMetrics         sql.MemoryMetrics
 }
 
 func makeServerMetrics(
 	sqlMemMetrics sql.MemoryMetrics, histogramWindow time.Duration,
 ) ServerMetrics {
 	return ServerMetrics{
-		BytesInCount:   metric.NewCounter(MetaBytesIn),
-		BytesOutCount:  metric.NewCounter(MetaBytesOut),
-		Conns:          metric.NewGauge(MetaConns),
-		NewConns:       metric.NewCounter(MetaNewConns),
-		ConnMemMetrics: sql.MakeBaseMemMetrics("conns", histogramWindow),
-		SQLMemMetrics:  sqlMemMetrics,
+		BytesInCount:          metric.NewCounter(MetaBytesIn),
+		BytesOutCount:         metric.NewCounter(MetaBytesOut),
+		Conns:                 metric.NewGauge(MetaConns),
+		NewConns:              metric.NewCounter(MetaNewConns),
+		ConnsRejected:         metric.NewCounter(MetaConnsRejected),
+		LockedOut:             metric.NewCounter(MetaLoginsLockedOut),
+		CancelRequestsDropped: metric.NewCounter(MetaCancelRequestsDropped),
+		ConnMemMetrics:        sql.MakeBaseMemMetrics("conns", histogramWindow),
+		SQLMemMetrics:         sqlMemMetrics,
 	}
 }
 
@@ -284,6 +311,11 @@ func MakeServer(
 		int64(connReservationBatchSize)*baseSQLMemoryBudget, noteworthyConnMemoryUsageBytes, st)
 	server.connMonitor.Start(context.Background(), server.sqlMemoryPool, mon.BoundAccount{})
 
+	server.connLimiter = newConnLimiter(&st.SV, server.metrics.ConnsRejected)
+	server.loginLockout = newLoginLockout(&st.SV, server.metrics.LockedOut)
+	server.cancelSem = quotapool.NewIntPool("pgwire-cancel", maxConcurrentCancelRequests)
+	server.cancelDroppedLogEvery = log.Every(time.Minute)
+
 	server.mu.Lock()
 	server.mu.connCancelMap = make(cancelChanMap)
 	server.mu.Unlock()
@@ -293,6 +325,11 @@ func MakeServer(
 			loadLocalAuthConfigUponRemoteSettingChange(
 				ambientCtx.AnnotateCtx(context.Background()), server, st)
 		})
+	connIdentityMapConf.SetOnChange(&st.SV,
+		func() {
+			loadLocalIdentityMapUponRemoteSettingChange(
+				ambientCtx.AnnotateCtx(context.Background()), server, st)
+		})
 
 	return server
 }
@@ -338,6 +375,12 @@ func (s *Server) IsDraining() bool {
 	return s.mu.draining
 }
 
+// LoginLockout returns the lockout of users after failed authentication
+// attempts, which is shared with the HTTP logins of the node.
+func (s *Server) LoginLockout() *LoginLockout {
+	return s.loginLockout
+}
+
 // Metrics returns the set of metrics structs.
 func (s *Server) Metrics() (res []interface{}) {
 	return []interface{}{
@@ -578,7 +621,7 @@ func (s *Server) ServeConn(ctx context.Context, conn net.Conn, socketType Socket
 	if version == versionCancel {
 		// The cancel message is rather peculiar: it is sent without
 		// authentication, always over an unencrypted channel.
-		return handleCancel(conn)
+		return s.handleCancel(ctx, conn, &buf)
 	}
 
 	// If the server is shutting down, terminate the connection early.
@@ -614,7 +657,7 @@ func (s *Server) ServeConn(ctx context.Context, conn net.Conn, socketType Socket
 		// Yet, we've found clients in the wild that send the cancel
 		// after the TLS handshake, for example at
 		// https://github.com/cockroachlabs/support/issues/600.
-		return handleCancel(conn)
+		return s.handleCancel(ctx, conn, &buf)
 
 	default:
 		// We don't know this protocol.
@@ -663,16 +706,81 @@ func (s *Server) ServeConn(ctx context.Context, conn net.Conn, socketType Socket
 			insecure:        s.cfg.Insecure,
 			ie:              s.execCfg.InternalExecutor,
 			auth:            s.GetAuthenticationConfiguration(),
+			identMap:        s.GetIdentityMapConfiguration(),
+			connLimiter:     s.connLimiter,
+			loginLockout:    s.loginLockout,
 			testingAuthHook: testingAuthHook,
 		})
 	return nil
 }
 
-func handleCancel(conn net.Conn) error {
-	// Since we don't support this, close the door in the client's
-	// face. Make a note of that use in telemetry.
+// maxConcurrentCancelRequests is the maximum number of cancel requests that
+// are processed concurrently by a server. Further requests are ignored.
+const maxConcurrentCancelRequests = 256
+
+// failedCancelRequestPenalty is the time during which a cancel request that
+// did not cancel any query keeps counting against
+// maxConcurrentCancelRequests. Together, they bound the rate at which a client
+// can guess the keys of other sessions.
+const failedCancelRequestPenalty = time.Second
+
+// handleCancel handles a CancelRequest message, which requests the
+// cancellation of the queries currently running in the session
+// identified by the BackendKeyData in the message. The client does not
+// expect any response, and, as in PostgreSQL, errors are not reported
+// to it; the connection is simply closed.
+func (s *Server) handleCancel(
+	ctx context.Context, conn net.Conn, buf *pgwirebase.ReadBuffer,
+) error {
 	telemetry.Inc(sqltelemetry.CancelRequestCounter)
-	_ = conn.Close()
+	defer func() { _ = conn.Close() }()
+
+	alloc, err := s.cancelSem.TryAcquire(ctx, 1)
+	if err != nil {
+		log.VEventf(ctx, 2, "ignoring cancel request: too many concurrent cancel requests")
+		return nil
+	}
+	var canceled bool
+	defer func() {
+		if !canceled {
+			_ = conn.Close()
+			select {
+			case <-time.After(failedCancelRequestPenalty):
+			case <-ctx.Done():
+			}
+		}
+		alloc.Release()
+	}()
+
+	pid, err := buf.GetUint32()
+	if err != nil {
+		log.VEventf(ctx, 2, "malformed cancel request: %v", err)
+		return nil
+	}
+	secret, err := buf.GetUint32()
+	if err != nil {
+		log.VEventf(ctx, 2, "malformed cancel request: %v", err)
+		return nil
+	}
+	key := pgwirecancel.MakeBackendKeyDataFromPGWire(int32(pid), int32(secret))
+
+	// Only the SQL instance serving the session can cancel its queries. Cancel
+	// requests are not forwarded between SQL instances, so a client connected
+	// through a load balancer must send its cancel request to the instance
+	// serving its session. The requests for sessions served by other instances
+	// are dropped, and counted by the sql.cancel_requests_dropped metric.
+	// TODO: forward the request to the SQL instance serving the session.
+	if id := key.GetSQLInstanceID(); id != s.execCfg.NodeID.SQLInstanceID() {
+		s.metrics.CancelRequestsDropped.Inc(1)
+		if s.cancelDroppedLogEvery.ShouldLog() {
+			log.Warningf(ctx, "dropping cancel request for a session on SQL instance %d: "+
+				"cancel requests are not forwarded to other SQL instances", id)
+		}
+		return nil
+	}
+	if canceled = s.execCfg.SessionRegistry.CancelQueryByKey(key); !canceled {
+		log.VEventf(ctx, 2, "cancel request did not match any running query")
+	}
 	return nil
 }
 
@@ -732,6 +840,15 @@ func parseClientProvidedSessionParameters(
 			}
 			foundBufferSize = true
 
+		case compressionParam:
+			// If compression is disabled or none of the requested algorithms is
+			// supported, the results are not compressed. The client finds out
+			// because the server does not report the compression algorithm in a
+			// ParameterStatus message.
+			if sv != nil && compressionEnabled.Get(sv) {
+				args.Compression = negotiateCompression(value)
+			}
+
 		case "crdb:remote_addr":
 			if !trustClientProvidedRemoteAddr {
 				return sql.SessionArgs{}, pgerror.Newf(pgcode.ProtocolViolation,
@@ -768,6 +885,7 @@ func parseClientProvidedSessionParameters(
 					telemetry.Inc(counter)
 				}
 				log.Warningf(ctx, "unknown configuration parameter: %q", key)
+				args.IgnoredParameters = append(args.IgnoredParameters, key)
 
 			case !configurable:
 				return sql.SessionArgs{}, pgerror.Newf(pgcode.CantChangeRuntimeParam,
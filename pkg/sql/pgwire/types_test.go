@@ -234,6 +234,49 @@ func TestByteArrayRoundTrip(t *testing.T) {
 	}
 }
 
+func TestBinaryRoundTrip(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	evalCtx := tree.NewTestingEvalContext(cluster.MakeTestingClusterSettings())
+	defer evalCtx.Stop(context.Background())
+
+	for _, tc := range []struct {
+		typ *types.T
+		s   string
+	}{
+		{typ: types.Oid, s: "12345"},
+		{typ: types.Box2D, s: "BOX(1 2,3.5 4.25)"},
+		{typ: types.Geometry, s: "POINT(1 2)"},
+		{typ: types.Geometry, s: "SRID=4326;LINESTRING(0 0, 1 1, 2 0)"},
+		{typ: types.Geography, s: "SRID=4326;POLYGON((0 0, 1 0, 1 1, 0 0))"},
+	} {
+		t.Run(fmt.Sprintf("%s/%s", tc.typ, tc.s), func(t *testing.T) {
+			d, err := rowenc.ParseDatumStringAs(tc.typ, tc.s, evalCtx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			buf := newWriteBuffer(nil /* bytecount */)
+			buf.bytecount = metric.NewCounter(metric.Metadata{})
+			_, defaultLoc := makeTestingConvCfg()
+			buf.writeBinaryDatum(context.Background(), d, defaultLoc, tc.typ)
+			if buf.err != nil {
+				t.Fatal(buf.err)
+			}
+			b := buf.wrapped.Bytes()
+
+			got, err := pgwirebase.DecodeDatum(evalCtx, tc.typ, pgwirebase.FormatBinary, b[4:])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Compare(evalCtx, d) != 0 {
+				t.Fatalf("expected %s, got %s", d, got)
+			}
+		})
+	}
+}
+
 func TestCanWriteAllDatums(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -1066,6 +1066,74 @@ func (p *PhysicalPlan) AddJoinStage(
 	}
 }
 
+// AddLocalJoinStage adds a join processor for each pair of left and right
+// result routers, and wires the i-th left router and the i-th right router to
+// the i-th processor. Unlike AddJoinStage, rows are not redistributed between
+// nodes: the caller must ensure that all matching rows are produced by routers
+// of the same pair.
+//
+// The caller must also ensure that there are as many left routers as right
+// routers, and that both routers of each pair are on the same node. Violating
+// either of these invariants is an assertion failure, so a caller that cannot
+// guarantee them must check them beforehand and plan a regular join instead.
+func (p *PhysicalPlan) AddLocalJoinStage(
+	core execinfrapb.ProcessorCoreUnion,
+	post execinfrapb.PostProcessSpec,
+	leftTypes, rightTypes []*types.T,
+	leftMergeOrd, rightMergeOrd execinfrapb.Ordering,
+	leftRouters, rightRouters []ProcessorIdx,
+	resultTypes []*types.T,
+) {
+	if len(leftRouters) != len(rightRouters) {
+		panic(errors.AssertionFailedf(
+			"unexpectedly different number of routers: left %d, right %d",
+			len(leftRouters), len(rightRouters),
+		))
+	}
+	nodes := make([]roachpb.NodeID, len(leftRouters))
+	for i := range leftRouters {
+		nodes[i] = p.Processors[leftRouters[i]].Node
+		if rightNode := p.Processors[rightRouters[i]].Node; nodes[i] != rightNode {
+			panic(errors.AssertionFailedf(
+				"routers %d are on different nodes: left n%d, right n%d", i, nodes[i], rightNode,
+			))
+		}
+	}
+
+	pIdxStart := ProcessorIdx(len(p.Processors))
+	stageID := p.NewStageOnNodes(nodes)
+
+	for _, n := range nodes {
+		proc := Processor{
+			Node: n,
+			Spec: execinfrapb.ProcessorSpec{
+				Input: []execinfrapb.InputSyncSpec{
+					{ColumnTypes: leftTypes},
+					{ColumnTypes: rightTypes},
+				},
+				Core:        core,
+				Post:        post,
+				Output:      []execinfrapb.OutputRouterSpec{{Type: execinfrapb.OutputRouterSpec_PASS_THROUGH}},
+				StageID:     stageID,
+				ResultTypes: resultTypes,
+			},
+		}
+		p.Processors = append(p.Processors, proc)
+	}
+
+	p.ResultRouters = p.ResultRouters[:0]
+	for i := range nodes {
+		pIdx := pIdxStart + ProcessorIdx(i)
+		p.MergeResultStreams(
+			leftRouters[i:i+1], 0 /* sourceRouterSlot */, leftMergeOrd, pIdx, 0, false, /* forceSerialization */
+		)
+		p.MergeResultStreams(
+			rightRouters[i:i+1], 0 /* sourceRouterSlot */, rightMergeOrd, pIdx, 1, false, /* forceSerialization */
+		)
+		p.ResultRouters = append(p.ResultRouters, pIdx)
+	}
+}
+
 // AddStageOnNodes adds a stage of processors that take in a single input
 // logical stream on the specified nodes and connects them to the previous
 // stage via a hash router.
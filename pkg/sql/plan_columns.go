@@ -87,6 +87,8 @@ func getPlanColumns(plan planNode, mut bool) colinfo.ResultColumns {
 		return n.columns
 	case *zigzagJoinNode:
 		return n.columns
+	case *interleavedJoinNode:
+		return n.columns
 	case *vTableLookupJoinNode:
 		return n.columns
 	case *invertedFilterNode:
@@ -12,6 +12,7 @@ package sql
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/settings"
@@ -63,12 +64,14 @@ func (p *planner) prepareUsingOptimizer(ctx context.Context) (planFlags, error)
 		*tree.DropTable, *tree.DropView, *tree.DropSequence,
 		*tree.Execute,
 		*tree.Grant, *tree.GrantRole,
+		*tree.Listen, *tree.Notify,
 		*tree.Prepare,
 		*tree.ReleaseSavepoint, *tree.RenameColumn, *tree.RenameDatabase,
 		*tree.RenameIndex, *tree.RenameTable, *tree.Revoke, *tree.RevokeRole,
 		*tree.RollbackToSavepoint, *tree.RollbackTransaction,
 		*tree.Savepoint, *tree.SetTransaction, *tree.SetTracing, *tree.SetSessionAuthorizationDefault,
-		*tree.SetSessionCharacteristics:
+		*tree.SetSessionCharacteristics,
+		*tree.Unlisten:
 		// These statements do not have result columns and do not support placeholders
 		// so there is no need to do anything during prepare.
 		//
@@ -185,10 +188,27 @@ func (p *planner) prepareUsingOptimizer(ctx context.Context) (planFlags, error)
 // makeOptimizerPlan generates a plan using the cost-based optimizer.
 // On success, it populates p.curPlan.
 func (p *planner) makeOptimizerPlan(ctx context.Context) error {
+	err := p.makeOptimizerPlanInternal(ctx, false /* ignorePlanHints */)
+	if err != nil && !p.stmt.Hints.Empty() && errors.Is(err, execbuilder.ErrUnsatisfiableJoinHint) {
+		// Unlike join hints specified inline, plan hints are only suggestions:
+		// if no plan conforms to them, the statement is planned without them.
+		log.VEventf(ctx, 1, "ignoring plan hints: %v", err)
+		err = p.makeOptimizerPlanInternal(ctx, true /* ignorePlanHints */)
+	}
+	return err
+}
+
+func (p *planner) makeOptimizerPlanInternal(ctx context.Context, ignorePlanHints bool) error {
 	p.curPlan.init(&p.stmt, &p.instrumentation)
 
 	opc := &p.optPlanningCtx
 	opc.reset()
+	if ignorePlanHints {
+		// The memo built with the plan hints must not be reused or cached.
+		opc.ignorePlanHints = true
+		opc.allowMemoReuse = false
+		opc.useCache = false
+	}
 
 	execMemo, err := opc.buildExecMemo(ctx)
 	if err != nil {
@@ -286,6 +306,17 @@ type optPlanningCtx struct {
 	// allowMemoReuse is false.
 	useCache bool
 
+	// traceOpt is set when the statement is an EXPLAIN (OPT, MEMO), in which
+	// case the rules applied by the optimizer are recorded in appliedRules and
+	// optTrace is populated once optimization is complete.
+	traceOpt     bool
+	appliedRules []string
+	optTrace     string
+
+	// ignorePlanHints is set when the plan hints of the statement are not
+	// applied, because no plan conforms to them.
+	ignorePlanHints bool
+
 	flags planFlags
 }
 
@@ -302,6 +333,14 @@ func (opc *optPlanningCtx) reset() {
 	opc.catalog.reset()
 	opc.optimizer.Init(p.EvalContext(), &opc.catalog)
 	opc.flags = 0
+	opc.ignorePlanHints = false
+	opc.traceOpt = false
+	opc.appliedRules = opc.appliedRules[:0]
+	opc.optTrace = ""
+	if e, ok := p.stmt.AST.(*tree.Explain); ok && e.Mode == tree.ExplainOpt && e.Flags[tree.ExplainFlagMemo] {
+		opc.traceOpt = true
+		opc.optimizer.NotifyOnAppliedRule(opc.recordAppliedRule)
+	}
 
 	// We only allow memo caching for SELECT/INSERT/UPDATE/DELETE. We could
 	// support it for all statements in principle, but it would increase the
@@ -325,10 +364,54 @@ func (opc *optPlanningCtx) reset() {
 			opc.useCache = false
 		}
 
+		if !p.stmt.Hints.Empty() {
+			// The SQL of a statement prepared with PREPARE ... AS doesn't include
+			// the plan hints, so it could be the cache key of the same statement
+			// without hints.
+			opc.useCache = false
+		}
+
 	default:
 		opc.allowMemoReuse = false
 		opc.useCache = false
 	}
+
+	if opc.traceOpt {
+		// A reused memo may already be fully optimized, in which case no rules
+		// would be applied and the trace would be empty. Always build the memo
+		// from scratch when a trace is requested.
+		opc.allowMemoReuse = false
+		opc.useCache = false
+	}
+}
+
+// recordAppliedRule is an xform.AppliedRuleFunc that records the rules applied
+// by the optimizer for EXPLAIN (OPT, MEMO).
+func (opc *optPlanningCtx) recordAppliedRule(ruleName opt.RuleName, source, target opt.Expr) {
+	var buf strings.Builder
+	buf.WriteString(ruleName.String())
+	if source != nil {
+		fmt.Fprintf(&buf, ": %s", source.Op())
+		if target != nil {
+			fmt.Fprintf(&buf, " => %s", target.Op())
+		}
+	} else if target != nil {
+		fmt.Fprintf(&buf, ": %s", target.Op())
+	}
+	opc.appliedRules = append(opc.appliedRules, buf.String())
+}
+
+// formatOptTrace returns the optimizer trace shown by EXPLAIN (OPT, MEMO): the
+// list of rules that were applied, in order, followed by the memo with the
+// explored alternatives and their costs.
+func (opc *optPlanningCtx) formatOptTrace() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "rules applied (%d):\n", len(opc.appliedRules))
+	for _, r := range opc.appliedRules {
+		fmt.Fprintf(&buf, " ├── %s\n", r)
+	}
+	buf.WriteString(opc.optimizer.FormatMemo(xform.FmtPretty))
+	return buf.String()
 }
 
 func (opc *optPlanningCtx) log(ctx context.Context, msg string) {
@@ -376,6 +459,9 @@ func (opc *optPlanningCtx) buildReusableMemo(ctx context.Context) (_ *memo.Memo,
 	f := opc.optimizer.Factory()
 	bld := optbuilder.New(ctx, &p.semaCtx, p.EvalContext(), &opc.catalog, f, opc.p.stmt.AST)
 	bld.KeepPlaceholders = true
+	if !opc.ignorePlanHints {
+		bld.Hints = opc.p.stmt.Hints
+	}
 	if err := bld.Build(); err != nil {
 		return nil, err
 	}
@@ -454,10 +540,11 @@ func (opc *optPlanningCtx) buildExecMemo(ctx context.Context) (_ *memo.Memo, _ e
 		// available.
 
 		// If the prepared memo has been invalidated by schema or other changes,
-		// re-prepare it.
+		// or its plan was found to be stale during execution, re-prepare it.
 		if isStale, err := prepared.Memo.IsStale(ctx, p.EvalContext(), &opc.catalog); err != nil {
 			return nil, err
-		} else if isStale {
+		} else if isStale || prepared.stalePlan {
+			prepared.stalePlan = false
 			prepared.Memo, err = opc.buildReusableMemo(ctx)
 			opc.log(ctx, "rebuilding cached memo")
 			if err != nil {
@@ -504,6 +591,9 @@ func (opc *optPlanningCtx) buildExecMemo(ctx context.Context) (_ *memo.Memo, _ e
 	f := opc.optimizer.Factory()
 	f.FoldingControl().AllowStableFolds()
 	bld := optbuilder.New(ctx, &p.semaCtx, p.EvalContext(), &opc.catalog, f, opc.p.stmt.AST)
+	if !opc.ignorePlanHints {
+		bld.Hints = opc.p.stmt.Hints
+	}
 	if err := bld.Build(); err != nil {
 		return nil, err
 	}
@@ -512,6 +602,9 @@ func (opc *optPlanningCtx) buildExecMemo(ctx context.Context) (_ *memo.Memo, _ e
 			return nil, err
 		}
 	}
+	if opc.traceOpt {
+		opc.optTrace = opc.formatOptTrace()
+	}
 
 	// If this statement doesn't have placeholders and we have not constant-folded
 	// any VolatilityStable operators, add it to the cache.
@@ -550,6 +643,7 @@ func (opc *optPlanningCtx) runExecBuilder(
 	if !planTop.instrumentation.ShouldBuildExplainPlan() {
 		// No instrumentation.
 		bld := execbuilder.New(f, mem, &opc.catalog, mem.RootExpr(), evalCtx, allowAutoCommit)
+		bld.OptimizerTrace = opc.optTrace
 		plan, err := bld.Build()
 		if err != nil {
 			return err
@@ -562,6 +656,7 @@ func (opc *optPlanningCtx) runExecBuilder(
 		// Create an explain factory and record the explain.Plan.
 		explainFactory := explain.NewFactory(f)
 		bld := execbuilder.New(explainFactory, mem, &opc.catalog, mem.RootExpr(), evalCtx, allowAutoCommit)
+		bld.OptimizerTrace = opc.optTrace
 		plan, err := bld.Build()
 		if err != nil {
 			return err
@@ -159,6 +159,10 @@ type planner struct {
 
 	preparedStatements preparedStatementsAccessor
 
+	// notifications gives access to the session's LISTEN and NOTIFY state. It
+	// is nil for planners which are not associated with a session.
+	notifications notificationsAccessor
+
 	// avoidCachedDescriptors, when true, instructs all code that
 	// accesses table/view descriptors to force reading the descriptors
 	// within the transaction. This is necessary to read descriptors
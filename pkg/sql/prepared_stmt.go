@@ -50,6 +50,11 @@ type PreparedStatement struct {
 	// if it is used by the optimizer as a starting point.
 	Memo *memo.Memo
 
+	// stalePlan is set when an execution of the statement detected that the
+	// plan in Memo was based on stale statistics; Memo is rebuilt on the next
+	// execution.
+	stalePlan bool
+
 	// refCount keeps track of the number of references to this PreparedStatement.
 	// New references are registered through incRef().
 	// Once refCount hits 0 (through calls to decRef()), the following memAcc is
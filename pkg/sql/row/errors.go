@@ -45,9 +45,13 @@ func (f *singleKVFetcher) nextBatch(
 	return true, f.kvs[:], nil, roachpb.Span{}, nil
 }
 
-// ConvertBatchError returns a user friendly constraint violation error.
+// ConvertBatchError returns a user friendly constraint violation or lock
+// conflict error.
 func ConvertBatchError(ctx context.Context, tableDesc catalog.TableDescriptor, b *kv.Batch) error {
 	origPErr := b.MustPErr()
+	if wiErr, ok := origPErr.GetDetail().(*roachpb.WriteIntentError); ok {
+		return NewLockNotAvailableError(ctx, tableDesc, wiErr.Intents[0].Key)
+	}
 	if origPErr.Index == nil {
 		return origPErr.GoError()
 	}
@@ -14,6 +14,7 @@ import (
 	"bytes"
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -184,8 +185,9 @@ func (f *txnKVFetcher) getWaitPolicy() lock.WaitPolicy {
 		return lock.WaitPolicy_Block
 
 	case descpb.ScanLockingWaitPolicy_SKIP:
-		// Should not get here. Query should be rejected during planning.
-		panic(errors.AssertionFailedf("unsupported wait policy %s", f.lockWaitPolicy))
+		// Conflicting locks are reported as errors, and the rows they are on
+		// are then skipped by the fetcher. See send.
+		return lock.WaitPolicy_Error
 
 	case descpb.ScanLockingWaitPolicy_ERROR:
 		return lock.WaitPolicy_Error
@@ -300,48 +302,135 @@ func makeKVBatchFetcherWithSendFunc(
 // return.
 const maxScanResponseBytes = 10 * (1 << 20)
 
-// fetch retrieves spans from the kv layer.
-func (f *txnKVFetcher) fetch(ctx context.Context) error {
-	var ba roachpb.BatchRequest
-	ba.Header.WaitPolicy = f.getWaitPolicy()
-	ba.Header.MaxSpanRequestKeys = f.getBatchSize()
-	if ba.Header.MaxSpanRequestKeys > 0 {
-		// If this kvfetcher limits the number of rows returned, also use
-		// target bytes to guard against the case in which the average row
-		// is very large.
-		// If no limit is set, the assumption is that SQL *knows* that there
-		// is only a "small" amount of data to be read, and wants to preserve
-		// concurrency for this request inside of DistSender, which setting
-		// TargetBytes would interfere with.
-		ba.Header.TargetBytes = maxScanResponseBytes
-	}
-	ba.Requests = make([]roachpb.RequestUnion, len(f.spans))
+// makeScanRequests returns the scan requests for the given spans.
+func (f *txnKVFetcher) makeScanRequests(spans roachpb.Spans) []roachpb.RequestUnion {
+	reqs := make([]roachpb.RequestUnion, len(spans))
 	keyLocking := f.getKeyLockingStrength()
 	if f.reverse {
 		scans := make([]struct {
 			req   roachpb.ReverseScanRequest
 			union roachpb.RequestUnion_ReverseScan
-		}, len(f.spans))
-		for i := range f.spans {
-			scans[i].req.SetSpan(f.spans[i])
+		}, len(spans))
+		for i := range spans {
+			scans[i].req.SetSpan(spans[i])
 			scans[i].req.ScanFormat = roachpb.BATCH_RESPONSE
 			scans[i].req.KeyLocking = keyLocking
 			scans[i].union.ReverseScan = &scans[i].req
-			ba.Requests[i].Value = &scans[i].union
+			reqs[i].Value = &scans[i].union
 		}
 	} else {
 		scans := make([]struct {
 			req   roachpb.ScanRequest
 			union roachpb.RequestUnion_Scan
-		}, len(f.spans))
-		for i := range f.spans {
-			scans[i].req.SetSpan(f.spans[i])
+		}, len(spans))
+		for i := range spans {
+			scans[i].req.SetSpan(spans[i])
 			scans[i].req.ScanFormat = roachpb.BATCH_RESPONSE
 			scans[i].req.KeyLocking = keyLocking
 			scans[i].union.Scan = &scans[i].req
-			ba.Requests[i].Value = &scans[i].union
+			reqs[i].Value = &scans[i].union
+		}
+	}
+	return reqs
+}
+
+// send sends the batch, whose requests correspond to f.requestSpans.
+//
+// If the fetcher skips locked rows (SKIP LOCKED), the batch is sent with the
+// Error wait policy. When a conflicting lock is encountered, the row it is on
+// is removed from f.requestSpans and the batch is resent, until it succeeds.
+func (f *txnKVFetcher) send(
+	ctx context.Context, ba *roachpb.BatchRequest,
+) (*roachpb.BatchResponse, error) {
+	for {
+		br, err := f.sendFn(ctx, *ba)
+		if err == nil || f.lockWaitPolicy != descpb.ScanLockingWaitPolicy_SKIP {
+			return br, err
+		}
+		var wiErr *roachpb.WriteIntentError
+		if !errors.As(err, &wiErr) {
+			return nil, err
+		}
+		skipped := false
+		for _, intent := range wiErr.Intents {
+			rowPrefix, keyErr := keys.EnsureSafeSplitKey(intent.Key)
+			if keyErr != nil {
+				return nil, err
+			}
+			rowSpan := roachpb.Span{Key: rowPrefix, EndKey: rowPrefix.PrefixEnd()}
+			var ok bool
+			if f.requestSpans, ok = subtractSpan(f.requestSpans, rowSpan, f.reverse); ok {
+				log.VEventf(ctx, 2, "skipping locked row %s", rowSpan)
+				skipped = true
+			}
+		}
+		if !skipped {
+			// The conflicting lock is not within the spans being fetched; we
+			// can't make progress by skipping it.
+			return nil, err
+		}
+		if len(f.requestSpans) == 0 {
+			return &roachpb.BatchResponse{}, nil
+		}
+		ba.Requests = f.makeScanRequests(f.requestSpans)
+	}
+}
+
+// subtractSpan removes the given span from spans, returning the result and
+// whether any of spans overlapped with the removed span. The returned spans
+// reuse the memory of the given ones only when nothing is removed.
+//
+// The spans are ordered in the direction of the scan, so when a span is split
+// in two for a reverse scan, the piece after the removed span comes first.
+func subtractSpan(
+	spans roachpb.Spans, remove roachpb.Span, reverse bool,
+) (roachpb.Spans, bool) {
+	var res roachpb.Spans
+	overlapped := false
+	for _, sp := range spans {
+		if !sp.Overlaps(remove) {
+			res = append(res, sp)
+			continue
+		}
+		overlapped = true
+		var before, after roachpb.Span
+		if sp.Key.Compare(remove.Key) < 0 {
+			before = roachpb.Span{Key: sp.Key, EndKey: remove.Key}
+		}
+		if remove.EndKey.Compare(sp.EndKey) < 0 {
+			after = roachpb.Span{Key: remove.EndKey, EndKey: sp.EndKey}
+		}
+		if reverse {
+			before, after = after, before
+		}
+		for _, piece := range [...]roachpb.Span{before, after} {
+			if piece.Key != nil {
+				res = append(res, piece)
+			}
 		}
 	}
+	if !overlapped {
+		return spans, false
+	}
+	return res, true
+}
+
+// fetch retrieves spans from the kv layer.
+func (f *txnKVFetcher) fetch(ctx context.Context) error {
+	var ba roachpb.BatchRequest
+	ba.Header.WaitPolicy = f.getWaitPolicy()
+	ba.Header.MaxSpanRequestKeys = f.getBatchSize()
+	if ba.Header.MaxSpanRequestKeys > 0 {
+		// If this kvfetcher limits the number of rows returned, also use
+		// target bytes to guard against the case in which the average row
+		// is very large.
+		// If no limit is set, the assumption is that SQL *knows* that there
+		// is only a "small" amount of data to be read, and wants to preserve
+		// concurrency for this request inside of DistSender, which setting
+		// TargetBytes would interfere with.
+		ba.Header.TargetBytes = maxScanResponseBytes
+	}
+	ba.Requests = f.makeScanRequests(f.spans)
 	if cap(f.requestSpans) < len(f.spans) {
 		f.requestSpans = make(roachpb.Spans, len(f.spans))
 	} else {
@@ -376,7 +465,7 @@ func (f *txnKVFetcher) fetch(ctx context.Context) error {
 	// Reset spans in preparation for adding resume-spans below.
 	f.spans = f.spans[:0]
 
-	br, err := f.sendFn(ctx, ba)
+	br, err := f.send(ctx, &ba)
 	if err != nil {
 		return err
 	}
@@ -1130,6 +1130,67 @@ func TestIndexJoiner(t *testing.T) {
 	}
 }
 
+// TestIndexJoinerBatchSizeSetting verifies that index joins batch their input
+// rows according to the sql.distsql.index_join.batch_size setting.
+func TestIndexJoinerBatchSizeSetting(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	sqlutils.CreateTable(t, sqlDB, "t", "a INT PRIMARY KEY, b INT", 10, sqlutils.ToRowFn(
+		sqlutils.RowIdxFn,
+		func(row int) tree.Datum { return tree.NewDInt(tree.DInt(row * 10)) },
+	))
+	td := catalogkv.TestingGetTableDescriptor(kvDB, keys.SystemSQLCodec, "test", "t")
+
+	st := cluster.MakeTestingClusterSettings()
+	// Every input row is looked up in a batch of its own.
+	indexJoinBatchSize.Override(&st.SV, 1)
+	evalCtx := tree.MakeTestingEvalContext(st)
+	defer evalCtx.Stop(ctx)
+	flowCtx := execinfra.FlowCtx{
+		EvalCtx: &evalCtx,
+		Cfg:     &execinfra.ServerConfig{Settings: st},
+		Txn:     kv.NewTxn(ctx, s.DB(), s.NodeID()),
+	}
+
+	in := distsqlutils.NewRowBuffer(rowenc.OneIntCol, rowenc.EncDatumRows{
+		{rowenc.IntEncDatum(2)},
+		{rowenc.IntEncDatum(5)},
+		{rowenc.IntEncDatum(7)},
+	}, distsqlutils.RowBufferArgs{})
+	out := &distsqlutils.RowBuffer{}
+	jr, err := newJoinReader(
+		&flowCtx,
+		0, /* processorID */
+		&execinfrapb.JoinReaderSpec{Table: *td.TableDesc()},
+		in,
+		&execinfrapb.PostProcessSpec{},
+		out,
+		indexJoinReaderType,
+	)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), jr.(*joinReader).batchSizeBytes)
+
+	jr.Run(ctx)
+	require.True(t, out.ProducerClosed())
+	var res rowenc.EncDatumRows
+	for {
+		row, meta := out.Next()
+		if meta != nil && meta.Metrics == nil {
+			t.Fatalf("unexpected metadata %+v", meta)
+		}
+		if row == nil {
+			break
+		}
+		res = append(res, row)
+	}
+	require.Equal(t, "[[2 20] [5 50] [7 70]]", res.String(rowenc.TwoIntCols))
+}
+
 // BenchmarkJoinReader benchmarks different lookup join match ratios against a
 // table with half a million rows. A match ratio specifies how many rows are
 // returned for a single lookup row. Some cases will cause the join reader to
@@ -2192,6 +2192,24 @@ nearest replica.`, defaultFollowerReadDuration),
 		},
 	),
 
+	tree.WithMaxStalenessFunctionName: makeBuiltin(
+		tree.FunctionProperties{},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"max_staleness", types.Interval}},
+			ReturnType: tree.FixedReturnType(types.TimestampTZ),
+			Fn:         withMaxStaleness,
+			Info: `Returns the most recent timestamp which is at most max_staleness older
+than the statement time, and which is likely to be safe to perform against a
+follower replica if max_staleness allows it.
+
+This function is intended to be used with an AS OF SYSTEM TIME clause to perform
+bounded staleness reads: reads which are served by the closest replica when the
+staleness bound is larger than the follower read lag, and by the leaseholder
+otherwise.`,
+			Volatility: tree.VolatilityVolatile,
+		},
+	),
+
 	tree.FollowerReadTimestampExperimentalFunctionName: makeBuiltin(
 		tree.FunctionProperties{},
 		tree.Overload{
@@ -6877,6 +6895,24 @@ func followerReadTimestamp(ctx *tree.EvalContext, _ tree.Datums) (tree.Datum, er
 	return tree.MakeDTimestampTZ(ts, time.Microsecond)
 }
 
+func withMaxStaleness(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+	maxStaleness := tree.MustBeDInterval(args[0]).Duration
+	if maxStaleness.Compare(duration.Duration{}) < 0 {
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+			"%s: interval must be non-negative", tree.WithMaxStalenessFunctionName)
+	}
+	followerTS, err := recentTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Read at the follower read timestamp, unless it is staler than allowed.
+	ts := followerTS
+	if minTS := duration.Add(ctx.StmtTimestamp, maxStaleness.Mul(-1)); minTS.After(ts) {
+		ts = minTS
+	}
+	return tree.MakeDTimestampTZ(ts, time.Microsecond)
+}
+
 func jsonNumInvertedIndexEntries(_ *tree.EvalContext, val tree.Datum) (tree.Datum, error) {
 	if val == tree.DNull {
 		return tree.DZero, nil
@@ -35,8 +35,13 @@ const FollowerReadTimestampFunctionName = "follower_read_timestamp"
 // "experimental_" function, which we keep for backwards compatibility.
 const FollowerReadTimestampExperimentalFunctionName = "experimental_follower_read_timestamp"
 
-var errInvalidExprForAsOf = errors.Errorf("AS OF SYSTEM TIME: only constant expressions or " +
-	FollowerReadTimestampFunctionName + " are allowed")
+// WithMaxStalenessFunctionName is the name of the function which can be used
+// with AOST clauses to perform a read that is served by the nearest replica
+// when possible, and whose staleness is bounded by the function's argument.
+const WithMaxStalenessFunctionName = "with_max_staleness"
+
+var errInvalidExprForAsOf = errors.Errorf("AS OF SYSTEM TIME: only constant expressions, " +
+	FollowerReadTimestampFunctionName + " or " + WithMaxStalenessFunctionName + " are allowed")
 
 // IsFollowerReadTimestampFunction determines whether the AS OF SYSTEM TIME
 // clause contains a simple invocation of the follower_read_timestamp function.
@@ -52,6 +57,20 @@ func IsFollowerReadTimestampFunction(asOf AsOfClause, searchPath sessiondata.Sea
 	return def.Name == FollowerReadTimestampFunctionName || def.Name == FollowerReadTimestampExperimentalFunctionName
 }
 
+// IsWithMaxStalenessFunction determines whether the AS OF SYSTEM TIME clause
+// contains an invocation of the with_max_staleness function.
+func IsWithMaxStalenessFunction(asOf AsOfClause, searchPath sessiondata.SearchPath) bool {
+	fe, ok := asOf.Expr.(*FuncExpr)
+	if !ok {
+		return false
+	}
+	def, err := fe.Func.Resolve(searchPath)
+	if err != nil {
+		return false
+	}
+	return def.Name == WithMaxStalenessFunctionName
+}
+
 // EvalAsOfTimestamp evaluates the timestamp argument to an AS OF SYSTEM TIME query.
 func EvalAsOfTimestamp(
 	ctx context.Context, asOf AsOfClause, semaCtx *SemaContext, evalCtx *EvalContext,
@@ -64,13 +83,15 @@ func EvalAsOfTimestamp(
 	scalarProps.Require("AS OF SYSTEM TIME", RejectSpecial|RejectSubqueries)
 
 	// In order to support the follower reads feature we permit this expression
-	// to be a simple invocation of the follower_read_timestamp function.
+	// to be a simple invocation of the follower_read_timestamp function, or an
+	// invocation of the with_max_staleness function with a constant argument.
 	// Over time we could expand the set of allowed functions or expressions.
 	// All non-function expressions must be const and must TypeCheck into a
 	// string.
 	var te TypedExpr
 	if _, ok := asOf.Expr.(*FuncExpr); ok {
-		if !IsFollowerReadTimestampFunction(asOf, semaCtx.SearchPath) {
+		withMaxStaleness := IsWithMaxStalenessFunction(asOf, semaCtx.SearchPath)
+		if !withMaxStaleness && !IsFollowerReadTimestampFunction(asOf, semaCtx.SearchPath) {
 			return hlc.Timestamp{}, errInvalidExprForAsOf
 		}
 		var err error
@@ -78,6 +99,13 @@ func EvalAsOfTimestamp(
 		if err != nil {
 			return hlc.Timestamp{}, err
 		}
+		if withMaxStaleness {
+			for _, arg := range te.(*FuncExpr).Exprs {
+				if !IsConst(evalCtx, arg.(TypedExpr)) {
+					return hlc.Timestamp{}, errInvalidExprForAsOf
+				}
+			}
+		}
 	} else {
 		var err error
 		te, err = asOf.Expr.TypeCheck(ctx, semaCtx, types.String)
@@ -105,6 +105,7 @@ const (
 	ExplainFlagEnv
 	ExplainFlagCatalog
 	ExplainFlagJSON
+	ExplainFlagMemo
 	numExplainFlags = iota
 )
 
@@ -114,6 +115,7 @@ var explainFlagStrings = [...]string{
 	ExplainFlagEnv:     "ENV",
 	ExplainFlagCatalog: "CATALOG",
 	ExplainFlagJSON:    "JSON",
+	ExplainFlagMemo:    "MEMO",
 }
 
 var explainFlagStringMap = func() map[string]ExplainFlag {
@@ -246,6 +248,9 @@ func MakeExplain(options []string, stmt Statement) (Statement, error) {
 			return nil, pgerror.Newf(pgcode.Syntax, "the JSON flag cannot be used with ANALYZE")
 		}
 	}
+	if opts.Flags[ExplainFlagMemo] && (opts.Mode != ExplainOpt || analyze) {
+		return nil, pgerror.Newf(pgcode.Syntax, "the MEMO flag can only be used with OPT")
+	}
 
 	if analyze {
 		if opts.Mode != ExplainDistSQL && opts.Mode != ExplainDebug && opts.Mode != ExplainPlan {
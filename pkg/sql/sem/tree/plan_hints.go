@@ -0,0 +1,191 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+import (
+	"strings"
+	"unicode"
+)
+
+// PlanHints contains the optimizer hints specified in a special comment of the
+// form /*+ ... */ inside a statement. Hints allow the plan of a statement to be
+// adjusted without rewriting the query itself, e.g.:
+//
+//   SELECT /*+ INDEX(a a_b_idx) LOOKUP_JOIN(b) */ * FROM a JOIN b ON a.x = b.x
+//
+// The following hints are supported (table arguments refer to table names or
+// aliases as they appear in the statement):
+//
+//   INDEX(t idx)         force the scan of table t to use index idx
+//   NO_INDEX_JOIN(t)     disallow index joins when scanning table t
+//   HASH_JOIN[(t ...)]   use a hash join to join tables t (all joins if
+//                        no table is specified)
+//   MERGE_JOIN[(t ...)]  use a merge join, as above
+//   LOOKUP_JOIN[(t ...)] use a lookup join into tables t, as above
+//   ORDERED              join tables in the order in which they appear
+//
+// Hints that are not recognized or are malformed are ignored, in the same way
+// that the comment would be ignored if it were not a hint.
+type PlanHints struct {
+	// Indexes maps a table name or alias to the index flags used when that
+	// table is scanned.
+	Indexes map[Name]*IndexFlags
+
+	// Joins maps a table name or alias to the join hint (one of AstHash,
+	// AstMerge or AstLookup) used when that table is the right input of a join.
+	Joins map[Name]string
+
+	// DefaultJoin is the join hint used for all joins that don't have a more
+	// specific hint. It is empty if no such hint was specified.
+	DefaultJoin string
+
+	// Ordered is true if the joins must be executed in the order in which they
+	// appear in the statement.
+	Ordered bool
+}
+
+// Empty returns true if no hints are set.
+func (h *PlanHints) Empty() bool {
+	return h == nil || (len(h.Indexes) == 0 && len(h.Joins) == 0 && h.DefaultJoin == "" && !h.Ordered)
+}
+
+// IndexFlagsForTable returns the index flags hinted for the given table name
+// or alias, or nil if there are none.
+func (h *PlanHints) IndexFlagsForTable(name Name) *IndexFlags {
+	if h == nil {
+		return nil
+	}
+	return h.Indexes[name]
+}
+
+// JoinHintForTable returns the join hint for a join with the given table name
+// or alias on its right side, or the empty string if there is none.
+func (h *PlanHints) JoinHintForTable(name Name) string {
+	if h == nil {
+		return ""
+	}
+	if hint, ok := h.Joins[name]; ok {
+		return hint
+	}
+	return h.DefaultJoin
+}
+
+// planHintJoinMethods maps the join method hints to the JoinTableExpr hint
+// they correspond to.
+var planHintJoinMethods = map[string]string{
+	"HASH_JOIN":   AstHash,
+	"MERGE_JOIN":  AstMerge,
+	"LOOKUP_JOIN": AstLookup,
+}
+
+// Parse parses the contents of a hint comment (without the leading "/*+" and
+// trailing "*/") and adds the hints found to h.
+func (h *PlanHints) Parse(comment string) {
+	toks := tokenizePlanHints(comment)
+	for i := 0; i < len(toks); i++ {
+		name := strings.ToUpper(toks[i])
+		var args []Name
+		if i+1 < len(toks) && toks[i+1] == "(" {
+			end := i + 2
+			for end < len(toks) && toks[end] != ")" {
+				if toks[end] != "," {
+					args = append(args, Name(toks[end]))
+				}
+				end++
+			}
+			if end == len(toks) {
+				// Unterminated argument list; ignore the rest of the comment.
+				return
+			}
+			i = end
+		}
+
+		switch name {
+		case "INDEX":
+			if len(args) == 2 {
+				h.addIndexFlags(args[0], &IndexFlags{Index: UnrestrictedName(args[1]), FromPlanHint: true})
+			}
+
+		case "NO_INDEX_JOIN":
+			for _, t := range args {
+				h.addIndexFlags(t, &IndexFlags{NoIndexJoin: true, FromPlanHint: true})
+			}
+
+		case "ORDERED":
+			h.Ordered = true
+
+		default:
+			method, ok := planHintJoinMethods[name]
+			if !ok {
+				continue
+			}
+			if len(args) == 0 {
+				h.DefaultJoin = method
+				continue
+			}
+			if h.Joins == nil {
+				h.Joins = make(map[Name]string)
+			}
+			for _, t := range args {
+				h.Joins[t] = method
+			}
+		}
+	}
+}
+
+// addIndexFlags sets the index flags for the given table. Flags that conflict
+// with flags previously hinted for the same table are ignored.
+func (h *PlanHints) addIndexFlags(table Name, flags *IndexFlags) {
+	if h.Indexes == nil {
+		h.Indexes = make(map[Name]*IndexFlags)
+	}
+	if prev, ok := h.Indexes[table]; ok {
+		combined := *prev
+		if combined.CombineWith(flags) != nil || combined.Check() != nil {
+			return
+		}
+		*prev = combined
+		return
+	}
+	h.Indexes[table] = flags
+}
+
+// tokenizePlanHints splits a hint comment into hint names, arguments and the
+// punctuation characters "(", ")" and ",". Unquoted names are lowercased like
+// SQL identifiers, except that the caller uppercases hint names; double-quoted
+// names are kept as-is.
+func tokenizePlanHints(s string) []string {
+	var toks []string
+	for i := 0; i < len(s); {
+		ch := s[i]
+		switch {
+		case ch == '(' || ch == ')' || ch == ',':
+			toks = append(toks, s[i:i+1])
+			i++
+		case ch == '"':
+			end := strings.IndexByte(s[i+1:], '"')
+			if end < 0 {
+				return toks
+			}
+			toks = append(toks, s[i+1:i+1+end])
+			i += end + 2
+		case unicode.IsSpace(rune(ch)):
+			i++
+		default:
+			start := i
+			for i < len(s) && !unicode.IsSpace(rune(s[i])) && !strings.ContainsRune("(),\"", rune(s[i])) {
+				i++
+			}
+			toks = append(toks, strings.ToLower(s[start:i]))
+		}
+	}
+	return toks
+}
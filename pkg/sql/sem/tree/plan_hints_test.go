@@ -0,0 +1,91 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree_test
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanHints(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testCases := []struct {
+		sql      string
+		expected *tree.PlanHints
+	}{
+		{`SELECT * FROM a`, nil},
+		{`SELECT /* INDEX(a idx) */ * FROM a`, nil},
+		{`SELECT /*+ UNKNOWN(a) */ * FROM a`, nil},
+		{`SELECT /*+ INDEX(a */ * FROM a`, nil},
+		{
+			`SELECT /*+ INDEX(a idx) */ * FROM a`,
+			&tree.PlanHints{Indexes: map[tree.Name]*tree.IndexFlags{"a": {Index: "idx", FromPlanHint: true}}},
+		},
+		{
+			`SELECT /*+ index("A" "Idx") no_index_join(b) */ * FROM "A", b`,
+			&tree.PlanHints{Indexes: map[tree.Name]*tree.IndexFlags{
+				"A": {Index: "Idx", FromPlanHint: true},
+				"b": {NoIndexJoin: true, FromPlanHint: true},
+			}},
+		},
+		{
+			// Conflicting hints for the same table are ignored.
+			`SELECT /*+ INDEX(a idx) NO_INDEX_JOIN(a) */ * FROM a`,
+			&tree.PlanHints{Indexes: map[tree.Name]*tree.IndexFlags{"a": {Index: "idx", FromPlanHint: true}}},
+		},
+		{
+			`SELECT /*+ LOOKUP_JOIN(b, c) ORDERED */ * FROM a JOIN b ON true JOIN c ON true`,
+			&tree.PlanHints{
+				Joins:   map[tree.Name]string{"b": tree.AstLookup, "c": tree.AstLookup},
+				Ordered: true,
+			},
+		},
+		{
+			`SELECT /*+ HASH_JOIN */ * FROM a /*+ MERGE_JOIN(b) */ JOIN b USING (x)`,
+			&tree.PlanHints{
+				Joins:       map[tree.Name]string{"b": tree.AstMerge},
+				DefaultJoin: tree.AstHash,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.sql, func(t *testing.T) {
+			stmt, err := parser.ParseOne(tc.sql)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, stmt.Hints)
+		})
+	}
+}
+
+func TestPlanHintsLookup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	var h tree.PlanHints
+	h.Parse(`LOOKUP_JOIN(b) HASH_JOIN INDEX(a idx)`)
+	require.Equal(t, tree.AstLookup, h.JoinHintForTable("b"))
+	require.Equal(t, tree.AstHash, h.JoinHintForTable("c"))
+	require.Equal(t, &tree.IndexFlags{Index: "idx", FromPlanHint: true}, h.IndexFlagsForTable("a"))
+	require.Nil(t, h.IndexFlagsForTable("b"))
+
+	var empty *tree.PlanHints
+	require.True(t, empty.Empty())
+	require.Equal(t, "", empty.JoinHintForTable("b"))
+	require.Nil(t, empty.IndexFlagsForTable("a"))
+}
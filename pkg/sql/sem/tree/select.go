@@ -281,6 +281,10 @@ type IndexFlags struct {
 	// references from this table. This is useful in particular for scrub queries
 	// used to verify the consistency of foreign key relations.
 	IgnoreForeignKeys bool
+	// FromPlanHint is set if the flags were specified by the plan hints of the
+	// statement (see PlanHints) rather than inline. Such flags are ignored,
+	// rather than causing an error, when they cannot be satisfied.
+	FromPlanHint bool
 }
 
 // ForceIndex returns true if a forced index was specified, either using a name
@@ -48,6 +48,16 @@ func (p *planner) SetVar(ctx context.Context, n *tree.SetVar) (planNode, error)
 	}
 
 	name := strings.ToLower(n.Name)
+	if name == "all" {
+		// RESET ALL resets all the session variables to their default value.
+		if len(n.Values) != 1 || n.Values[0] != (tree.DefaultVal{}) {
+			return nil, pgerror.New(pgcode.Syntax, "SET ALL is not supported, use RESET ALL")
+		}
+		if err := resetSessionVars(ctx, p.sessionDataMutator); err != nil {
+			return nil, err
+		}
+		return newZeroNode(nil /* columns */), nil
+	}
 	_, v, err := getSessionVar(name, false /* missingOk */)
 	if err != nil {
 		return nil, err
@@ -304,6 +314,16 @@ func stmtTimeoutVarSet(ctx context.Context, m *sessionDataMutator, s string) err
 	return nil
 }
 
+func lockTimeoutVarSet(ctx context.Context, m *sessionDataMutator, s string) error {
+	timeout, err := validateTimeoutVar(s, "lock_timeout")
+	if err != nil {
+		return err
+	}
+
+	m.SetLockTimeout(timeout)
+	return nil
+}
+
 func idleInSessionTimeoutVarSet(ctx context.Context, m *sessionDataMutator, s string) error {
 	timeout, err := validateTimeoutVar(s, "idle_in_session_timeout")
 	if err != nil {
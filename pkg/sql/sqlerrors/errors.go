@@ -214,6 +214,17 @@ func NewAggInAggError() error {
 var QueryTimeoutError = pgerror.New(
 	pgcode.QueryCanceled, "query execution canceled due to statement timeout")
 
+// IdleInTransactionSessionTimeoutError is the error sent to the client when
+// its session is terminated because of idle_in_transaction_session_timeout.
+var IdleInTransactionSessionTimeoutError = pgerror.New(
+	pgcode.IdleInTransactionSessionTimeout,
+	"terminating connection due to idle-in-transaction timeout")
+
+// IdleSessionTimeoutError is the error sent to the client when its session is
+// terminated because of idle_in_session_timeout.
+var IdleSessionTimeoutError = pgerror.New(
+	pgcode.IdleSessionTimeout, "terminating connection due to idle-session timeout")
+
 // IsOutOfMemoryError checks whether this is an out of memory error.
 func IsOutOfMemoryError(err error) bool {
 	return errHasCode(err, pgcode.OutOfMemory)
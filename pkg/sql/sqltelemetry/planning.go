@@ -74,6 +74,10 @@ var IndexHintUseCounter = telemetry.GetCounterOnce("sql.plan.hints.index")
 // index hint in a SELECT.
 var IndexHintSelectUseCounter = telemetry.GetCounterOnce("sql.plan.hints.index.select")
 
+// PlanHintUseCounter is to be incremented whenever a hint specified in a plan
+// hint comment (/*+ ... */) is applied to a table scan or join.
+var PlanHintUseCounter = telemetry.GetCounterOnce("sql.plan.hints.comment")
+
 // IndexHintUpdateUseCounter is to be incremented whenever a query specifies an
 // index hint in an UPDATE.
 var IndexHintUpdateUseCounter = telemetry.GetCounterOnce("sql.plan.hints.index.update")
@@ -142,6 +146,19 @@ var JoinAlgoLookupUseCounter = telemetry.GetCounterOnce("sql.plan.opt.node.join.
 // planned.
 var JoinAlgoCrossUseCounter = telemetry.GetCounterOnce("sql.plan.opt.node.join.algo.cross")
 
+// StalePlanDetectedCounter is to be incremented whenever the actual row count
+// of a statement diverges from the estimate enough for its plan to be
+// considered stale.
+var StalePlanDetectedCounter = telemetry.GetCounterOnce("sql.plan.stale-plan-detected")
+
+// InterleavedJoinCounter is to be incremented whenever a join between
+// interleaved tables is planned as a local join.
+var InterleavedJoinCounter = telemetry.GetCounterOnce("sql.plan.interleaved-join")
+
+// InterleavedScanJoinCounter is to be incremented whenever a join between
+// interleaved tables is planned as a single scan of the interleaved keyspace.
+var InterleavedScanJoinCounter = telemetry.GetCounterOnce("sql.plan.interleaved-scan-join")
+
 // JoinTypeInnerUseCounter is to be incremented whenever an inner join node is
 // planned.
 var JoinTypeInnerUseCounter = telemetry.GetCounterOnce("sql.plan.opt.node.join.type.inner")
@@ -0,0 +1,172 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// stalePlanRowCountRatio is the threshold on the divergence between the
+// estimated and the actual number of rows returned by a statement above which
+// the plan of the statement is considered stale.
+var stalePlanRowCountRatio = settings.RegisterFloatSetting(
+	"sql.plan_regression_guard.row_count_ratio",
+	"if positive, a statement whose actual row count differs from the estimated "+
+		"row count by more than this factor has its cached plan invalidated and "+
+		"the statistics of the tables it reads refreshed",
+	0,
+	settings.NonNegativeFloat,
+)
+
+// stalePlanRefreshInterval is the minimum interval between two statistics
+// refreshes of the same table requested because of a stale plan.
+var stalePlanRefreshInterval = settings.RegisterDurationSetting(
+	"sql.plan_regression_guard.min_refresh_interval",
+	"the minimum interval between two statistics refreshes of a table that "+
+		"are caused by statements whose cached plan is stale",
+	time.Minute,
+	settings.NonNegativeDuration,
+)
+
+// stalePlanMinRows is the minimum number of rows (estimated or actual) for
+// which a divergence between the estimated and actual row counts is taken into
+// account. It prevents small statements from triggering statistics refreshes.
+const stalePlanMinRows = 1000
+
+// isStaleRowCountEstimate returns true if the estimated and actual row counts
+// diverge by more than the given ratio.
+func isStaleRowCountEstimate(estimated, actual, ratio float64) bool {
+	if ratio <= 0 {
+		return false
+	}
+	lo, hi := math.Min(estimated, actual), math.Max(estimated, actual)
+	if hi < stalePlanMinRows {
+		return false
+	}
+	return hi/math.Max(lo, 1) > ratio
+}
+
+// stalePlanRateLimiter limits the rate at which the statistics of each table
+// are refreshed because of stale plans, so that a frequent statement whose
+// estimate keeps diverging (for instance because its filter is not
+// representable by the statistics) doesn't refresh the statistics of its
+// tables over and over.
+type stalePlanRateLimiter struct {
+	mu struct {
+		syncutil.Mutex
+		// lastRefresh is the time of the last refresh of each table, for the
+		// tables which were refreshed in the last interval.
+		lastRefresh map[descpb.ID]time.Time
+	}
+}
+
+func newStalePlanRateLimiter() *stalePlanRateLimiter {
+	l := &stalePlanRateLimiter{}
+	l.mu.lastRefresh = make(map[descpb.ID]time.Time)
+	return l
+}
+
+// tryRefresh returns true if the table was not refreshed in the given
+// interval, in which case it records a refresh of the table at the given time.
+func (l *stalePlanRateLimiter) tryRefresh(id descpb.ID, now time.Time, interval time.Duration) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if last, ok := l.mu.lastRefresh[id]; ok && now.Sub(last) < interval {
+		return false
+	}
+	// Forget the tables which can be refreshed again.
+	for tableID, last := range l.mu.lastRefresh {
+		if now.Sub(last) >= interval {
+			delete(l.mu.lastRefresh, tableID)
+		}
+	}
+	l.mu.lastRefresh[id] = now
+	return true
+}
+
+// maybeInvalidateStalePlan compares the number of rows returned by the
+// statement that was just executed with the number of rows estimated by the
+// optimizer. If they diverge past sql.plan_regression_guard.row_count_ratio,
+// the statistics the estimate was based on are likely stale: the cached plan of
+// the statement is invalidated and a statistics refresh is requested for all
+// the tables the statement reads, so that the next execution re-optimizes the
+// statement. Virtual tables have no statistics and are ignored, and the
+// statistics of each table are refreshed at most once per
+// sql.plan_regression_guard.min_refresh_interval: once all the tables read by
+// the statement were refreshed recently, the plan is kept, since it would be
+// re-optimized with the same statistics.
+func (ex *connExecutor) maybeInvalidateStalePlan(
+	ctx context.Context, planner *planner, res RestrictedCommandResult,
+) {
+	if ex.executorType == executorTypeInternal || res.Err() != nil {
+		return
+	}
+	stmt := planner.stmt
+	mem := planner.curPlan.mem
+	if mem == nil || stmt.AST.StatementType() != tree.Rows {
+		return
+	}
+	ratio := stalePlanRowCountRatio.Get(&ex.server.cfg.Settings.SV)
+	if ratio <= 0 {
+		return
+	}
+	root, ok := mem.RootExpr().(memo.RelExpr)
+	if !ok {
+		return
+	}
+	stats := root.Relational().Stats
+	if !stats.Available {
+		// The estimate is not based on table statistics.
+		return
+	}
+	actual := float64(res.RowsAffected())
+	if !isStaleRowCountEstimate(stats.RowCount, actual, ratio) {
+		return
+	}
+
+	now := timeutil.Now()
+	interval := stalePlanRefreshInterval.Get(&ex.server.cfg.Settings.SV)
+	var refreshed bool
+	for _, tab := range mem.Metadata().AllTables() {
+		if tab.Table.IsVirtualTable() {
+			continue
+		}
+		id := descpb.ID(tab.Table.ID())
+		if !ex.server.stalePlans.tryRefresh(id, now, interval) {
+			continue
+		}
+		ex.server.cfg.StatsRefresher.NotifyMutation(id, math.MaxInt32 /* rowsAffected */)
+		refreshed = true
+	}
+	if !refreshed {
+		return
+	}
+
+	log.VEventf(ctx, 1, "stale plan detected: estimated %.0f rows, got %.0f", stats.RowCount, actual)
+	telemetry.Inc(sqltelemetry.StalePlanDetectedCounter)
+
+	ex.server.cfg.QueryCache.Purge(stmt.SQL)
+	if stmt.Prepared != nil {
+		stmt.Prepared.stalePlan = true
+	}
+}
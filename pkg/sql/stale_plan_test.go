@@ -0,0 +1,93 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsStaleRowCountEstimate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testCases := []struct {
+		estimated, actual, ratio float64
+		expected                 bool
+	}{
+		// A zero ratio disables the check.
+		{estimated: 10, actual: 1e6, ratio: 0, expected: false},
+		// Small row counts are ignored.
+		{estimated: 1, actual: 500, ratio: 10, expected: false},
+		{estimated: 1000, actual: 5000, ratio: 10, expected: false},
+		{estimated: 1000, actual: 20000, ratio: 10, expected: true},
+		{estimated: 20000, actual: 1000, ratio: 10, expected: true},
+		{estimated: 5000, actual: 0, ratio: 10, expected: true},
+		{estimated: 5000, actual: 5000, ratio: 1, expected: false},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%g/%g/%g", tc.estimated, tc.actual, tc.ratio), func(t *testing.T) {
+			require.Equal(t, tc.expected, isStaleRowCountEstimate(tc.estimated, tc.actual, tc.ratio))
+		})
+	}
+}
+
+func TestStalePlanInvalidation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING sql.stats.automatic_collection.enabled = false`)
+	sqlDB.Exec(t, `SET CLUSTER SETTING sql.plan_regression_guard.row_count_ratio = 10`)
+	sqlDB.Exec(t, `CREATE TABLE t (k INT PRIMARY KEY)`)
+	sqlDB.Exec(t, `INSERT INTO t SELECT generate_series(1, 2000)`)
+	// The statistics claim that the table has a single row.
+	sqlDB.Exec(t, `ALTER TABLE t INJECT STATISTICS '[{
+		"columns": ["k"],
+		"created_at": "2021-01-01 00:00:00",
+		"row_count": 1,
+		"distinct_count": 1
+	}]'`)
+
+	detected := func() int32 {
+		return telemetry.Read(sqltelemetry.StalePlanDetectedCounter)
+	}
+	before := detected()
+	sqlDB.Exec(t, `SELECT * FROM t`)
+	require.Equal(t, before+1, detected())
+
+	// The statistics of t were just refreshed, so the plan is not invalidated
+	// again.
+	sqlDB.Exec(t, `SELECT * FROM t`)
+	require.Equal(t, before+1, detected())
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING sql.plan_regression_guard.min_refresh_interval = '0s'`)
+	sqlDB.Exec(t, `SELECT * FROM t`)
+	require.Equal(t, before+2, detected())
+
+	// Estimates which are close enough don't invalidate the plan.
+	sqlDB.Exec(t, `SELECT * FROM t WHERE k = 1`)
+	require.Equal(t, before+2, detected())
+}
@@ -167,6 +167,10 @@ func (tb *tableWriterBase) enableAutoCommit() {
 	tb.autoCommit = autoCommitEnabled
 }
 
+func (tb *tableWriterBase) disableAutoCommit() {
+	tb.autoCommit = autoCommitDisabled
+}
+
 func (tb *tableWriterBase) clearLastBatch(ctx context.Context) {
 	tb.lastBatchSize = 0
 	if tb.rows != nil {
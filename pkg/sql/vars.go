@@ -334,7 +334,7 @@ var varGen = map[string]sessionVar{
 		Set: func(_ context.Context, m *sessionDataMutator, s string) error {
 			pri, ok := tree.UserPriorityFromString(s)
 			if !ok {
-				return newVarValueError(`default_transaction_isolation`, s, "low", "normal", "high")
+				return newVarValueError(`default_transaction_priority`, s, "low", "normal", "high")
 			}
 			m.SetDefaultTransactionPriority(pri)
 			return nil
@@ -347,7 +347,7 @@ var varGen = map[string]sessionVar{
 			return strings.ToLower(pri.String())
 		},
 		GlobalDefault: func(sv *settings.Values) string {
-			return strings.ToLower(tree.Normal.String())
+			return defaultTxnPriorityClusterMode.String(sv)
 		},
 	},
 
@@ -761,8 +761,16 @@ var varGen = map[string]sessionVar{
 		},
 	},
 
-	// See https://www.postgresql.org/docs/10/static/runtime-config-client.html#GUC-LOC-TIMEOUT
-	`lock_timeout`: makeCompatIntVar(`lock_timeout`, 0),
+	// See https://www.postgresql.org/docs/10/static/runtime-config-client.html#GUC-LOCK-TIMEOUT
+	`lock_timeout`: {
+		GetStringVal: makeTimeoutVarGetter(`lock_timeout`),
+		Set:          lockTimeoutVarSet,
+		Get: func(evalCtx *extendedEvalContext) string {
+			ms := evalCtx.SessionData.LockTimeout.Nanoseconds() / int64(time.Millisecond)
+			return strconv.FormatInt(ms, 10)
+		},
+		GlobalDefault: func(sv *settings.Values) string { return "0" },
+	},
 
 	// Supported for PG compatibility only.
 	// See https://www.postgresql.org/docs/10/static/sql-syntax-lexical.html#SQL-SYNTAX-IDENTIFIERS
@@ -145,6 +145,8 @@ func (v *planVisitor) visitInternal(plan planNode, name string) {
 
 	case *zigzagJoinNode:
 
+	case *interleavedJoinNode:
+
 	case *applyJoinNode:
 		n.input.plan = v.visit(n.input.plan)
 
@@ -380,6 +382,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&indexJoinNode{}):                  "index join",
 	reflect.TypeOf(&insertNode{}):                     "insert",
 	reflect.TypeOf(&insertFastPathNode{}):             "insert fast path",
+	reflect.TypeOf(&interleavedJoinNode{}):            "interleaved join",
 	reflect.TypeOf(&invertedFilterNode{}):             "inverted filter",
 	reflect.TypeOf(&invertedJoinNode{}):               "inverted join",
 	reflect.TypeOf(&joinNode{}):                       "join",
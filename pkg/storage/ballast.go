@@ -0,0 +1,114 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storage
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/util/sysutil"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/errors/oserror"
+)
+
+// The emergency ballast is a file, created in the auxiliary directory of each
+// on-disk store, which reserves some disk space. If the disk fills up while
+// the node is running, the leaseholders on the store stop accepting writes
+// (see IsCapacityFull), and if it is full at startup, the node refuses to
+// start (see IsDiskFull), instead of crashing over and over with
+// unrecoverable storage errors. An operator can then delete the ballast to
+// reclaim enough space to recover, for example by decommissioning the node or
+// by dropping data.
+
+// maxBallastSize is the maximum size of the emergency ballast.
+const maxBallastSize = 1 << 30 // 1 GiB
+
+// BallastSizeBytes returns the size of the emergency ballast of a store whose
+// filesystem has the given total size: 1% of the filesystem, up to 1 GiB.
+func BallastSizeBytes(totalBytes int64) int64 {
+	size := totalBytes / 100
+	if size > maxBallastSize {
+		size = maxBallastSize
+	}
+	return size
+}
+
+// diskUsage returns the total and available sizes, in bytes, of the
+// filesystem containing the given directory.
+func diskUsage(dir string) (total, avail int64, _ error) {
+	fs, err := sysutil.StatFS(dir)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "failed to stat filesystem %s", dir)
+	}
+	return fs.TotalBlocks * fs.BlockSize, fs.AvailBlocks * fs.BlockSize, nil
+}
+
+// IsCapacityFull returns whether a store with the given total and available
+// sizes, in bytes, is full, that is whether less than half the size of its
+// emergency ballast is available.
+func IsCapacityFull(totalBytes, availBytes int64) bool {
+	return availBytes < BallastSizeBytes(totalBytes)/2
+}
+
+// IsDiskFull returns whether the filesystem of the store is full, as defined
+// by IsCapacityFull.
+// In-memory stores and stores which do not exist yet are never full.
+func IsDiskFull(spec base.StoreSpec) (bool, error) {
+	if spec.InMemory {
+		return false, nil
+	}
+	if _, err := os.Stat(spec.Path); oserror.IsNotExist(err) {
+		return false, nil
+	}
+	total, avail, err := diskUsage(spec.Path)
+	if err != nil {
+		return false, err
+	}
+	return IsCapacityFull(total, avail), nil
+}
+
+// MaybeEstablishBallast creates the emergency ballast of the store in the
+// given data directory, unless it already exists. Nothing is done if the data
+// directory does not exist yet. Returns whether the ballast was created.
+func MaybeEstablishBallast(dataDir string) (created bool, _ error) {
+	if _, err := os.Stat(dataDir); oserror.IsNotExist(err) {
+		return false, nil
+	}
+	total, avail, err := diskUsage(dataDir)
+	if err != nil {
+		return false, err
+	}
+	return maybeEstablishBallast(base.EmergencyBallastFile(dataDir), BallastSizeBytes(total), avail)
+}
+
+// maybeEstablishBallast creates the ballast file of the given size at the given
+// path, unless it already exists. The ballast is only created if that leaves at
+// least its size available, so that creating it does not make the disk full.
+func maybeEstablishBallast(path string, size, avail int64) (created bool, _ error) {
+	if _, err := os.Stat(path); err == nil {
+		return false, nil
+	} else if !oserror.IsNotExist(err) {
+		return false, err
+	}
+	if size <= 0 || avail < 2*size {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, err
+	}
+	if err := sysutil.CreateLargeFile(path, size); err != nil {
+		// Do not leave a partial ballast behind.
+		_ = os.Remove(path)
+		return false, errors.Wrap(err, "failed to create the emergency ballast")
+	}
+	return true, nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBallastSizeBytes(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	require.Equal(t, int64(0), BallastSizeBytes(0))
+	require.Equal(t, int64(10<<20), BallastSizeBytes(1000<<20))
+	require.Equal(t, int64(1<<30), BallastSizeBytes(1000<<30))
+}
+
+func TestIsCapacityFull(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// The ballast of a 1000 MiB store is 10 MiB, so it is full with less than
+	// 5 MiB available.
+	require.False(t, IsCapacityFull(1000<<20, 100<<20))
+	require.False(t, IsCapacityFull(1000<<20, 5<<20))
+	require.True(t, IsCapacityFull(1000<<20, 4<<20))
+	require.True(t, IsCapacityFull(1000<<20, 0))
+}
+
+func TestMaybeEstablishBallast(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	path := base.EmergencyBallastFile(dir)
+
+	// Not enough space: the ballast is not created.
+	created, err := maybeEstablishBallast(path, 1<<20, 1<<20)
+	require.NoError(t, err)
+	require.False(t, created)
+	_, err = os.Stat(path)
+	require.True(t, os.IsNotExist(err))
+
+	// Enough space: the ballast is created with the requested size.
+	created, err = maybeEstablishBallast(path, 1<<20, 10<<20)
+	require.NoError(t, err)
+	require.True(t, created)
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, int64(1<<20), fi.Size())
+
+	// The ballast already exists: nothing to do.
+	created, err = maybeEstablishBallast(path, 2<<20, 10<<20)
+	require.NoError(t, err)
+	require.False(t, created)
+	fi, err = os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, int64(1<<20), fi.Size())
+}
+
+func TestIsDiskFull(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	full, err := IsDiskFull(base.StoreSpec{InMemory: true})
+	require.NoError(t, err)
+	require.False(t, full)
+
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	full, err = IsDiskFull(base.StoreSpec{Path: filepath.Join(dir, "does-not-exist")})
+	require.NoError(t, err)
+	require.False(t, full)
+}
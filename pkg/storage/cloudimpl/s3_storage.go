@@ -25,9 +25,11 @@ import (
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/errors"
 )
@@ -43,6 +45,21 @@ type s3Storage struct {
 
 var _ cloud.ExternalStorage = &s3Storage{}
 
+// s3UploadPartSize is the size of the parts in which files are written to S3.
+var s3UploadPartSize = settings.RegisterByteSizeSetting(
+	"cloudstorage.s3.upload_part_size",
+	"the size of the parts in which files are uploaded to S3; files larger than "+
+		"this are written using multipart uploads",
+	8<<20, // 8MB
+	func(size int64) error {
+		if size < s3manager.MinUploadPartSize {
+			return errors.Newf("part size must be at least %s",
+				humanizeutil.IBytes(s3manager.MinUploadPartSize))
+		}
+		return nil
+	},
+)
+
 type serverSideEncMode string
 
 const (
@@ -217,7 +234,7 @@ func (s *s3Storage) WriteFile(ctx context.Context, basename string, content io.R
 	err = contextutil.RunWithTimeout(ctx, "put s3 object",
 		timeoutSetting.Get(&s.settings.SV),
 		func(ctx context.Context) error {
-			putObjectInput := s3.PutObjectInput{
+			uploadInput := s3manager.UploadInput{
 				Bucket: s.bucket,
 				Key:    aws.String(path.Join(s.prefix, basename)),
 				Body:   content,
@@ -229,16 +246,22 @@ func (s *s3Storage) WriteFile(ctx context.Context, basename string, content io.R
 			if s.conf.ServerEncMode != "" {
 				switch s.conf.ServerEncMode {
 				case string(aes256Enc):
-					putObjectInput.SetServerSideEncryption(s.conf.ServerEncMode)
+					uploadInput.ServerSideEncryption = aws.String(s.conf.ServerEncMode)
 				case string(kmsEnc):
-					putObjectInput.SetServerSideEncryption(s.conf.ServerEncMode)
-					putObjectInput.SetSSEKMSKeyId(s.conf.ServerKMSID)
+					uploadInput.ServerSideEncryption = aws.String(s.conf.ServerEncMode)
+					uploadInput.SSEKMSKeyId = aws.String(s.conf.ServerKMSID)
 				default:
 					return errors.Newf("unsupported server encryption mode %s. "+
 						"Supported values are `aws:kms` and `AES256`.", s.conf.ServerEncMode)
 				}
 			}
-			_, err := client.PutObjectWithContext(ctx, &putObjectInput)
+
+			// Files larger than the part size are uploaded in several parts, which
+			// are retried individually, instead of in a single PUT request.
+			uploader := s3manager.NewUploaderWithClient(client, func(u *s3manager.Uploader) {
+				u.PartSize = s3UploadPartSize.Get(&s.settings.SV)
+			})
+			_, err := uploader.UploadWithContext(ctx, &uploadInput)
 			return err
 		})
 	return errors.Wrap(err, "failed to put s3 object")
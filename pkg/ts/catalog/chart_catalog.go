@@ -475,6 +475,12 @@ var charts = []sectionDescription{
 					"ranges.overreplicated",
 				},
 			},
+			{
+				Title: "Circuit Breakers",
+				Metrics: []string{
+					"kv.replica_circuit_breaker.num_tripped_replicas",
+				},
+			},
 			{
 				Title: "Operations",
 				Metrics: []string{
@@ -951,6 +957,10 @@ var charts = []sectionDescription{
 				Title:   "Durations",
 				Metrics: []string{"txn.durations"},
 			},
+			{
+				Title:   "Pipelined Writes",
+				Metrics: []string{"txn.pipelined_writes"},
+			},
 			{
 				Title: "Restart Cause Mix",
 				Metrics: []string{
@@ -1804,6 +1814,14 @@ var charts = []sectionDescription{
 				Title:   "Max",
 				Metrics: []string{"sql.mem.root.max"},
 			},
+			{
+				Title: "Budget Exceeded",
+				Metrics: []string{
+					"sql.mem.budget_exceeded.count",
+					"sql.mem.budget_exceeded.count.internal",
+				},
+				AxisLabel: "SQL Statements",
+			},
 		},
 	},
 	{
@@ -1851,6 +1869,24 @@ var charts = []sectionDescription{
 					"sql.new_conns",
 				},
 			},
+			{
+				Title: "Rejected Connections",
+				Metrics: []string{
+					"sql.conns_rejected",
+				},
+			},
+			{
+				Title: "Locked Out Connections",
+				Metrics: []string{
+					"sql.conns_locked_out",
+				},
+			},
+			{
+				Title: "Dropped Cancel Requests",
+				Metrics: []string{
+					"sql.cancel_requests_dropped",
+				},
+			},
 			{
 				Title: "Open Transactions",
 				Metrics: []string{
@@ -1882,6 +1918,28 @@ var charts = []sectionDescription{
 				},
 				AxisLabel: "Latency",
 			},
+			{
+				Title: "Service Latency by Statement Type",
+				Metrics: []string{
+					"sql.select.service.latency",
+					"sql.update.service.latency",
+					"sql.insert.service.latency",
+					"sql.delete.service.latency",
+					"sql.ddl.service.latency",
+				},
+				AxisLabel: "Latency",
+			},
+			{
+				Title: "Service Latency by Statement Type (Internal)",
+				Metrics: []string{
+					"sql.select.service.latency.internal",
+					"sql.update.service.latency.internal",
+					"sql.insert.service.latency.internal",
+					"sql.delete.service.latency.internal",
+					"sql.ddl.service.latency.internal",
+				},
+				AxisLabel: "Latency",
+			},
 			{
 				Title: "Transaction Latency",
 				Metrics: []string{
@@ -1995,6 +2053,14 @@ var charts = []sectionDescription{
 					"sql.txn.rollback.started.count.internal",
 				},
 			},
+			{
+				Title: "Automatic Retries",
+				Metrics: []string{
+					"sql.txn.auto_retry.count",
+					"sql.txn.auto_retry.count.internal",
+				},
+				AxisLabel: "SQL Transactions",
+			},
 			{
 				Title: "Savepoints",
 				Metrics: []string{
@@ -0,0 +1,447 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package admission implements admission control for work executed on a node.
+// Work (e.g. a KV batch) is admitted into execution only when a slot is
+// available; when all slots are in use, work waits in a queue that grants
+// slots to latency-sensitive foreground work before bulk work, and to tenants
+// that are using fewer slots before tenants that are using more.
+package admission
+
+import (
+	"container/heap"
+	"context"
+	"math"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+)
+
+// KVAdmissionControlEnabled controls whether KV work is subject to admission
+// control.
+var KVAdmissionControlEnabled = settings.RegisterBoolSetting(
+	"admission.kv.enabled",
+	"when true, work performed by the KV layer is subject to admission control",
+	false,
+)
+
+// kvSlotsPerCPU is the number of KV work items admitted concurrently per CPU
+// when storage is not overloaded.
+var kvSlotsPerCPU = settings.RegisterIntSetting(
+	"admission.kv.slots_per_cpu",
+	"the number of KV requests per CPU that are admitted concurrently",
+	8,
+	settings.PositiveInt,
+)
+
+// L0SubLevelCountOverloadThreshold is the number of L0 sub-levels of a store
+// above which the store is considered overloaded, and the number of KV slots
+// is reduced.
+var L0SubLevelCountOverloadThreshold = settings.RegisterIntSetting(
+	"admission.l0_sub_level_count_overload_threshold",
+	"when the L0 sub-level count of a store exceeds this threshold, fewer KV "+
+		"requests are admitted concurrently",
+	20,
+	settings.PositiveInt,
+)
+
+// l0FileCountOverloadThreshold is the number of L0 files of a store above
+// which the store is considered overloaded, and the number of KV slots is
+// reduced.
+var l0FileCountOverloadThreshold = settings.RegisterIntSetting(
+	"admission.l0_file_count_overload_threshold",
+	"when the L0 file count of a store exceeds this threshold, fewer KV "+
+		"requests are admitted concurrently",
+	1000,
+	settings.PositiveInt,
+)
+
+// kvCPUUtilizationOverloadThreshold is the CPU utilization of a node above
+// which the node is considered overloaded, and the number of KV slots is
+// reduced.
+var kvCPUUtilizationOverloadThreshold = settings.RegisterFloatSetting(
+	"admission.kv.cpu_utilization_overload_threshold",
+	"when the CPU utilization of a node, normalized between 0 and 1 by its number of "+
+		"CPUs, exceeds this threshold, fewer KV requests are admitted concurrently",
+	0.8,
+	func(v float64) error {
+		if v <= 0 || v > 1 {
+			return errors.Errorf("must be in the range (0, 1]: %f", v)
+		}
+		return nil
+	},
+)
+
+// overloadSlotsDivisor is the factor by which the number of KV slots is
+// reduced when storage is overloaded to twice the overload thresholds or
+// more.
+const overloadSlotsDivisor = 4
+
+// ComputeKVSlots returns the number of KV work items that should be admitted
+// concurrently on a node with the given number of CPUs, given its CPU
+// utilization (normalized between 0 and 1) and the highest L0 sub-level count
+// and L0 file count among its stores. Once either count exceeds its overload
+// threshold, the number of slots is reduced gradually, in proportion to how
+// far the store is above the threshold, so that foreground work is throttled
+// before the LSM inverts. The number of slots is reduced in the same way once
+// the CPU utilization exceeds its threshold, down to the minimum when the CPUs
+// are saturated.
+func ComputeKVSlots(
+	sv *settings.Values, numCPU int, cpuUtilization float64, l0SubLevels, l0FileCount int64,
+) int {
+	slots := numCPU * int(kvSlotsPerCPU.Get(sv))
+	overload := math.Max(
+		float64(l0SubLevels)/float64(L0SubLevelCountOverloadThreshold.Get(sv)),
+		float64(l0FileCount)/float64(l0FileCountOverloadThreshold.Get(sv)),
+	)
+	if cpuThreshold := kvCPUUtilizationOverloadThreshold.Get(sv); cpuThreshold < 1 && cpuUtilization > cpuThreshold {
+		// Scale the CPU utilization so that the threshold maps to 1 and full
+		// utilization maps to 2, like twice the storage thresholds.
+		overload = math.Max(overload, 1+(cpuUtilization-cpuThreshold)/(1-cpuThreshold))
+	}
+	if overload > 1 {
+		// The fraction of the slots decreases linearly from one at the
+		// threshold down to 1/overloadSlotsDivisor at twice the threshold.
+		const minFraction = 1.0 / overloadSlotsDivisor
+		fraction := math.Max(minFraction, 1-(overload-1)*(1-minFraction))
+		slots = int(float64(slots) * fraction)
+	}
+	if slots < 1 {
+		slots = 1
+	}
+	return slots
+}
+
+// WorkPriority represents the priority of work. When work is queued, work
+// with a higher priority is admitted first.
+type WorkPriority int8
+
+const (
+	// LowPri is the priority of bulk work, such as backfills and imports.
+	LowPri WorkPriority = math.MinInt8
+	// NormalPri is the priority of foreground work.
+	NormalPri WorkPriority = 0
+	// HighPri is the priority of work that must not be delayed by other
+	// foreground work.
+	HighPri WorkPriority = math.MaxInt8
+)
+
+// WorkInfo describes the work that is submitted for admission.
+type WorkInfo struct {
+	// TenantID is the ID of the tenant on whose behalf the work is performed.
+	TenantID uint64
+	// Priority is the priority of the work.
+	Priority WorkPriority
+	// CreateTime is the time (in nanoseconds) at which the work was created.
+	// Among work with the same priority for tenants with the same usage, work
+	// that was created earlier is admitted first.
+	CreateTime int64
+}
+
+var (
+	metaRequested = metric.Metadata{
+		Name:        "admission.requested.kv",
+		Help:        "Number of KV requests that requested admission",
+		Measurement: "Requests",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaAdmitted = metric.Metadata{
+		Name:        "admission.admitted.kv",
+		Help:        "Number of KV requests that were admitted",
+		Measurement: "Requests",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaWaiting = metric.Metadata{
+		Name:        "admission.wait_queue_length.kv",
+		Help:        "Number of KV requests waiting for admission",
+		Measurement: "Requests",
+		Unit:        metric.Unit_COUNT,
+	}
+)
+
+// WorkQueueMetrics are the metrics of a WorkQueue.
+type WorkQueueMetrics struct {
+	Requested       *metric.Counter
+	Admitted        *metric.Counter
+	WaitQueueLength *metric.Gauge
+}
+
+// MetricStruct implements the metric.Struct interface.
+func (WorkQueueMetrics) MetricStruct() {}
+
+var _ metric.Struct = WorkQueueMetrics{}
+
+// waitingWork is work that is waiting in the queue for a slot.
+type waitingWork struct {
+	info WorkInfo
+	// ch is closed when the work is granted a slot.
+	ch chan struct{}
+	// granted is set, under the queue's mutex, when the work is granted a slot.
+	granted bool
+	// heapIndex is the index of the work in the waitingWorkHeap of its tenant.
+	heapIndex int
+}
+
+// waitingWorkHeap is a heap of the work of a tenant that is waiting for a
+// slot, in which work with a higher priority comes first, then work that was
+// created earlier.
+type waitingWorkHeap []*waitingWork
+
+var _ heap.Interface = (*waitingWorkHeap)(nil)
+
+func (h waitingWorkHeap) Len() int { return len(h) }
+
+func (h waitingWorkHeap) Less(i, j int) bool {
+	if h[i].info.Priority != h[j].info.Priority {
+		return h[i].info.Priority > h[j].info.Priority
+	}
+	return h[i].info.CreateTime < h[j].info.CreateTime
+}
+
+func (h waitingWorkHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].heapIndex = i
+	h[j].heapIndex = j
+}
+
+func (h *waitingWorkHeap) Push(x interface{}) {
+	w := x.(*waitingWork)
+	w.heapIndex = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *waitingWorkHeap) Pop() interface{} {
+	old := *h
+	w := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	w.heapIndex = -1
+	return w
+}
+
+// tenantInfo is the admission state of a tenant.
+type tenantInfo struct {
+	id uint64
+	// used is the number of slots used by the tenant.
+	used int
+	// waiting is the work of the tenant that is waiting for a slot.
+	waiting waitingWorkHeap
+	// heapIndex is the index of the tenant in the tenantHeap of the queue, or
+	// -1 if the tenant has no waiting work.
+	heapIndex int
+}
+
+// tenantHeap is a heap of the tenants that have waiting work, in which the
+// first tenant is the tenant of the work that must be admitted next: the
+// tenant whose first waiting work has the highest priority, then the tenant
+// that uses fewer slots, then the tenant whose first waiting work was created
+// earlier.
+type tenantHeap []*tenantInfo
+
+var _ heap.Interface = (*tenantHeap)(nil)
+
+func (h tenantHeap) Len() int { return len(h) }
+
+func (h tenantHeap) Less(i, j int) bool {
+	a, b := h[i].waiting[0], h[j].waiting[0]
+	if a.info.Priority != b.info.Priority {
+		return a.info.Priority > b.info.Priority
+	}
+	if h[i].used != h[j].used {
+		return h[i].used < h[j].used
+	}
+	return a.info.CreateTime < b.info.CreateTime
+}
+
+func (h tenantHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].heapIndex = i
+	h[j].heapIndex = j
+}
+
+func (h *tenantHeap) Push(x interface{}) {
+	t := x.(*tenantInfo)
+	t.heapIndex = len(*h)
+	*h = append(*h, t)
+}
+
+func (h *tenantHeap) Pop() interface{} {
+	old := *h
+	t := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	t.heapIndex = -1
+	return t
+}
+
+// WorkQueue maintains a queue of work waiting for admission. A fixed (but
+// adjustable) number of slots is available; work holds a slot from the time
+// it is admitted until AdmittedWorkDone is called.
+type WorkQueue struct {
+	settings *cluster.Settings
+	metrics  WorkQueueMetrics
+
+	mu struct {
+		syncutil.Mutex
+		totalSlots int
+		usedSlots  int
+		// tenants contains the tenants that use slots or have waiting work.
+		tenants map[uint64]*tenantInfo
+		// tenantHeap contains the tenants that have waiting work.
+		tenantHeap tenantHeap
+		numWaiting int
+	}
+}
+
+// NewWorkQueue creates a WorkQueue with the given number of slots.
+func NewWorkQueue(st *cluster.Settings, totalSlots int) *WorkQueue {
+	q := &WorkQueue{
+		settings: st,
+		metrics: WorkQueueMetrics{
+			Requested:       metric.NewCounter(metaRequested),
+			Admitted:        metric.NewCounter(metaAdmitted),
+			WaitQueueLength: metric.NewGauge(metaWaiting),
+		},
+	}
+	q.mu.totalSlots = totalSlots
+	q.mu.tenants = make(map[uint64]*tenantInfo)
+	return q
+}
+
+// Metrics returns the metrics of the queue.
+func (q *WorkQueue) Metrics() WorkQueueMetrics {
+	return q.metrics
+}
+
+// SetTotalSlots adjusts the number of slots of the queue. If the number of
+// slots is reduced below the number of slots in use, no work is admitted until
+// enough work completes.
+func (q *WorkQueue) SetTotalSlots(n int) {
+	if n < 1 {
+		n = 1
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.mu.totalSlots = n
+	q.grantWaitingLocked()
+}
+
+// Admit blocks until the work is admitted or the context is canceled. If
+// admission control is disabled, Admit returns immediately with admitted set
+// to false. If admitted is true, the caller must call AdmittedWorkDone once
+// the work completes.
+func (q *WorkQueue) Admit(ctx context.Context, info WorkInfo) (admitted bool, err error) {
+	if !KVAdmissionControlEnabled.Get(&q.settings.SV) {
+		return false, nil
+	}
+	q.metrics.Requested.Inc(1)
+
+	q.mu.Lock()
+	t := q.getTenantLocked(info.TenantID)
+	if q.mu.numWaiting == 0 && q.mu.usedSlots < q.mu.totalSlots {
+		q.useSlotLocked(t)
+		q.mu.Unlock()
+		q.metrics.Admitted.Inc(1)
+		return true, nil
+	}
+	w := &waitingWork{info: info, ch: make(chan struct{})}
+	heap.Push(&t.waiting, w)
+	q.fixTenantLocked(t)
+	q.mu.numWaiting++
+	q.metrics.WaitQueueLength.Inc(1)
+	q.mu.Unlock()
+
+	select {
+	case <-w.ch:
+		q.metrics.Admitted.Inc(1)
+		return true, nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		if w.granted {
+			// The work was granted a slot concurrently with the cancellation;
+			// return the slot.
+			q.mu.Unlock()
+			q.AdmittedWorkDone(info.TenantID)
+			return false, ctx.Err()
+		}
+		heap.Remove(&t.waiting, w.heapIndex)
+		q.fixTenantLocked(t)
+		q.mu.numWaiting--
+		q.maybeDeleteTenantLocked(t)
+		q.mu.Unlock()
+		q.metrics.WaitQueueLength.Dec(1)
+		return false, ctx.Err()
+	}
+}
+
+// AdmittedWorkDone releases the slot held by admitted work of the given
+// tenant.
+func (q *WorkQueue) AdmittedWorkDone(tenantID uint64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	t := q.mu.tenants[tenantID]
+	q.mu.usedSlots--
+	t.used--
+	q.fixTenantLocked(t)
+	q.maybeDeleteTenantLocked(t)
+	q.grantWaitingLocked()
+}
+
+func (q *WorkQueue) getTenantLocked(tenantID uint64) *tenantInfo {
+	t, ok := q.mu.tenants[tenantID]
+	if !ok {
+		t = &tenantInfo{id: tenantID, heapIndex: -1}
+		q.mu.tenants[tenantID] = t
+	}
+	return t
+}
+
+// fixTenantLocked restores the order of the tenant heap after the usage or the
+// waiting work of the tenant changed.
+func (q *WorkQueue) fixTenantLocked(t *tenantInfo) {
+	switch {
+	case len(t.waiting) == 0 && t.heapIndex >= 0:
+		heap.Remove(&q.mu.tenantHeap, t.heapIndex)
+	case len(t.waiting) > 0 && t.heapIndex < 0:
+		heap.Push(&q.mu.tenantHeap, t)
+	case len(t.waiting) > 0:
+		heap.Fix(&q.mu.tenantHeap, t.heapIndex)
+	}
+}
+
+func (q *WorkQueue) maybeDeleteTenantLocked(t *tenantInfo) {
+	if t.used <= 0 && len(t.waiting) == 0 {
+		delete(q.mu.tenants, t.id)
+	}
+}
+
+func (q *WorkQueue) useSlotLocked(t *tenantInfo) {
+	q.mu.usedSlots++
+	t.used++
+	q.fixTenantLocked(t)
+}
+
+// grantWaitingLocked grants the free slots to the waiting work, in order. Each
+// grant takes logarithmic time in the number of tenants and in the number of
+// waiting work items of the tenant.
+func (q *WorkQueue) grantWaitingLocked() {
+	for q.mu.usedSlots < q.mu.totalSlots && len(q.mu.tenantHeap) > 0 {
+		t := q.mu.tenantHeap[0]
+		w := heap.Pop(&t.waiting).(*waitingWork)
+		q.mu.numWaiting--
+		q.useSlotLocked(t)
+		w.granted = true
+		close(w.ch)
+		q.metrics.WaitQueueLength.Dec(1)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package admission
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func waitingLen(q *WorkQueue) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.mu.numWaiting
+}
+
+func TestWorkQueueDisabled(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	st := cluster.MakeTestingClusterSettings()
+	q := NewWorkQueue(st, 1)
+	for i := 0; i < 3; i++ {
+		admitted, err := q.Admit(context.Background(), WorkInfo{})
+		require.NoError(t, err)
+		require.False(t, admitted)
+	}
+}
+
+func TestWorkQueueOrdering(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	KVAdmissionControlEnabled.Override(&st.SV, true)
+	q := NewWorkQueue(st, 2)
+
+	// Tenant 1 holds both slots.
+	for i := 0; i < 2; i++ {
+		admitted, err := q.Admit(ctx, WorkInfo{TenantID: 1})
+		require.NoError(t, err)
+		require.True(t, admitted)
+	}
+
+	work := []WorkInfo{
+		{TenantID: 1, Priority: LowPri, CreateTime: 1},
+		{TenantID: 1, Priority: NormalPri, CreateTime: 3},
+		{TenantID: 2, Priority: NormalPri, CreateTime: 4},
+		{TenantID: 1, Priority: NormalPri, CreateTime: 2},
+		{TenantID: 2, Priority: HighPri, CreateTime: 5},
+	}
+	order := make(chan int, len(work))
+	for i := range work {
+		go func(i int) {
+			admitted, err := q.Admit(ctx, work[i])
+			if err != nil || !admitted {
+				panic("work unexpectedly not admitted")
+			}
+			order <- i
+		}(i)
+		// Wait for the work to be queued so that the goroutines don't race.
+		require.Eventually(t, func() bool { return waitingLen(q) == i+1 }, 10*time.Second, time.Millisecond)
+	}
+
+	// Release one slot at a time while tenant 1 keeps holding the other. High
+	// priority work goes first, then normal priority work of tenant 2, which
+	// uses fewer slots than tenant 1, then the normal priority work of tenant 1
+	// from oldest to newest, and the low priority work last.
+	var got []int
+	tenant := uint64(1)
+	for range work {
+		q.AdmittedWorkDone(tenant)
+		i := <-order
+		got = append(got, i)
+		tenant = work[i].TenantID
+	}
+	q.AdmittedWorkDone(tenant)
+	q.AdmittedWorkDone(1)
+	require.Equal(t, []int{4, 2, 3, 1, 0}, got)
+	require.Equal(t, int64(len(work)+2), q.Metrics().Admitted.Count())
+}
+
+func TestWorkQueueCancel(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	st := cluster.MakeTestingClusterSettings()
+	KVAdmissionControlEnabled.Override(&st.SV, true)
+	q := NewWorkQueue(st, 1)
+
+	admitted, err := q.Admit(context.Background(), WorkInfo{})
+	require.NoError(t, err)
+	require.True(t, admitted)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error)
+	go func() {
+		_, err := q.Admit(ctx, WorkInfo{})
+		errCh <- err
+	}()
+	require.Eventually(t, func() bool { return waitingLen(q) == 1 }, 10*time.Second, time.Millisecond)
+	cancel()
+	require.Equal(t, context.Canceled, <-errCh)
+	require.Equal(t, 0, waitingLen(q))
+	require.Equal(t, int64(0), q.Metrics().WaitQueueLength.Value())
+
+	// Raising the number of slots admits new work.
+	q.SetTotalSlots(2)
+	admitted, err = q.Admit(context.Background(), WorkInfo{})
+	require.NoError(t, err)
+	require.True(t, admitted)
+}
+
+func TestComputeKVSlots(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	st := cluster.MakeTestingClusterSettings()
+	require.Equal(t, 32, ComputeKVSlots(&st.SV, 4, 0, 0, 0))
+	require.Equal(t, 32, ComputeKVSlots(&st.SV, 4, 0.8, 20, 1000))
+	// The slots are reduced gradually above the thresholds.
+	require.Equal(t, 20, ComputeKVSlots(&st.SV, 4, 0, 30, 0))
+	require.Equal(t, 20, ComputeKVSlots(&st.SV, 4, 0, 0, 1500))
+	require.Equal(t, 20, ComputeKVSlots(&st.SV, 4, 0, 30, 1200))
+	require.Equal(t, 20, ComputeKVSlots(&st.SV, 4, 0.9, 0, 0))
+	require.Equal(t, 20, ComputeKVSlots(&st.SV, 4, 0.9, 30, 0))
+	require.Equal(t, 8, ComputeKVSlots(&st.SV, 4, 0, 40, 0))
+	require.Equal(t, 8, ComputeKVSlots(&st.SV, 4, 0, 100, 5000))
+	require.Equal(t, 8, ComputeKVSlots(&st.SV, 4, 1, 0, 0))
+	// The CPU utilization is ignored when its threshold is 1.
+	kvCPUUtilizationOverloadThreshold.Override(&st.SV, 1)
+	require.Equal(t, 32, ComputeKVSlots(&st.SV, 4, 1, 0, 0))
+	kvSlotsPerCPU.Override(&st.SV, 1)
+	require.Equal(t, 1, ComputeKVSlots(&st.SV, 1, 0, 100, 0))
+}
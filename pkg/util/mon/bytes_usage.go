@@ -567,7 +567,24 @@ func (b *BoundAccount) ResizeTo(ctx context.Context, newSz int64) error {
 }
 
 // Grow is an accessor for b.mon.GrowAccount.
+//
+// If the allocation is denied by an ancestor of the account's monitor, the
+// error names both the account's monitor (usually named after the operator
+// that requested the memory) and the monitor that denied it.
 func (b *BoundAccount) Grow(ctx context.Context, x int64) error {
+	if err := b.grow(ctx, x); err != nil {
+		if errors.Is(err, errBudgetDeniedByAncestor) {
+			return errors.Wrapf(err, "%s", b.mon.name)
+		}
+		return err
+	}
+	return nil
+}
+
+// grow is like Grow, but doesn't name the account's monitor in the error. It is
+// used by monitors to request more bytes from their pool, so that the name of
+// each intermediate monitor isn't added to the error.
+func (b *BoundAccount) grow(ctx context.Context, x int64) error {
 	if b.reserved < x {
 		minExtra := b.mon.roundSize(x)
 		if err := b.mon.reserveBytes(ctx, minExtra); err != nil {
@@ -596,6 +613,11 @@ func (b *BoundAccount) Shrink(ctx context.Context, delta int64) {
 	}
 }
 
+// errBudgetDeniedByAncestor marks the errors returned by reserveBytes when the
+// allocation was denied by an ancestor of the monitor rather than by the
+// monitor itself.
+var errBudgetDeniedByAncestor = errors.New("budget denied by ancestor monitor")
+
 // reserveBytes declares an allocation to this monitor. An error is returned if
 // the allocation is denied.
 // x must be a multiple of `poolAllocationSize`.
@@ -616,6 +638,9 @@ func (mm *BytesMonitor) reserveBytes(ctx context.Context, x int64) error {
 	// Check whether we need to request an increase of our budget.
 	if mm.mu.curAllocated > mm.mu.curBudget.used+mm.reserved.used-x {
 		if err := mm.increaseBudget(ctx, x); err != nil {
+			if mm.mu.curBudget.mon != nil {
+				err = errors.Mark(err, errBudgetDeniedByAncestor)
+			}
 			return err
 		}
 	}
@@ -690,7 +715,7 @@ func (mm *BytesMonitor) increaseBudget(ctx context.Context, minExtra int64) erro
 		log.Infof(ctx, "%s: requesting %d bytes from the pool", mm.name, minExtra)
 	}
 
-	return mm.mu.curBudget.Grow(ctx, minExtra)
+	return mm.mu.curBudget.grow(ctx, minExtra)
 }
 
 // roundSize rounds its argument to the smallest greater or equal
@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"strings"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
@@ -360,3 +361,46 @@ func BenchmarkBoundAccountGrow(b *testing.B) {
 		_ = a.Grow(ctx, 1)
 	}
 }
+
+func TestBudgetExceededErrorNamesMonitors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	session := NewMonitorWithLimit("session", MemoryResource, 100, /* limit */
+		nil /* curCount */, nil /* maxHist */, 1 /* increment */, math.MaxInt64 /* noteworthy */, st)
+	session.Start(ctx, nil, MakeStandaloneBudget(math.MaxInt64))
+	flow := NewMonitor("flow", MemoryResource,
+		nil /* curCount */, nil /* maxHist */, 1 /* increment */, math.MaxInt64 /* noteworthy */, st)
+	flow.Start(ctx, session, BoundAccount{})
+	sorter := NewMonitorWithLimit("sorter-mem", MemoryResource, 80, /* limit */
+		nil /* curCount */, nil /* maxHist */, 1 /* increment */, math.MaxInt64 /* noteworthy */, st)
+	sorter.Start(ctx, flow, BoundAccount{})
+	other := flow.MakeBoundAccount()
+	a := sorter.MakeBoundAccount()
+
+	if err := other.Grow(ctx, 40); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Grow(ctx, 50); err != nil {
+		t.Fatal(err)
+	}
+	// The session denies the request: the error names the monitor of the
+	// account and the session, but not the monitors in between.
+	err := a.Grow(ctx, 20)
+	if err == nil || !strings.HasPrefix(err.Error(), "sorter-mem: session: memory budget exceeded") ||
+		strings.Contains(err.Error(), "flow") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The monitor of the account denies the request: it is only named once.
+	err = a.Grow(ctx, 40)
+	if err == nil || !strings.HasPrefix(err.Error(), "sorter-mem: memory budget exceeded") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	a.Close(ctx)
+	other.Close(ctx)
+	sorter.Stop(ctx)
+	flow.Stop(ctx)
+	session.Stop(ctx)
+}